@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// FileInput is a log file read like the filelog watcher.
+	FileInput = "file"
+	// JournalInput is a journal exported by `journalctl -o export`.
+	JournalInput = "journal"
+	// DmesgInput is a kernel log dumped by dmesg, or read from /dev/kmsg.
+	DmesgInput = "dmesg"
+
+	// TextOutput prints the statuses for humans.
+	TextOutput = "text"
+	// JSONOutput prints the statuses as a JSON array.
+	JSONOutput = "json"
+)
+
+func NewLogReplayOptions() *LogReplayOptions {
+	return &LogReplayOptions{}
+}
+
+// LogReplayOptions contains log replay command line options.
+type LogReplayOptions struct {
+	// command line options. See flag descriptions for the description
+	ConfigPath   string
+	InputPath    string
+	InputFormat  string
+	OutputFormat string
+	BootTime     string
+}
+
+// AddFlags adds log replay command line options to pflag.
+func (lro *LogReplayOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&lro.ConfigPath, "config", "", "The system log monitor configuration file to replay the logs through.")
+	fs.StringVar(&lro.InputPath, "input", "-",
+		"The captured logs to replay, - for the standard input. Files ending with .gz are decompressed.")
+	fs.StringVar(&lro.InputFormat, "input-format", "",
+		"The format of the captured logs: file, journal or dmesg. Defaults to file for the filelog plugin, journal for the journald plugin and dmesg for the kmsg plugin of the configuration.")
+	fs.StringVar(&lro.OutputFormat, "output-format", TextOutput, "The format of the replayed statuses: text or json.")
+	fs.StringVar(&lro.BootTime, "boot-time", "1970-01-01T00:00:00Z",
+		"The RFC3339 boot time of the node the dmesg input was captured on, which its relative timestamps are added to.")
+}
+
+// IsValid validates log replay command line options.
+// Returns error if invalid, nil otherwise.
+func (lro *LogReplayOptions) IsValid() error {
+	if lro.ConfigPath == "" {
+		return fmt.Errorf("--config is required")
+	}
+	switch lro.InputFormat {
+	case "", FileInput, JournalInput, DmesgInput:
+	default:
+		return fmt.Errorf("unknown input format %q", lro.InputFormat)
+	}
+	switch lro.OutputFormat {
+	case TextOutput, JSONOutput:
+	default:
+		return fmt.Errorf("unknown output format %q", lro.OutputFormat)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, lro.BootTime); err != nil {
+		return fmt.Errorf("invalid boot time %q: %v", lro.BootTime, err)
+	}
+	return nil
+}
+
+func init() {
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+}
@@ -18,6 +18,9 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
 
 	"k8s.io/klog/v2"
 
@@ -29,10 +32,16 @@ import (
 	"k8s.io/node-problem-detector/pkg/exporters/prometheusexporter"
 	"k8s.io/node-problem-detector/pkg/problemdaemon"
 	"k8s.io/node-problem-detector/pkg/problemdetector"
+	"k8s.io/node-problem-detector/pkg/problemmetrics"
+	"k8s.io/node-problem-detector/pkg/statusapi"
 	"k8s.io/node-problem-detector/pkg/types"
 	"k8s.io/node-problem-detector/pkg/version"
 )
 
+// errInvalidConfig is returned by npdMain in validate-only mode when any
+// configuration file is invalid.
+var errInvalidConfig = errors.New("invalid configuration")
+
 func npdMain(ctx context.Context, npdo *options.NodeProblemDetectorOptions) error {
 	if npdo.PrintVersion {
 		version.PrintVersion()
@@ -43,8 +52,20 @@ func npdMain(ctx context.Context, npdo *options.NodeProblemDetectorOptions) erro
 	npdo.SetConfigFromDeprecatedOptionsOrDie()
 	npdo.ValidOrDie()
 
+	if npdo.ValidateOnly {
+		return validateConfigs(npdo)
+	}
+
+	// The labels of the problem metrics can't change once they are
+	// registered, so the ones of all configurations are declared up front.
+	if labels := problemdaemon.ProblemMetricLabels(npdo.MonitorConfigPaths); len(labels) != 0 {
+		if err := problemmetrics.GlobalProblemMetricsManager.DeclareProblemLabels(labels); err != nil {
+			klog.Fatalf("Failed to declare problem metric labels %q: %v", labels, err)
+		}
+	}
+
 	// Initialize problem daemons.
-	problemDaemons := problemdaemon.NewProblemDaemons(npdo.MonitorConfigPaths)
+	problemDaemons := problemdaemon.NewProblemDaemonMap(npdo.MonitorConfigPaths)
 	if len(problemDaemons) == 0 {
 		klog.Fatalf("No problem daemon is configured")
 	}
@@ -60,6 +81,12 @@ func npdMain(ctx context.Context, npdo *options.NodeProblemDetectorOptions) erro
 		klog.Info("Prometheus exporter started.")
 	}
 
+	statusAPI := statusapi.NewServerOrDie(npdo)
+	if statusAPI != nil {
+		defaultExporters = append(defaultExporters, statusAPI)
+		klog.Info("Status API started.")
+	}
+
 	plugableExporters := exporters.NewExporters()
 
 	npdExporters := []types.Exporter{}
@@ -72,5 +99,28 @@ func npdMain(ctx context.Context, npdo *options.NodeProblemDetectorOptions) erro
 
 	// Initialize NPD core.
 	p := problemdetector.NewProblemDetector(problemDaemons, npdExporters)
+	if statusAPI != nil {
+		statusAPI.SetMonitorHealthFunc(p.GetMonitorHealth)
+	}
+
+	// Recreate the problem daemons whose config files change.
+	reloader := problemdaemon.NewConfigReloader(npdo.MonitorConfigPaths, problemDaemons, p.ReplaceMonitor)
+	go reloader.Run(ctx, npdo.ConfigReloadPeriod, reloadSignals())
+
 	return p.Run(ctx)
 }
+
+// validateConfigs validates the configuration files of the problem daemons and
+// exporters without starting them, and prints every error found.
+func validateConfigs(npdo *options.NodeProblemDetectorOptions) error {
+	errs := problemdaemon.ValidateConfigs(npdo.MonitorConfigPaths)
+	errs = append(errs, exporters.ValidateConfigs()...)
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%w: %d error(s) found", errInvalidConfig, len(errs))
+	}
+	fmt.Println("Configuration is valid.")
+	return nil
+}
@@ -20,6 +20,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -86,6 +87,30 @@ func TestNPDMain(t *testing.T) {
 	}
 }
 
+func TestNPDMainValidateOnly(t *testing.T) {
+	npdo, cleanup := setupNPD(t)
+	defer cleanup()
+	npdo.ValidateOnly = true
+
+	if err := npdMain(context.Background(), npdo); err != nil {
+		t.Errorf("expected valid configuration, got %v", err)
+	}
+
+	invalidConfigFileName, err := writeTempFile(t, "json", `{"plugin": "filelog", "rules": [{"type": "temporary", "reason": "R", "pattern": "("}]}`)
+	if err != nil {
+		t.Fatalf("cannot create temp config file, %v", err)
+	}
+	defer func() {
+		if err := os.Remove(invalidConfigFileName); err != nil {
+			t.Logf("Failed to remove temporary file %s: %v", invalidConfigFileName, err)
+		}
+	}()
+	*npdo.MonitorConfigPaths["system-log-monitor"] = append(*npdo.MonitorConfigPaths["system-log-monitor"], invalidConfigFileName)
+	if err := npdMain(context.Background(), npdo); !errors.Is(err, errInvalidConfig) {
+		t.Errorf("expected invalid configuration error, got %v", err)
+	}
+}
+
 func writeTempFile(t *testing.T, ext string, contents string) (string, error) {
 	f, err := os.CreateTemp("", "*."+ext)
 	if err != nil {
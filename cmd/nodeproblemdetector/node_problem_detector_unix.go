@@ -20,6 +20,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"os"
 	"os/signal"
@@ -60,6 +61,17 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 	if err := npdMain(ctx, npdo); err != nil {
+		if errors.Is(err, errInvalidConfig) {
+			klog.Exit(err)
+		}
 		klog.Fatalf("Problem detector failed with error: %v", err)
 	}
 }
+
+// reloadSignals returns a channel that receives SIGHUP, which triggers a reload
+// of the problem daemon config files.
+func reloadSignals() <-chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	return ch
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -187,3 +188,9 @@ func (s *npdService) Execute(args []string, r <-chan svc.ChangeRequest, changes
 
 	return false, uint32(0)
 }
+
+// reloadSignals returns nil, Windows has no signal to trigger a reload of the
+// problem daemon config files.
+func reloadSignals() <-chan os.Signal {
+	return nil
+}
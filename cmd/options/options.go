@@ -36,6 +36,9 @@ type NodeProblemDetectorOptions struct {
 
 	// PrintVersion is the flag determining whether version information is printed.
 	PrintVersion bool
+	// ValidateOnly is the flag determining whether the configuration files are
+	// only validated, without starting the problem daemons and exporters.
+	ValidateOnly bool
 	// HostnameOverride specifies custom node name used to override hostname.
 	HostnameOverride string
 	// ServerPort is the port to bind the node problem detector server. Use 0 to disable.
@@ -75,6 +78,14 @@ type NodeProblemDetectorOptions struct {
 	// PrometheusServerAddress is the address to bind the Prometheus scrape endpoint.
 	PrometheusServerAddress string
 
+	// status API options
+	// StatusAPIPort is the port to bind the local status API. Use 0 to disable.
+	StatusAPIPort int
+	// StatusAPIAddress is the address to bind the local status API.
+	StatusAPIAddress string
+	// StatusAPIMaxEvents is the number of recent events the local status API keeps.
+	StatusAPIMaxEvents int
+
 	// problem daemon options
 
 	// SystemLogMonitorConfigPaths specifies the list of paths to system log monitor configuration
@@ -89,6 +100,9 @@ type NodeProblemDetectorOptions struct {
 	CustomPluginMonitorConfigPaths []string
 	// MonitorConfigPaths specifies the list of paths to configuration files for each monitor.
 	MonitorConfigPaths types.ProblemDaemonConfigPathMap
+	// ConfigReloadPeriod is the period at which the problem daemon configuration files are checked
+	// for changes. Use 0 to disable.
+	ConfigReloadPeriod time.Duration
 
 	// application options
 
@@ -127,6 +141,7 @@ func (npdo *NodeProblemDetectorOptions) AddFlags(fs *pflag.FlagSet) error {
 	fs.BoolVar(&npdo.K8sExporterWriteEvents, "k8s-exporter-write-events", true, "Whether to write Kubernetes Event objects with event details.")
 	fs.BoolVar(&npdo.K8sExporterUpdateNodeConditions, "k8s-exporter-update-node-conditions", true, "Whether to update Kubernetes Node conditions with event details.")
 	fs.BoolVar(&npdo.PrintVersion, "version", false, "Print version information and quit")
+	fs.BoolVar(&npdo.ValidateOnly, "validate-only", false, "Validate the problem daemon and exporter configuration files, print every error found and quit. The exit code is non-zero if any configuration is invalid.")
 	fs.StringVar(&npdo.HostnameOverride, "hostname-override",
 		"", "Custom node name used to override hostname")
 	fs.IntVar(&npdo.ServerPort, "port",
@@ -138,12 +153,20 @@ func (npdo *NodeProblemDetectorOptions) AddFlags(fs *pflag.FlagSet) error {
 		20257, "The port to bind the Prometheus scrape endpoint. Prometheus exporter is enabled by default at port 20257. Use 0 to disable.")
 	fs.StringVar(&npdo.PrometheusServerAddress, "prometheus-address",
 		"127.0.0.1", "The address to bind the Prometheus scrape endpoint.")
+	fs.IntVar(&npdo.StatusAPIPort, "status-api-port",
+		0, "The port to bind the local status API, which serves the conditions, recent events and monitor health of node problem detector. Use 0 to disable.")
+	fs.StringVar(&npdo.StatusAPIAddress, "status-api-address",
+		"127.0.0.1", "The address to bind the local status API.")
+	fs.IntVar(&npdo.StatusAPIMaxEvents, "status-api-max-events",
+		100, "The number of recent events the local status API keeps.")
 	fs.Float32Var(&npdo.QPS, "kube-api-qps", 500, "Maximum QPS to use while talking with Kubernetes API")
 	fs.IntVar(&npdo.Burst, "kube-api-burst", 500, "Maximum burst for throttle while talking with Kubernetes API")
 	for _, exporterName := range exporters.GetExporterNames() {
 		exporterHandler := exporters.GetExporterHandlerOrDie(exporterName)
 		exporterHandler.Options.SetFlags(fs)
 	}
+	fs.DurationVar(&npdo.ConfigReloadPeriod, "config-reload-period", 0,
+		"The period at which problem daemon config files are checked for changes, and the problem daemons with changed config files are recreated. Use 0 to only reload on SIGHUP.")
 	for _, problemDaemonName := range problemdaemon.GetProblemDaemonNames() {
 		fs.StringSliceVar(
 			npdo.MonitorConfigPaths[problemDaemonName],
@@ -18,6 +18,7 @@ package custompluginmonitor
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 
 	"k8s.io/node-problem-detector/pkg/custompluginmonitor/plugin"
 	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
 	"k8s.io/node-problem-detector/pkg/problemdaemon"
 	"k8s.io/node-problem-detector/pkg/problemmetrics"
 	"k8s.io/node-problem-detector/pkg/types"
@@ -34,74 +36,139 @@ import (
 
 const CustomPluginMonitorName = "custom-plugin-monitor"
 
+// summaryCheckPeriod is the period at which custom plugin monitor reports the
+// summaries of the events suppressed in the cooldown windows that elapsed.
+const summaryCheckPeriod = 10 * time.Second
+
 func init() {
 	problemdaemon.Register(
 		CustomPluginMonitorName,
 		types.ProblemDaemonHandler{
 			CreateProblemDaemonOrDie: NewCustomPluginMonitorOrDie,
+			CreateProblemDaemon:      NewCustomPluginMonitor,
+			ValidateConfig:           ValidateConfig,
 			CmdOptionDescription:     "Set to config file paths.",
 		})
 }
 
+// streakKey identifies the results a rule reports for a condition.
+type streakKey struct {
+	rule      *cpmtypes.CustomRule
+	condition string
+}
+
+// streak counts the consecutive NonOK and OK results a rule reported for a
+// condition.
+type streak struct {
+	failures  int
+	successes int
+}
+
 type customPluginMonitor struct {
 	configPath string
 	config     cpmtypes.CustomPluginConfig
 	conditions []types.Condition
-	plugin     *plugin.Plugin
+	// previousConditions are the conditions carried over from the custom
+	// plugin monitor this one replaces.
+	previousConditions []types.Condition
+	plugin             *plugin.Plugin
+	// limiters are the event limiters of the temporary rules with cooldown set.
+	limiters map[*cpmtypes.CustomRule]*util.EventLimiter
+	// streaks are the consecutive results of the rules with thresholds for
+	// their conditions.
+	streaks    map[streakKey]*streak
 	statusChan chan *types.Status
 	tomb       *tomb.Tomb
 }
 
 // NewCustomPluginMonitorOrDie create a new customPluginMonitor, panic if error occurs.
 func NewCustomPluginMonitorOrDie(configPath string) types.Monitor {
+	c, err := NewCustomPluginMonitor(configPath)
+	if err != nil {
+		klog.Fatal(err)
+	}
+	return c
+}
+
+// NewCustomPluginMonitor create a new customPluginMonitor, returns error if the
+// configuration is invalid.
+func NewCustomPluginMonitor(configPath string) (types.Monitor, error) {
 	c := &customPluginMonitor{
 		configPath: configPath,
+		streaks:    make(map[streakKey]*streak),
 		tomb:       tomb.NewTomb(),
 	}
 	f, err := os.ReadFile(configPath)
 	if err != nil {
-		klog.Fatalf("Failed to read configuration file %q: %v", configPath, err)
+		return nil, fmt.Errorf("failed to read configuration file %q: %v", configPath, err)
 	}
 	err = json.Unmarshal(f, &c.config)
 	if err != nil {
-		klog.Fatalf("Failed to unmarshal configuration file %q: %v", configPath, err)
+		return nil, fmt.Errorf("failed to unmarshal configuration file %q: %v", configPath, err)
 	}
 	// Apply configurations
 	err = (&c.config).ApplyConfiguration()
 	if err != nil {
-		klog.Fatalf("Failed to apply configuration for %q: %v", configPath, err)
+		return nil, fmt.Errorf("failed to apply configuration for %q: %v", configPath, err)
 	}
 
 	// Validate configurations
 	err = c.config.Validate()
 	if err != nil {
-		klog.Fatalf("Failed to validate custom plugin config %+v: %v", c.config, err)
+		return nil, fmt.Errorf("failed to validate custom plugin config %+v: %v", c.config, err)
 	}
 
 	klog.Infof("Finish parsing custom plugin monitor config file %s: %+v", c.configPath, c.config)
 
 	c.plugin = plugin.NewPlugin(c.config)
+	c.limiters = newEventLimiters(c.config.Rules)
 	// A 1000 size channel should be big enough.
 	c.statusChan = make(chan *types.Status, 1000)
 
 	if *c.config.EnableMetricsReporting {
 		initializeProblemMetricsOrDie(c.config.Rules)
 	}
-	return c
+	return c, nil
+}
+
+// ValidateConfig validates the custom plugin monitor configuration file without
+// starting the plugins, and reports every invalid value as a util.ConfigError.
+func ValidateConfig(configPath string) []error {
+	f, err := os.ReadFile(configPath)
+	if err != nil {
+		return []error{err}
+	}
+	var config cpmtypes.CustomPluginConfig
+	if err := util.UnmarshalConfig(f, &config); err != nil {
+		return []error{err}
+	}
+	return config.ValidateAll()
+}
+
+// newEventLimiters creates the event limiters of the rules with cooldown set.
+func newEventLimiters(rules []*cpmtypes.CustomRule) map[*cpmtypes.CustomRule]*util.EventLimiter {
+	limiters := make(map[*cpmtypes.CustomRule]*util.EventLimiter)
+	for _, rule := range rules {
+		if rule.Cooldown != nil {
+			limiters[rule] = util.NewEventLimiter(*rule.Cooldown, rule.EventsPerWindow())
+		}
+	}
+	return limiters
 }
 
 // initializeProblemMetricsOrDie creates problem metrics for all problems and set the value to 0,
 // panic if error occurs.
 func initializeProblemMetricsOrDie(rules []*cpmtypes.CustomRule) {
 	for _, rule := range rules {
+		severity := rule.ProblemSeverity()
 		if rule.Type == types.Perm {
-			err := problemmetrics.GlobalProblemMetricsManager.SetProblemGauge(rule.Condition, rule.Reason, false)
+			err := problemmetrics.GlobalProblemMetricsManager.SetProblemGaugeWithSeverity(rule.Condition, rule.Reason, string(severity), false)
 			if err != nil {
 				klog.Fatalf("Failed to initialize problem gauge metrics for problem %q, reason %q: %v",
 					rule.Condition, rule.Reason, err)
 			}
 		}
-		err := problemmetrics.GlobalProblemMetricsManager.IncrementProblemCounter(rule.Reason, 0)
+		err := problemmetrics.GlobalProblemMetricsManager.IncrementProblemCounterWithLabels(rule.Reason, severityLabels(severity), 0)
 		if err != nil {
 			klog.Fatalf("Failed to initialize problem counter metrics for %q: %v", rule.Reason, err)
 		}
@@ -120,6 +187,14 @@ func (c *customPluginMonitor) Stop() {
 	c.tomb.Stop()
 }
 
+func (c *customPluginMonitor) GetConditions() []types.Condition {
+	return append([]types.Condition{}, c.conditions...)
+}
+
+func (c *customPluginMonitor) RestoreConditions(conditions []types.Condition) {
+	c.previousConditions = conditions
+}
+
 // monitorLoop is the main loop of customPluginMonitor.
 // there is one customPluginMonitor, one plugin instance for each configPath.
 // each runs rules in parallel at pre-configured concurrency, and interval.
@@ -132,6 +207,12 @@ func (c *customPluginMonitor) monitorLoop() {
 	}
 
 	resultChan := c.plugin.GetResultChan()
+	var summaryCh <-chan time.Time
+	if len(c.limiters) != 0 {
+		ticker := time.NewTicker(summaryCheckPeriod)
+		defer ticker.Stop()
+		summaryCh = ticker.C
+	}
 
 	for {
 		select {
@@ -143,7 +224,12 @@ func (c *customPluginMonitor) monitorLoop() {
 			klog.V(3).Infof("Receive new plugin result for %s: %+v", c.configPath, result)
 			status := c.generateStatus(result)
 			klog.V(3).Infof("New status generated: %+v", status)
-			c.statusChan <- status
+			c.sendStatus(status)
+		case <-summaryCh:
+			if status := c.summarizeSuppressedEvents(time.Now()); status != nil {
+				klog.V(0).Infof("New status generated: %+v", status)
+				c.sendStatus(status)
+			}
 		case <-c.tomb.Stopping():
 			c.plugin.Stop()
 			klog.Infof("Custom plugin monitor stopped: %s", c.configPath)
@@ -153,139 +239,322 @@ func (c *customPluginMonitor) monitorLoop() {
 	}
 }
 
+// sendStatus sends the status to the problem detector and records how long it
+// had to wait.
+func (c *customPluginMonitor) sendStatus(status *types.Status) {
+	start := time.Now()
+	c.statusChan <- status
+	if err := monitormetrics.GlobalMonitorMetricsManager.ObserveStatusSendLatency(c.config.Source, time.Since(start)); err != nil {
+		klog.Errorf("Failed to update status send latency metrics for %q: %v", c.config.Source, err)
+	}
+}
+
 // generateStatus generates status from the plugin check result.
 func (c *customPluginMonitor) generateStatus(result cpmtypes.Result) *types.Status {
 	timestamp := time.Now()
 	var activeProblemEvents []types.Event
 	var inactiveProblemEvents []types.Event
+	addEvent := func(event *types.Event, active bool) {
+		if event == nil {
+			return
+		}
+		if active {
+			activeProblemEvents = append(activeProblemEvents, *event)
+		} else {
+			inactiveProblemEvents = append(inactiveProblemEvents, *event)
+		}
+	}
+	// The reason reported by the plugin takes precedence over the one in the rule.
+	reason := result.Rule.Reason
+	if result.Reason != "" {
+		reason = result.Reason
+	}
+	severity := result.Rule.ProblemSeverity()
+	if result.Severity != "" {
+		severity = result.Severity
+	}
 	if result.Rule.Type == types.Temp {
 		// For temporary error only generate event when exit status is above warning
 		if result.ExitStatus >= cpmtypes.NonOK {
 			activeProblemEvents = append(activeProblemEvents, types.Event{
-				Severity:  types.Warn,
+				Severity:  eventSeverity(severity),
 				Timestamp: timestamp,
-				Reason:    result.Rule.Reason,
+				Reason:    reason,
 				Message:   result.Message,
 			})
 		}
 	} else {
 		// For permanent error that changes the condition
-		for i := range c.conditions {
-			condition := &c.conditions[i]
-			if condition.Type == result.Rule.Condition {
-				// The condition reason specified in the rule and the result message
-				// represent the problem happened. We need to know the default condition
-				// from the config, so that we can set the new condition reason/message
-				// back when such problem goes away.
-				var defaultConditionReason string
-				var defaultConditionMessage string
-				for j := range c.config.DefaultConditions {
-					defaultCondition := &c.config.DefaultConditions[j]
-					if defaultCondition.Type == result.Rule.Condition {
-						defaultConditionReason = defaultCondition.Reason
-						defaultConditionMessage = defaultCondition.Message
-						break
-					}
-				}
-
-				needToUpdateCondition := true
-				var newReason string
-				var newMessage string
-				status := toConditionStatus(result.ExitStatus)
-				if condition.Status == types.True && status != types.True {
-					// Scenario 1: Condition status changes from True to False/Unknown
-					newReason = defaultConditionReason
-					if status == types.False {
-						newMessage = defaultConditionMessage
-					} else {
-						// When status unknown, the result's message is important for debug
-						newMessage = result.Message
-					}
-				} else if condition.Status != types.True && status == types.True {
-					// Scenario 2: Condition status changes from False/Unknown to True
-					newReason = result.Rule.Reason
-					newMessage = result.Message
-				} else if condition.Status != status {
-					// Scenario 3: Condition status changes from False to Unknown or vice versa
-					newReason = defaultConditionReason
-					if status == types.False {
-						newMessage = defaultConditionMessage
-					} else {
-						// When status unknown, the result's message is important for debug
-						newMessage = result.Message
-					}
-				} else if condition.Status == types.True && status == types.True &&
-					(condition.Reason != result.Rule.Reason ||
-						(*c.config.PluginGlobalConfig.EnableMessageChangeBasedConditionUpdate && condition.Message != result.Message)) {
-					// Scenario 4: Condition status does not change and it stays true.
-					// condition reason changes or
-					// condition message changes when message based condition update is enabled.
-					newReason = result.Rule.Reason
-					newMessage = result.Message
-				} else {
-					// Scenario 5: Condition status does not change and it stays False/Unknown.
-					// This should just be the default reason or message (as a consequence
-					// of scenario 1 and scenario 3 above).
-					needToUpdateCondition = false
-				}
-
-				if needToUpdateCondition {
-					condition.Transition = timestamp
-					condition.Status = status
-					condition.Reason = newReason
-					condition.Message = newMessage
-
-					updateEvent := util.GenerateConditionChangeEvent(
-						condition.Type,
-						status,
-						newReason,
-						newMessage,
-						timestamp,
-					)
-
-					if status == types.True {
-						activeProblemEvents = append(activeProblemEvents, updateEvent)
-					} else {
-						inactiveProblemEvents = append(inactiveProblemEvents, updateEvent)
-					}
-				}
-
-				break
-			}
+		if status, ok := c.dampStatus(result.Rule, result.Rule.Condition, result.ExitStatus); ok {
+			addEvent(c.updateCondition(result.Rule.Condition, status, reason, result.Message, severity, timestamp))
+		}
+	}
+	for _, sub := range result.Conditions {
+		subReason := sub.Reason
+		if subReason == "" {
+			subReason = reason
+		}
+		if !c.hasCondition(sub.Type) {
+			klog.Warningf("Ignoring condition %q reported by rule %+v: it is not a default condition", sub.Type, result.Rule)
+			continue
+		}
+		if status, ok := c.dampStatus(result.Rule, sub.Type, sub.Status); ok {
+			addEvent(c.updateCondition(sub.Type, status, subReason, sub.Message, severity, timestamp))
 		}
 	}
 	if *c.config.EnableMetricsReporting {
 		// Increment problem counter only for active problems which just got detected.
 		for _, event := range activeProblemEvents {
-			err := problemmetrics.GlobalProblemMetricsManager.IncrementProblemCounter(
-				event.Reason, 1)
+			err := problemmetrics.GlobalProblemMetricsManager.IncrementProblemCounterWithLabels(
+				event.Reason, severityLabels(severity), 1)
 			if err != nil {
 				klog.Errorf("Failed to update problem counter metrics for %q: %v",
 					event.Reason, err)
 			}
 		}
 		for _, condition := range c.conditions {
-			err := problemmetrics.GlobalProblemMetricsManager.SetProblemGauge(
-				condition.Type, condition.Reason, condition.Status == types.True)
+			err := problemmetrics.GlobalProblemMetricsManager.SetProblemGaugeWithSeverity(
+				condition.Type, condition.Reason, string(condition.Severity), condition.Status == types.True)
 			if err != nil {
 				klog.Errorf("Failed to update problem gauge metrics for problem %q, reason %q: %v",
 					condition.Type, condition.Reason, err)
 			}
 		}
+		recordPluginMetrics(result.Rule.MetricName(), result.Metrics)
+	}
+	events := append(activeProblemEvents, inactiveProblemEvents...)
+	if limiter := c.limiters[result.Rule]; limiter != nil {
+		// The problem counter above still counts the suppressed events.
+		events = limitEvents(limiter, events, result.ExitStatus >= cpmtypes.NonOK, timestamp)
 	}
 	status := &types.Status{
 		Source: c.config.Source,
 		// TODO(random-liu): Aggregate events and conditions and then do periodically report.
-		Events:     append(activeProblemEvents, inactiveProblemEvents...),
+		Events:     events,
 		Conditions: c.conditions,
 	}
 	// Log only if condition has changed
-	if len(activeProblemEvents) != 0 || len(inactiveProblemEvents) != 0 {
+	if len(events) != 0 {
 		klog.V(0).Infof("New status generated: %+v", status)
 	}
 	return status
 }
 
+// dampStatus applies the unknown policy and the failure and success thresholds
+// of the rule to the status it reported for the condition. It returns the
+// status the condition is updated with, false if the condition is kept as it
+// is. A status which does not change the condition status, e.g. NonOK for a
+// True condition, is applied right away to update its reason and message.
+func (c *customPluginMonitor) dampStatus(rule *cpmtypes.CustomRule, conditionType string, status cpmtypes.Status) (cpmtypes.Status, bool) {
+	if status == cpmtypes.Unknown {
+		switch rule.UnknownPolicy {
+		case cpmtypes.UnknownPolicyKeep:
+			return status, false
+		case cpmtypes.UnknownPolicyFailure:
+			status = cpmtypes.NonOK
+		}
+	}
+	failureThreshold, successThreshold := rule.FailuresToTrigger(), rule.SuccessesToRecover()
+	if failureThreshold == 1 && successThreshold == 1 {
+		return status, true
+	}
+	key := streakKey{rule: rule, condition: conditionType}
+	s, ok := c.streaks[key]
+	if !ok {
+		s = &streak{}
+		c.streaks[key] = s
+	}
+	current := c.conditionStatus(conditionType)
+	switch status {
+	case cpmtypes.OK:
+		s.failures = 0
+		s.successes++
+		if s.successes < successThreshold && current != types.False {
+			klog.V(2).Infof("Condition %s kept %s after %d of %d OK results of rule %+v", conditionType, current, s.successes, successThreshold, rule)
+			return status, false
+		}
+	case cpmtypes.NonOK:
+		s.successes = 0
+		s.failures++
+		if s.failures < failureThreshold && current != types.True {
+			klog.V(2).Infof("Condition %s kept %s after %d of %d NonOK results of rule %+v", conditionType, current, s.failures, failureThreshold, rule)
+			return status, false
+		}
+	default:
+		*s = streak{}
+	}
+	return status, true
+}
+
+// conditionStatus returns the status of the condition of the given type.
+func (c *customPluginMonitor) conditionStatus(conditionType string) types.ConditionStatus {
+	for _, condition := range c.conditions {
+		if condition.Type == conditionType {
+			return condition.Status
+		}
+	}
+	return types.Unknown
+}
+
+// limitEvents applies the cooldown of a temporary rule to the events of its
+// result, the first of which is the temporary problem event if the plugin
+// reported a problem. The summary of the events suppressed in the window that
+// elapsed at now is reported first. The summary ticker of the monitor loop
+// reports the summaries of the rules without a next result, e.g. a quiet
+// stream rule.
+func limitEvents(limiter *util.EventLimiter, events []types.Event, problem bool, now time.Time) []types.Event {
+	var limited []types.Event
+	if summary, ok := limiter.Summary(now); ok {
+		limited = append(limited, summary)
+	}
+	if problem && !limiter.Allow(events[0], now) {
+		klog.V(2).Infof("Event suppressed by the cooldown of its rule: %+v", events[0])
+		events = events[1:]
+	}
+	return append(limited, events...)
+}
+
+// summarizeSuppressedEvents returns the status with the summaries of the
+// events suppressed in the cooldown windows elapsed at now. It returns nil if
+// there is no summary.
+func (c *customPluginMonitor) summarizeSuppressedEvents(now time.Time) *types.Status {
+	var events []types.Event
+	for _, rule := range c.config.Rules {
+		limiter := c.limiters[rule]
+		if limiter == nil {
+			continue
+		}
+		if summary, ok := limiter.Summary(now); ok {
+			events = append(events, summary)
+		}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return &types.Status{
+		Source:     c.config.Source,
+		Events:     events,
+		Conditions: c.conditions,
+	}
+}
+
+// updateCondition updates the condition of the given type with the status,
+// reason and message reported by a plugin, and the severity it reports or the
+// severity of its rule. It returns the condition change event, or nil if the
+// condition did not change, and whether the problem is active.
+func (c *customPluginMonitor) updateCondition(conditionType string, exitStatus cpmtypes.Status, reason, message string, severity types.Severity, timestamp time.Time) (*types.Event, bool) {
+	for i := range c.conditions {
+		condition := &c.conditions[i]
+		if condition.Type != conditionType {
+			continue
+		}
+		// The condition reason specified in the rule and the result message
+		// represent the problem happened. We need to know the default condition
+		// from the config, so that we can set the new condition reason/message
+		// back when such problem goes away.
+		var defaultConditionReason string
+		var defaultConditionMessage string
+		for j := range c.config.DefaultConditions {
+			defaultCondition := &c.config.DefaultConditions[j]
+			if defaultCondition.Type == conditionType {
+				defaultConditionReason = defaultCondition.Reason
+				defaultConditionMessage = defaultCondition.Message
+				break
+			}
+		}
+
+		var newReason string
+		var newMessage string
+		status := toConditionStatus(exitStatus)
+		if condition.Status == types.True && status != types.True {
+			// Scenario 1: Condition status changes from True to False/Unknown
+			newReason = defaultConditionReason
+			if status == types.False {
+				newMessage = defaultConditionMessage
+			} else {
+				// When status unknown, the result's message is important for debug
+				newMessage = message
+			}
+		} else if condition.Status != types.True && status == types.True {
+			// Scenario 2: Condition status changes from False/Unknown to True
+			newReason = reason
+			newMessage = message
+		} else if condition.Status != status {
+			// Scenario 3: Condition status changes from False to Unknown or vice versa
+			newReason = defaultConditionReason
+			if status == types.False {
+				newMessage = defaultConditionMessage
+			} else {
+				// When status unknown, the result's message is important for debug
+				newMessage = message
+			}
+		} else if condition.Status == types.True && status == types.True &&
+			(condition.Reason != reason || condition.Severity != severity ||
+				(*c.config.PluginGlobalConfig.EnableMessageChangeBasedConditionUpdate && condition.Message != message)) {
+			// Scenario 4: Condition status does not change and it stays true.
+			// condition reason changes or
+			// condition severity changes, e.g. from warning to critical, or
+			// condition message changes when message based condition update is enabled.
+			newReason = reason
+			newMessage = message
+		} else {
+			// Scenario 5: Condition status does not change and it stays False/Unknown.
+			// This should just be the default reason or message (as a consequence
+			// of scenario 1 and scenario 3 above).
+			return nil, false
+		}
+
+		condition.Transition = timestamp
+		condition.Status = status
+		condition.Reason = newReason
+		condition.Message = newMessage
+		condition.Severity = ""
+		if status == types.True {
+			condition.Severity = severity
+		}
+
+		updateEvent := util.GenerateConditionChangeEvent(
+			condition.Type,
+			status,
+			newReason,
+			newMessage,
+			timestamp,
+		)
+		if status == types.True {
+			updateEvent.Severity = eventSeverity(severity)
+		}
+		return &updateEvent, status == types.True
+	}
+	return nil, false
+}
+
+// hasCondition returns whether the monitor handles the condition type.
+func (c *customPluginMonitor) hasCondition(conditionType string) bool {
+	for _, condition := range c.conditions {
+		if condition.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// eventSeverity returns the severity of the problem events of a rule with the
+// severity.
+func eventSeverity(severity types.Severity) types.Severity {
+	if severity == "" {
+		return types.Warn
+	}
+	return severity
+}
+
+// severityLabels returns the problem counter labels of the severity.
+func severityLabels(severity types.Severity) map[string]string {
+	if severity == "" {
+		return nil
+	}
+	return map[string]string{"severity": string(severity)}
+}
+
 func toConditionStatus(s cpmtypes.Status) types.ConditionStatus {
 	switch s {
 	case cpmtypes.OK:
@@ -310,6 +579,19 @@ func (c *customPluginMonitor) sendInitialStatus() {
 // initializeConditions initializes the internal node conditions.
 func (c *customPluginMonitor) initializeConditions() {
 	c.conditions = initialConditions(c.config.DefaultConditions)
+	if len(c.previousConditions) != 0 {
+		c.conditions = util.CarryOverConditions(c.conditions, c.previousConditions)
+		if *c.config.EnableMetricsReporting {
+			for _, condition := range c.conditions {
+				err := problemmetrics.GlobalProblemMetricsManager.SetProblemGaugeWithSeverity(
+					condition.Type, condition.Reason, string(condition.Severity), condition.Status == types.True)
+				if err != nil {
+					klog.Errorf("Failed to update problem gauge metrics for problem %q, reason %q: %v",
+						condition.Type, condition.Reason, err)
+				}
+			}
+		}
+	}
 	klog.Infof("Initialized conditions for %s: %+v", c.configPath, c.conditions)
 }
 
@@ -26,6 +26,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
 
 	"k8s.io/node-problem-detector/pkg/custompluginmonitor/plugin"
 	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
@@ -106,6 +107,7 @@ func newTestMonitor(t *testing.T, o testOptions) *customPluginMonitor {
 		config:     cfg,
 		plugin:     plugin.NewPlugin(cfg),
 		statusChan: make(chan *types.Status, 1000),
+		streaks:    make(map[streakKey]*streak),
 		tomb:       tomb.NewTomb(),
 	}
 	c.initializeConditions()
@@ -435,6 +437,202 @@ func TestGenerateStatusForTemporaryProblem(t *testing.T) {
 	}
 }
 
+func TestGenerateStatusAppliesThresholds(t *testing.T) {
+	c := newTestMonitor(t, testOptions{defaultConditions: defaultTestConditions()})
+	rule := &cpmtypes.CustomRule{
+		Type:             types.Perm,
+		Condition:        testCondition,
+		Reason:           testProblemReason,
+		FailureThreshold: 3,
+		SuccessThreshold: 2,
+	}
+	steps := []struct {
+		exitStatus cpmtypes.Status
+		message    string
+		wantStatus types.ConditionStatus
+		wantEvent  bool
+	}{
+		{exitStatus: cpmtypes.NonOK, message: "flake", wantStatus: types.False},
+		{exitStatus: cpmtypes.NonOK, message: "flake", wantStatus: types.False},
+		// An OK result breaks the streak of NonOK results.
+		{exitStatus: cpmtypes.OK, wantStatus: types.False},
+		{exitStatus: cpmtypes.NonOK, message: "broken", wantStatus: types.False},
+		{exitStatus: cpmtypes.NonOK, message: "broken", wantStatus: types.False},
+		{exitStatus: cpmtypes.NonOK, message: "broken", wantStatus: types.True, wantEvent: true},
+		{exitStatus: cpmtypes.OK, wantStatus: types.True},
+		// A NonOK result for a True condition is applied right away.
+		{exitStatus: cpmtypes.NonOK, message: "still broken", wantStatus: types.True},
+		{exitStatus: cpmtypes.OK, wantStatus: types.True},
+		{exitStatus: cpmtypes.OK, wantStatus: types.False, wantEvent: true},
+	}
+	for i, step := range steps {
+		got := c.generateStatus(cpmtypes.Result{Rule: rule, ExitStatus: step.exitStatus, Message: step.message})
+		assert.Equal(t, step.wantStatus, got.Conditions[1].Status, "step %d", i)
+		assert.Equal(t, step.wantEvent, len(got.Events) == 1, "step %d: events %+v", i, got.Events)
+	}
+}
+
+func TestGenerateStatusAppliesUnknownPolicy(t *testing.T) {
+	for _, test := range []struct {
+		policy     cpmtypes.UnknownPolicy
+		wantStatus types.ConditionStatus
+	}{
+		{policy: "", wantStatus: types.Unknown},
+		{policy: cpmtypes.UnknownPolicyUnknown, wantStatus: types.Unknown},
+		{policy: cpmtypes.UnknownPolicyKeep, wantStatus: types.False},
+		{policy: cpmtypes.UnknownPolicyFailure, wantStatus: types.True},
+	} {
+		t.Run(string(test.policy), func(t *testing.T) {
+			c := newTestMonitor(t, testOptions{defaultConditions: defaultTestConditions()})
+			rule := &cpmtypes.CustomRule{
+				Type:             types.Perm,
+				Condition:        testCondition,
+				Reason:           testProblemReason,
+				FailureThreshold: 2,
+				UnknownPolicy:    test.policy,
+			}
+			c.generateStatus(cpmtypes.Result{Rule: rule, ExitStatus: cpmtypes.NonOK, Message: "broken"})
+			got := c.generateStatus(cpmtypes.Result{Rule: rule, ExitStatus: cpmtypes.Unknown, Message: "timeout"})
+			assert.Equal(t, test.wantStatus, got.Conditions[1].Status)
+		})
+	}
+}
+
+func TestGenerateStatusLimitsTemporaryEvents(t *testing.T) {
+	fakeCounter, _ := stubProblemMetrics(t)
+	cooldown := "5m"
+	rule := &cpmtypes.CustomRule{Type: types.Temp, Reason: testTempReason, Path: testPluginScript("ok"), CooldownString: &cooldown}
+	c := newTestMonitor(t, testOptions{
+		defaultConditions:      defaultTestConditions(),
+		rules:                  []*cpmtypes.CustomRule{rule},
+		enableMetricsReporting: true,
+	})
+	c.limiters = newEventLimiters(c.config.Rules)
+	result := func(status cpmtypes.Status, message string) cpmtypes.Result {
+		return cpmtypes.Result{Rule: rule, ExitStatus: status, Message: message}
+	}
+
+	got := c.generateStatus(result(cpmtypes.NonOK, "first"))
+	require.Len(t, got.Events, 1)
+	assert.Equal(t, "first", got.Events[0].Message)
+	assert.Empty(t, c.generateStatus(result(cpmtypes.NonOK, "second")).Events)
+	assert.Empty(t, c.generateStatus(result(cpmtypes.NonOK, "third")).Events)
+	assert.Empty(t, c.generateStatus(result(cpmtypes.OK, "")).Events)
+
+	// Every occurrence is counted, including the suppressed ones.
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_counter", Labels: map[string]string{"reason": testTempReason}, Value: 3},
+	}, fakeCounter.ListMetrics())
+
+	// The suppressed events are summarized with the first result after the
+	// window elapsed.
+	limiter := c.limiters[rule]
+	deadline, ok := limiter.Deadline()
+	require.True(t, ok)
+	summary, ok := limiter.Summary(deadline)
+	require.True(t, ok)
+	assert.Equal(t, "third (and 1 more in the last 5m0s)", summary.Message)
+	assert.Equal(t, testTempReason, summary.Reason)
+}
+
+func TestLimitEvents(t *testing.T) {
+	start := time.Unix(1000, 0)
+	limiter := util.NewEventLimiter(time.Minute, 1)
+	problem := types.Event{Reason: testTempReason, Timestamp: start, Message: "problem"}
+	condition := types.Event{Reason: testConditionOK, Timestamp: start, Message: "condition"}
+
+	assert.Equal(t, []types.Event{problem, condition}, limitEvents(limiter, []types.Event{problem, condition}, true, start))
+	// The events of the conditions reported with the problem are not limited.
+	assert.Equal(t, []types.Event{condition}, limitEvents(limiter, []types.Event{problem, condition}, true, start))
+	assert.Equal(t, []types.Event{condition}, limitEvents(limiter, []types.Event{condition}, false, start))
+
+	// The summary comes first once the window elapsed.
+	summary := problem
+	summary.Timestamp = start.Add(time.Minute)
+	next := problem
+	next.Timestamp = start.Add(time.Minute)
+	assert.Equal(t, []types.Event{summary, next}, limitEvents(limiter, []types.Event{next}, true, start.Add(time.Minute)))
+}
+
+func TestSummarizeSuppressedEvents(t *testing.T) {
+	stubProblemMetrics(t)
+	cooldown := "1m"
+	rule := &cpmtypes.CustomRule{Type: types.Temp, Reason: testTempReason, Path: testPluginScript("ok"), CooldownString: &cooldown}
+	c := newTestMonitor(t, testOptions{
+		defaultConditions: defaultTestConditions(),
+		rules:             []*cpmtypes.CustomRule{rule},
+	})
+	c.limiters = newEventLimiters(c.config.Rules)
+	assert.Nil(t, c.summarizeSuppressedEvents(time.Now()))
+
+	// A quiet stream rule has no next result to report the summary with.
+	c.generateStatus(cpmtypes.Result{Rule: rule, ExitStatus: cpmtypes.NonOK, Message: "first"})
+	c.generateStatus(cpmtypes.Result{Rule: rule, ExitStatus: cpmtypes.NonOK, Message: "second"})
+	deadline, ok := c.limiters[rule].Deadline()
+	require.True(t, ok)
+	assert.Nil(t, c.summarizeSuppressedEvents(deadline.Add(-time.Second)))
+	status := c.summarizeSuppressedEvents(deadline)
+	require.NotNil(t, status)
+	assert.Equal(t, testSource, status.Source)
+	require.Len(t, status.Events, 1)
+	assert.Equal(t, "second", status.Events[0].Message)
+	assert.Equal(t, c.conditions, status.Conditions)
+	assert.Nil(t, c.summarizeSuppressedEvents(deadline.Add(time.Minute)))
+}
+
+func TestGenerateStatusReportsRuleSeverity(t *testing.T) {
+	fakeCounter, fakeGauge := stubProblemMetrics(t)
+	c := newTestMonitor(t, testOptions{
+		defaultConditions:      defaultTestConditions(),
+		enableMetricsReporting: true,
+	})
+	result := permResult(cpmtypes.NonOK, testProblemReason, "boom")
+	result.Rule.Severity = "critical"
+
+	got := c.generateStatus(result)
+	require.Len(t, got.Events, 1)
+	assert.Equal(t, types.Critical, got.Events[0].Severity)
+	assert.Equal(t, types.Critical, got.Conditions[1].Severity)
+
+	result = permResult(cpmtypes.OK, testProblemReason, "all good")
+	result.Rule.Severity = "critical"
+	got = c.generateStatus(result)
+	require.Len(t, got.Events, 1)
+	assert.Equal(t, types.Info, got.Events[0].Severity)
+	assert.Empty(t, got.Conditions[1].Severity)
+
+	gotMetrics := append(fakeCounter.ListMetrics(), fakeGauge.ListMetrics()...)
+	assert.ElementsMatch(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_counter", Labels: map[string]string{"reason": testProblemReason, "severity": "critical"}, Value: 1},
+		{Name: "problem_gauge", Labels: map[string]string{"type": otherCondition, "reason": otherConditionOK}, Value: 0},
+		{Name: "problem_gauge", Labels: map[string]string{"type": testCondition, "reason": testProblemReason, "severity": "critical"}, Value: 0},
+		{Name: "problem_gauge", Labels: map[string]string{"type": testCondition, "reason": testConditionOK}, Value: 0},
+	}, gotMetrics)
+}
+
+func TestGenerateStatusReportsResultSeverity(t *testing.T) {
+	c := newTestMonitor(t, testOptions{defaultConditions: defaultTestConditions()})
+
+	result := permResult(cpmtypes.NonOK, testProblemReason, "disk is filling up")
+	result.Rule.Severity = "error"
+	result.Severity = types.Warn
+	got := c.generateStatus(result)
+	require.Len(t, got.Events, 1)
+	assert.Equal(t, types.Warn, got.Events[0].Severity)
+	assert.Equal(t, types.Warn, got.Conditions[1].Severity)
+
+	// The condition stays True, and is updated when the problem escalates.
+	result.Severity = types.Critical
+	got = c.generateStatus(result)
+	require.Len(t, got.Events, 1)
+	assert.Equal(t, types.Critical, got.Events[0].Severity)
+	assert.Equal(t, types.Critical, got.Conditions[1].Severity)
+
+	got = c.generateStatus(result)
+	assert.Empty(t, got.Events)
+	assert.Equal(t, types.Critical, got.Conditions[1].Severity)
+}
+
 func TestGenerateStatusMetrics(t *testing.T) {
 	testCases := []struct {
 		name                   string
@@ -514,6 +712,74 @@ func TestGenerateStatusMetrics(t *testing.T) {
 	}
 }
 
+func TestGenerateStatusForJSONResult(t *testing.T) {
+	c := newTestMonitor(t, testOptions{defaultConditions: defaultTestConditions()})
+
+	result := permResult(cpmtypes.NonOK, testProblemReason, "disk is slow")
+	result.Reason = "DiskSlow"
+	result.Conditions = []cpmtypes.ConditionResult{
+		{Type: otherCondition, Status: cpmtypes.NonOK, Message: "other is broken"},
+		{Type: "NotADefaultCondition", Status: cpmtypes.NonOK},
+	}
+	got := c.generateStatus(result)
+
+	require.Len(t, got.Conditions, 2)
+	assert.Equal(t, types.True, got.Conditions[1].Status)
+	assert.Equal(t, "DiskSlow", got.Conditions[1].Reason)
+	assert.Equal(t, "disk is slow", got.Conditions[1].Message)
+	// A sub-condition without a reason takes the reason of the result.
+	assert.Equal(t, types.True, got.Conditions[0].Status)
+	assert.Equal(t, "DiskSlow", got.Conditions[0].Reason)
+	assert.Equal(t, "other is broken", got.Conditions[0].Message)
+	assert.Len(t, got.Events, 2)
+
+	result = permResult(cpmtypes.OK, testProblemReason, "")
+	result.Conditions = []cpmtypes.ConditionResult{{Type: otherCondition, Status: cpmtypes.OK}}
+	got = c.generateStatus(result)
+	assert.Equal(t, types.False, got.Conditions[0].Status)
+	assert.Equal(t, otherConditionOK, got.Conditions[0].Reason)
+	assert.Equal(t, types.False, got.Conditions[1].Status)
+	assert.Equal(t, testConditionOK, got.Conditions[1].Reason)
+	assert.Len(t, got.Events, 2)
+
+	got = c.generateStatus(cpmtypes.Result{
+		Rule:       &cpmtypes.CustomRule{Type: types.Temp, Reason: testTempReason},
+		ExitStatus: cpmtypes.NonOK,
+		Reason:     "TemporarySlowness",
+		Message:    "temporary problem detail",
+	})
+	require.Len(t, got.Events, 1)
+	assert.Equal(t, "TemporarySlowness", got.Events[0].Reason)
+	assert.Equal(t, "temporary problem detail", got.Events[0].Message)
+}
+
+func TestGenerateStatusRecordsPluginMetrics(t *testing.T) {
+	stubProblemMetrics(t)
+	c := newTestMonitor(t, testOptions{
+		defaultConditions:      defaultTestConditions(),
+		enableMetricsReporting: true,
+	})
+	result := permResult(cpmtypes.OK, testProblemReason, "")
+	result.Rule.Name = "disk-latency"
+	result.Metrics = map[string]float64{"latency_seconds": 0.25}
+	c.generateStatus(result)
+
+	rows, err := view.RetrieveData(string(metrics.CustomPluginMetricID))
+	require.NoError(t, err)
+	var found bool
+	for _, row := range rows {
+		labels := map[string]string{}
+		for _, tag := range row.Tags {
+			labels[tag.Key.Name()] = tag.Value
+		}
+		if labels["rule"] == "disk-latency" && labels["metric"] == "latency_seconds" {
+			found = true
+			assert.Equal(t, 0.25, row.Data.(*view.LastValueData).Value)
+		}
+	}
+	assert.True(t, found, "metric not recorded: %+v", rows)
+}
+
 func TestGenerateStatusSurvivesMetricsFailure(t *testing.T) {
 	original := problemmetrics.GlobalProblemMetricsManager
 	t.Cleanup(func() { problemmetrics.GlobalProblemMetricsManager = original })
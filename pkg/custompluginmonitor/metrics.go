@@ -0,0 +1,61 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custompluginmonitor
+
+import (
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"k8s.io/node-problem-detector/pkg/util/metrics"
+)
+
+var (
+	// pluginMetric records the metrics reported by plugins with the json output
+	// format. It is created when the first metric is recorded.
+	pluginMetric     *metrics.Float64Metric
+	pluginMetricOnce sync.Once
+)
+
+// recordPluginMetrics records the metrics a plugin reported, labelled by the
+// name of the rule and the name of the metric.
+func recordPluginMetrics(rule string, values map[string]float64) {
+	if len(values) == 0 {
+		return
+	}
+	pluginMetricOnce.Do(func() {
+		var err error
+		pluginMetric, err = metrics.NewFloat64Metric(
+			metrics.CustomPluginMetricID,
+			string(metrics.CustomPluginMetricID),
+			"Value of a metric reported by a custom plugin.",
+			"1",
+			metrics.LastValue,
+			[]string{"rule", "metric"})
+		if err != nil {
+			klog.Errorf("Failed to create custom_plugin/metric metric: %v", err)
+		}
+	})
+	if pluginMetric == nil {
+		return
+	}
+	for name, value := range values {
+		if err := pluginMetric.Record(map[string]string{"rule": rule, "metric": name}, value); err != nil {
+			klog.Errorf("Failed to record metric %q of rule %q: %v", name, rule, err)
+		}
+	}
+}
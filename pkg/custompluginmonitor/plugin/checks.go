@@ -0,0 +1,308 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+	"k8s.io/node-problem-detector/pkg/util/metrics"
+)
+
+// maxCheckReadBytes is the max bytes of an http body or a file that a check reads.
+const maxCheckReadBytes = 1024 * 1024
+
+// maxScrapeBytes is the max bytes of the metrics a prometheus check reads. The
+// metrics of the kubelet are several megabytes.
+const maxScrapeBytes = 32 * 1024 * 1024
+
+// sample is a value scraped by a prometheus check.
+type sample struct {
+	value float64
+	time  time.Time
+}
+
+// runCheck runs the built-in check of the rule.
+func (p *Plugin) runCheck(ctx context.Context, rule cpmtypes.CustomRule) (cpmtypes.Status, string) {
+	check := rule.Check
+	switch check.Kind {
+	case cpmtypes.HTTPCheck:
+		return checkHTTP(ctx, check)
+	case cpmtypes.TCPCheck:
+		return checkTCP(ctx, check)
+	case cpmtypes.DNSCheck:
+		return checkDNS(ctx, check)
+	case cpmtypes.FileCheck:
+		return checkFile(check)
+	case cpmtypes.PrometheusCheck:
+		return p.checkPrometheus(ctx, check)
+	case cpmtypes.CommandCheck:
+		exitCode, output := p.runPlugin(ctx, commandRule(rule))
+		return pluginStatus(exitCode), output
+	}
+	return cpmtypes.Unknown, fmt.Sprintf("Unknown check kind %q", check.Kind)
+}
+
+// commandRule returns the rule which runs the command of the command check of
+// the rule as its plugin.
+func commandRule(rule cpmtypes.CustomRule) cpmtypes.CustomRule {
+	rule.Path = rule.Check.Command[0]
+	rule.Args = rule.Check.Command[1:]
+	rule.Check = nil
+	return rule
+}
+
+// checkHTTP checks that a GET request returns the expected status code and a
+// body matching the body pattern.
+func checkHTTP(ctx context.Context, check *cpmtypes.CheckConfig) (cpmtypes.Status, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.URL, nil)
+	if err != nil {
+		return cpmtypes.Unknown, fmt.Sprintf("Failed to create request for %s: %v", check.URL, err)
+	}
+	resp, err := checkClient(check).Do(req)
+	if err != nil {
+		return cpmtypes.NonOK, fmt.Sprintf("GET %s failed: %v", check.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != check.StatusCode {
+		return cpmtypes.NonOK, fmt.Sprintf("GET %s returned status code %d, expected %d", check.URL, resp.StatusCode, check.StatusCode)
+	}
+	if check.BodyRegexp != nil {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxCheckReadBytes))
+		if err != nil {
+			return cpmtypes.NonOK, fmt.Sprintf("Failed to read body of %s: %v", check.URL, err)
+		}
+		if !check.BodyRegexp.Match(body) {
+			return cpmtypes.NonOK, fmt.Sprintf("Body of %s does not match %q", check.URL, check.BodyPattern)
+		}
+	}
+	return cpmtypes.OK, fmt.Sprintf("GET %s returned status code %d", check.URL, resp.StatusCode)
+}
+
+// checkTCP checks that a TCP connection to the address can be established.
+func checkTCP(ctx context.Context, check *cpmtypes.CheckConfig) (cpmtypes.Status, string) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", check.Address)
+	if err != nil {
+		return cpmtypes.NonOK, fmt.Sprintf("Failed to connect to %s: %v", check.Address, err)
+	}
+	conn.Close()
+	return cpmtypes.OK, fmt.Sprintf("Connected to %s", check.Address)
+}
+
+// checkDNS checks that the host name resolves.
+func checkDNS(ctx context.Context, check *cpmtypes.CheckConfig) (cpmtypes.Status, string) {
+	addrs, err := net.DefaultResolver.LookupHost(ctx, check.Host)
+	if err != nil {
+		return cpmtypes.NonOK, fmt.Sprintf("Failed to resolve %s: %v", check.Host, err)
+	}
+	return cpmtypes.OK, fmt.Sprintf("Resolved %s to %s", check.Host, strings.Join(addrs, ","))
+}
+
+// checkFile checks that the file exists, that its content matches the content
+// pattern and that its numeric value satisfies the threshold.
+func checkFile(check *cpmtypes.CheckConfig) (cpmtypes.Status, string) {
+	f, err := os.Open(check.Path)
+	if os.IsNotExist(err) {
+		return cpmtypes.NonOK, fmt.Sprintf("%s does not exist", check.Path)
+	}
+	if err != nil {
+		return cpmtypes.Unknown, fmt.Sprintf("Failed to open %s: %v", check.Path, err)
+	}
+	defer f.Close()
+	if check.ContentRegexp == nil && check.Threshold == nil {
+		return cpmtypes.OK, fmt.Sprintf("%s exists", check.Path)
+	}
+	content, err := io.ReadAll(io.LimitReader(f, maxCheckReadBytes))
+	if err != nil {
+		return cpmtypes.Unknown, fmt.Sprintf("Failed to read %s: %v", check.Path, err)
+	}
+	if check.ContentRegexp != nil && !check.ContentRegexp.Match(content) {
+		return cpmtypes.NonOK, fmt.Sprintf("Content of %s does not match %q", check.Path, check.ContentPattern)
+	}
+	if check.Threshold == nil {
+		return cpmtypes.OK, fmt.Sprintf("Content of %s matches %q", check.Path, check.ContentPattern)
+	}
+	fields := strings.Fields(string(content))
+	if check.Field >= len(fields) {
+		return cpmtypes.Unknown, fmt.Sprintf("%s has no field %d", check.Path, check.Field)
+	}
+	value, err := strconv.ParseFloat(fields[check.Field], 64)
+	if err != nil {
+		return cpmtypes.Unknown, fmt.Sprintf("Field %d of %s is not a number: %q", check.Field, check.Path, fields[check.Field])
+	}
+	if !check.Threshold.Satisfied(value) {
+		return cpmtypes.NonOK, fmt.Sprintf("Value %v of %s does not satisfy %s", value, check.Path, check.Threshold)
+	}
+	return cpmtypes.OK, fmt.Sprintf("Value %v of %s satisfies %s", value, check.Path, check.Threshold)
+}
+
+// checkClient returns the http client of the check, which connects to the
+// socket of the check if it has one.
+func checkClient(check *cpmtypes.CheckConfig) *http.Client {
+	if check.Socket == "" {
+		return http.DefaultClient
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", check.Socket)
+			},
+			// The transport is not reused across checks.
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+// checkPrometheus checks that the series of the check, or its rate since the
+// previous scrape, satisfies the threshold. The check is Unknown when the
+// metrics cannot be scraped, when the series is missing, and on the first
+// scrape of a rate.
+func (p *Plugin) checkPrometheus(ctx context.Context, check *cpmtypes.CheckConfig) (cpmtypes.Status, string) {
+	series := seriesString(check.Metric, check.Labels)
+	scraped, err := scrapeMetrics(ctx, check)
+	if err != nil {
+		return cpmtypes.Unknown, fmt.Sprintf("Failed to scrape %s: %v", check.URL, err)
+	}
+	selected := selectSeries(scraped, check.Metric, check.Labels)
+	if len(selected) == 0 {
+		return cpmtypes.Unknown, fmt.Sprintf("%s has no series %s", check.URL, series)
+	}
+	if len(selected) > 1 {
+		return cpmtypes.Unknown, fmt.Sprintf("Ambiguous selector %s matches %d series of %s", series, len(selected), check.URL)
+	}
+	metric := selected[0]
+
+	value := metric.Value
+	if check.Rate {
+		current := sample{value: metric.Value, time: p.clock.Now()}
+		previous, ok := p.swapSample(check, current)
+		if !ok {
+			return cpmtypes.Unknown, fmt.Sprintf("Waiting for a second scrape of %s to compute its rate", series)
+		}
+		elapsed := current.time.Sub(previous.time).Seconds()
+		if elapsed <= 0 {
+			return cpmtypes.Unknown, fmt.Sprintf("No time elapsed since the previous scrape of %s", series)
+		}
+		increase := current.value - previous.value
+		if increase < 0 {
+			// The counter was reset, e.g. by a restart, and counts from zero.
+			increase = current.value
+		}
+		value = increase / elapsed
+		series = "rate of " + series
+	}
+
+	if !check.Threshold.Satisfied(value) {
+		return cpmtypes.NonOK, fmt.Sprintf("Value %v of %s does not satisfy %s", value, series, check.Threshold)
+	}
+	return cpmtypes.OK, fmt.Sprintf("Value %v of %s satisfies %s", value, series, check.Threshold)
+}
+
+// swapSample stores the sample of the check and returns the previous one, if
+// any.
+func (p *Plugin) swapSample(check *cpmtypes.CheckConfig, current sample) (sample, bool) {
+	p.samplesLock.Lock()
+	defer p.samplesLock.Unlock()
+	if p.samples == nil {
+		p.samples = make(map[*cpmtypes.CheckConfig]sample)
+	}
+	previous, ok := p.samples[check]
+	p.samples[check] = current
+	return previous, ok
+}
+
+// scrapeMetrics requests the metrics of the check and parses them.
+func scrapeMetrics(ctx context.Context, check *cpmtypes.CheckConfig) ([]metrics.Float64MetricRepresentation, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/plain")
+	resp, err := checkClient(check).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	body, err := readAtMost(resp.Body, maxScrapeBytes)
+	if err != nil {
+		return nil, err
+	}
+	return metrics.ParsePrometheusMetrics(string(body))
+}
+
+// readAtMost reads the reader to the end, and fails if it has more than
+// maxBytes bytes, rather than returning them truncated.
+func readAtMost(reader io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("body is larger than %d bytes", maxBytes)
+	}
+	return data, nil
+}
+
+// selectSeries returns the series with the name and the labels. The series
+// may have other labels.
+func selectSeries(scraped []metrics.Float64MetricRepresentation, name string, labels map[string]string) []metrics.Float64MetricRepresentation {
+	var selected []metrics.Float64MetricRepresentation
+	for _, metric := range scraped {
+		if metric.Name != name {
+			continue
+		}
+		matches := true
+		for key, value := range labels {
+			if metric.Labels[key] != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			selected = append(selected, metric)
+		}
+	}
+	return selected
+}
+
+// seriesString formats the metric name and labels of a series as in the
+// Prometheus text format, e.g. up{job="kubelet"}.
+func seriesString(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+	var pairs []string
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, labels[key]))
+	}
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,347 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testclock "k8s.io/utils/clock/testing"
+
+	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+)
+
+func runCheck(t *testing.T, check cpmtypes.CheckConfig) (cpmtypes.Status, string) {
+	t.Helper()
+	conf := cpmtypes.CustomPluginConfig{Rules: []*cpmtypes.CustomRule{{Check: &check}}}
+	if err := (&conf).ApplyConfiguration(); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+	if err := check.Validate(); err != nil {
+		t.Fatalf("Invalid check: %v", err)
+	}
+	p := Plugin{config: conf}
+	result := p.run(conf.Rules[0])
+	return result.ExitStatus, result.Message
+}
+
+func TestHTTPCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unhealthy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write([]byte("status: ok"))
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		name     string
+		check    cpmtypes.CheckConfig
+		expected cpmtypes.Status
+	}{
+		{
+			name:     "expected status code",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.HTTPCheck, URL: server.URL + "/healthz"},
+			expected: cpmtypes.OK,
+		},
+		{
+			name:     "unexpected status code",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.HTTPCheck, URL: server.URL + "/unhealthy"},
+			expected: cpmtypes.NonOK,
+		},
+		{
+			name:     "configured status code",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.HTTPCheck, URL: server.URL + "/unhealthy", StatusCode: http.StatusServiceUnavailable},
+			expected: cpmtypes.OK,
+		},
+		{
+			name:     "body matches",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.HTTPCheck, URL: server.URL, BodyPattern: "status: (ok|degraded)"},
+			expected: cpmtypes.OK,
+		},
+		{
+			name:     "body does not match",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.HTTPCheck, URL: server.URL, BodyPattern: "status: failed"},
+			expected: cpmtypes.NonOK,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			status, message := runCheck(t, test.check)
+			assert.Equal(t, test.expected, status, message)
+		})
+	}
+
+	server.Close()
+	status, message := runCheck(t, cpmtypes.CheckConfig{Kind: cpmtypes.HTTPCheck, URL: server.URL})
+	assert.Equal(t, cpmtypes.NonOK, status, message)
+}
+
+func TestTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+
+	status, message := runCheck(t, cpmtypes.CheckConfig{Kind: cpmtypes.TCPCheck, Address: address})
+	assert.Equal(t, cpmtypes.OK, status, message)
+
+	listener.Close()
+	status, message = runCheck(t, cpmtypes.CheckConfig{Kind: cpmtypes.TCPCheck, Address: address})
+	assert.Equal(t, cpmtypes.NonOK, status, message)
+}
+
+func TestDNSCheck(t *testing.T) {
+	status, message := runCheck(t, cpmtypes.CheckConfig{Kind: cpmtypes.DNSCheck, Host: "localhost"})
+	assert.Equal(t, cpmtypes.OK, status, message)
+
+	status, message = runCheck(t, cpmtypes.CheckConfig{Kind: cpmtypes.DNSCheck, Host: "not-exist.invalid"})
+	assert.Equal(t, cpmtypes.NonOK, status, message)
+}
+
+func TestFileCheck(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file-nr")
+	if err := os.WriteFile(path, []byte("1024\t0\t9223372036854775807\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		check    cpmtypes.CheckConfig
+		expected cpmtypes.Status
+	}{
+		{
+			name:     "file exists",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.FileCheck, Path: path},
+			expected: cpmtypes.OK,
+		},
+		{
+			name:     "file does not exist",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.FileCheck, Path: filepath.Join(dir, "not-exist")},
+			expected: cpmtypes.NonOK,
+		},
+		{
+			name:     "content matches",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.FileCheck, Path: path, ContentPattern: `^\d+\s`},
+			expected: cpmtypes.OK,
+		},
+		{
+			name:     "content does not match",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.FileCheck, Path: path, ContentPattern: `^0\s`},
+			expected: cpmtypes.NonOK,
+		},
+		{
+			name:     "value satisfies threshold",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.FileCheck, Path: path, ThresholdString: "< 2048"},
+			expected: cpmtypes.OK,
+		},
+		{
+			name:     "value of field does not satisfy threshold",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.FileCheck, Path: path, ThresholdString: "< 2048", Field: 2},
+			expected: cpmtypes.NonOK,
+		},
+		{
+			name:     "missing field",
+			check:    cpmtypes.CheckConfig{Kind: cpmtypes.FileCheck, Path: path, ThresholdString: "< 2048", Field: 3},
+			expected: cpmtypes.Unknown,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			status, message := runCheck(t, test.check)
+			assert.Equal(t, test.expected, status, message)
+		})
+	}
+}
+
+func TestCommandCheck(t *testing.T) {
+	ext := "sh"
+	if runtime.GOOS == "windows" {
+		ext = "cmd"
+	}
+	status, message := runCheck(t, cpmtypes.CheckConfig{Kind: cpmtypes.CommandCheck, Command: []string{"./test-data/non-ok." + ext}})
+	assert.Equal(t, cpmtypes.NonOK, status)
+	assert.Equal(t, "NonOK", message)
+}
+
+// metricsHandler serves the metrics text of the kubelet, with a counter the
+// test sets.
+func metricsHandler(counter *atomic.Int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `# TYPE kubelet_pleg_relist_duration_seconds histogram
+kubelet_pleg_relist_duration_seconds_bucket{le="0.5"} 9
+kubelet_pleg_relist_duration_seconds_bucket{le="+Inf"} 10
+kubelet_pleg_relist_duration_seconds_sum 3.5
+kubelet_pleg_relist_duration_seconds_count 10
+# TYPE kubelet_runtime_operations_errors_total counter
+kubelet_runtime_operations_errors_total{operation_type="create_container"} %d
+kubelet_runtime_operations_errors_total{operation_type="remove_container"} 0
+`, counter.Load())
+	})
+}
+
+func TestPrometheusCheck(t *testing.T) {
+	var counter atomic.Int64
+	counter.Store(3)
+	server := httptest.NewServer(metricsHandler(&counter))
+	defer server.Close()
+
+	for _, test := range []struct {
+		name     string
+		check    cpmtypes.CheckConfig
+		expected cpmtypes.Status
+	}{
+		{
+			name: "value satisfies threshold",
+			check: cpmtypes.CheckConfig{Kind: cpmtypes.PrometheusCheck, URL: server.URL + "/metrics",
+				Metric: "kubelet_pleg_relist_duration_seconds_sum", ThresholdString: "< 10"},
+			expected: cpmtypes.OK,
+		},
+		{
+			name: "value of series with labels does not satisfy threshold",
+			check: cpmtypes.CheckConfig{Kind: cpmtypes.PrometheusCheck, URL: server.URL + "/metrics",
+				Metric: "kubelet_runtime_operations_errors_total", Labels: map[string]string{"operation_type": "create_container"}, ThresholdString: "== 0"},
+			expected: cpmtypes.NonOK,
+		},
+		{
+			name: "histogram bucket",
+			check: cpmtypes.CheckConfig{Kind: cpmtypes.PrometheusCheck, URL: server.URL + "/metrics",
+				Metric: "kubelet_pleg_relist_duration_seconds_bucket", Labels: map[string]string{"le": "+Inf"}, ThresholdString: "== 10"},
+			expected: cpmtypes.OK,
+		},
+		{
+			name: "missing series",
+			check: cpmtypes.CheckConfig{Kind: cpmtypes.PrometheusCheck, URL: server.URL + "/metrics",
+				Metric: "kubelet_runtime_operations_errors_total", Labels: map[string]string{"operation_type": "pull_image"}, ThresholdString: "== 0"},
+			expected: cpmtypes.Unknown,
+		},
+		{
+			name: "scrape fails",
+			check: cpmtypes.CheckConfig{Kind: cpmtypes.PrometheusCheck, URL: server.URL + "/not-found",
+				Metric: "up", ThresholdString: "== 1"},
+			expected: cpmtypes.Unknown,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			status, message := runCheck(t, test.check)
+			assert.Equal(t, test.expected, status, message)
+		})
+	}
+}
+
+func TestPrometheusCheckRate(t *testing.T) {
+	var counter atomic.Int64
+	counter.Store(100)
+	server := httptest.NewServer(metricsHandler(&counter))
+	defer server.Close()
+
+	check := &cpmtypes.CheckConfig{Kind: cpmtypes.PrometheusCheck, URL: server.URL + "/metrics",
+		Metric: "kubelet_runtime_operations_errors_total", Labels: map[string]string{"operation_type": "create_container"},
+		ThresholdString: "< 1", Rate: true}
+	if err := check.ApplyConfiguration(); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+	fakeClock := testclock.NewFakeClock(time.Unix(0, 0))
+	p := Plugin{clock: fakeClock}
+
+	status, message := p.checkPrometheus(context.Background(), check)
+	assert.Equal(t, cpmtypes.Unknown, status, message)
+
+	fakeClock.Step(10 * time.Second)
+	counter.Store(105)
+	status, message = p.checkPrometheus(context.Background(), check)
+	assert.Equal(t, cpmtypes.OK, status, message)
+	assert.Contains(t, message, "Value 0.5 of rate of")
+
+	fakeClock.Step(10 * time.Second)
+	counter.Store(125)
+	status, message = p.checkPrometheus(context.Background(), check)
+	assert.Equal(t, cpmtypes.NonOK, status, message)
+
+	// The counter restarts from zero.
+	fakeClock.Step(10 * time.Second)
+	counter.Store(2)
+	status, message = p.checkPrometheus(context.Background(), check)
+	assert.Equal(t, cpmtypes.OK, status, message)
+	assert.Contains(t, message, "Value 0.2 of rate of")
+}
+
+func TestPrometheusCheckThroughSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not used on windows")
+	}
+	socket := filepath.Join(t.TempDir(), "metrics.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	var counter atomic.Int64
+	server := &http.Server{Handler: metricsHandler(&counter)}
+	go func() { _ = server.Serve(listener) }()
+	defer func() { _ = server.Close() }()
+
+	status, message := runCheck(t, cpmtypes.CheckConfig{Kind: cpmtypes.PrometheusCheck, URL: "http://localhost/metrics", Socket: socket,
+		Metric: "kubelet_runtime_operations_errors_total", Labels: map[string]string{"operation_type": "remove_container"}, ThresholdString: "== 0"})
+	assert.Equal(t, cpmtypes.OK, status, message)
+	assert.Contains(t, message, `Value 0 of kubelet_runtime_operations_errors_total{operation_type="remove_contai`)
+}
+
+func TestPrometheusCheckAmbiguousSelector(t *testing.T) {
+	var counter atomic.Int64
+	server := httptest.NewServer(metricsHandler(&counter))
+	defer server.Close()
+
+	check := &cpmtypes.CheckConfig{Kind: cpmtypes.PrometheusCheck, URL: server.URL + "/metrics",
+		Metric: "kubelet_runtime_operations_errors_total", ThresholdString: "== 0"}
+	if err := check.ApplyConfiguration(); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+	p := Plugin{clock: testclock.NewFakeClock(time.Unix(0, 0))}
+
+	status, message := p.checkPrometheus(context.Background(), check)
+	assert.Equal(t, cpmtypes.Unknown, status)
+	assert.Equal(t, "Ambiguous selector kubelet_runtime_operations_errors_total matches 2 series of "+server.URL+"/metrics", message)
+}
+
+func TestReadAtMost(t *testing.T) {
+	data, err := readAtMost(strings.NewReader("up 1\n"), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "up 1\n", string(data))
+
+	_, err = readAtMost(strings.NewReader("up 1\n"), 4)
+	assert.EqualError(t, err, "body is larger than 4 bytes")
+}
+
+func TestSeriesString(t *testing.T) {
+	assert.Equal(t, "up", seriesString("up", nil))
+	assert.Equal(t, `up{instance="node-1",job="kubelet"}`, seriesString("up", map[string]string{"job": "kubelet", "instance": "node-1"}))
+}
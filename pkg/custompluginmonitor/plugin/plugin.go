@@ -30,10 +30,16 @@ import (
 	"k8s.io/utils/clock"
 
 	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
+	"k8s.io/node-problem-detector/pkg/types"
 	"k8s.io/node-problem-detector/pkg/util"
 	"k8s.io/node-problem-detector/pkg/util/tomb"
 )
 
+// failedExitCode is the exit code of a plugin which could not be run. Like the
+// exit code of a killed plugin, it is reported as unknown.
+const failedExitCode = -1
+
 // maxCustomPluginBufferBytes is the max bytes that a custom plugin is allowed to
 // send to stdout/stderr. Any bytes exceeding this value will be truncated.
 const maxCustomPluginBufferBytes = 1024 * 4
@@ -44,7 +50,11 @@ type Plugin struct {
 	resultChan chan cpmtypes.Result
 	tomb       *tomb.Tomb
 	clock      clock.WithTicker
-	runFunc    func(cpmtypes.CustomRule) (cpmtypes.Status, string)
+	runFunc    func(*cpmtypes.CustomRule) cpmtypes.Result
+	// samples are the last values scraped by the prometheus checks with a
+	// rate, by check.
+	samples     map[*cpmtypes.CheckConfig]sample
+	samplesLock sync.Mutex
 	sync.WaitGroup
 }
 
@@ -78,6 +88,18 @@ func (p *Plugin) Run() {
 		p.tomb.Done()
 	}()
 
+	var periodicRules []*cpmtypes.CustomRule
+	for _, rule := range p.config.Rules {
+		if rule.IsStream() {
+			p.Add(1)
+			go p.runStream(rule)
+		} else {
+			periodicRules = append(periodicRules, rule)
+		}
+	}
+	// The streaming plugins are stopped with the periodic ones.
+	defer p.Wait()
+
 	groups := p.intervalGroups()
 	if len(groups) == 0 {
 		<-p.tomb.Stopping()
@@ -94,7 +116,7 @@ func (p *Plugin) Run() {
 	}()
 
 	// On boot, run every rule in one batch.
-	if !p.runRules(p.config.Rules) {
+	if !p.runRules(periodicRules) {
 		return
 	}
 
@@ -105,10 +127,14 @@ func (p *Plugin) Run() {
 	p.Wait()
 }
 
+// intervalGroups groups the periodic rules by their invoke interval.
 func (p *Plugin) intervalGroups() []intervalGroup {
 	groups := []intervalGroup{}
 	groupIndexes := make(map[time.Duration]int)
 	for _, rule := range p.config.Rules {
+		if rule.IsStream() {
+			continue
+		}
 		interval := p.effectiveInterval(rule)
 		groupIndex, ok := groupIndexes[interval]
 		if !ok {
@@ -178,20 +204,17 @@ func (p *Plugin) runRules(rules []*cpmtypes.CustomRule) bool {
 			}
 
 			start := time.Now()
-			exitStatus, message := p.runFunc(*rule)
+			result := p.runFunc(rule)
+			if err := monitormetrics.GlobalMonitorMetricsManager.ObservePluginDuration(p.config.Source, rule.MetricName(), time.Since(start)); err != nil {
+				klog.Errorf("Failed to update plugin duration metrics for rule %+v: %v", rule, err)
+			}
 			level := klog.Level(3)
-			if exitStatus != 0 {
+			if result.ExitStatus != cpmtypes.OK {
 				level = klog.Level(2)
 			}
 
 			klog.V(level).Infof("Rule: %+v. Start time: %v. End time: %v. Duration: %v", rule, start, time.Now(), time.Since(start))
 
-			result := cpmtypes.Result{
-				Rule:       rule,
-				ExitStatus: exitStatus,
-				Message:    message,
-			}
-
 			// pipes result into resultChan which customPluginMonitor instance generates status from
 			select {
 			case p.resultChan <- result:
@@ -223,7 +246,8 @@ func readFromReader(reader io.ReadCloser, maxBytes int64) ([]byte, error) {
 	return data, nil
 }
 
-func (p *Plugin) run(rule cpmtypes.CustomRule) (exitStatus cpmtypes.Status, output string) {
+// run runs the plugin or the built-in check of the rule, and builds its result.
+func (p *Plugin) run(rule *cpmtypes.CustomRule) cpmtypes.Result {
 	var ctx context.Context
 	var cancel context.CancelFunc
 
@@ -234,21 +258,171 @@ func (p *Plugin) run(rule cpmtypes.CustomRule) (exitStatus cpmtypes.Status, outp
 	}
 	defer cancel()
 
+	// The nagios state is the exit code of the plugin, or of the command of a
+	// command check, the only check the protocol supports.
+	if rule.IsNagios() {
+		pluginRule := *rule
+		if rule.Check != nil {
+			pluginRule = commandRule(pluginRule)
+		}
+		exitCode, output, timeoutState := p.execPlugin(ctx, pluginRule)
+		result := p.parseNagiosResult(rule, nagiosState(exitCode), output)
+		if timeoutState != "" {
+			// The timeout note is added once the output is parsed, so that
+			// a '|' in the output it quotes is not taken for perfdata.
+			result.Message = p.truncateOutput(timeoutOutput(pluginRule, timeoutState, result.Message))
+		}
+		return result
+	}
+
+	var exitStatus cpmtypes.Status
+	var output string
+	if rule.Check != nil {
+		exitStatus, output = p.runCheck(ctx, *rule)
+	} else {
+		var exitCode int
+		exitCode, output = p.runPlugin(ctx, *rule)
+		exitStatus = pluginStatus(exitCode)
+	}
+
+	// The JSON document is parsed as a whole, and its message is truncated
+	// afterwards.
+	if rule.OutputFormat == cpmtypes.JSONOutputFormat {
+		return p.parseJSONResult(rule, exitStatus, output)
+	}
+	return cpmtypes.Result{
+		Rule:       rule,
+		ExitStatus: exitStatus,
+		Message:    p.truncateOutput(output),
+	}
+}
+
+// truncateOutput cuts the output at position max_output_length if it is longer
+// than max_output_length bytes.
+func (p *Plugin) truncateOutput(output string) string {
+	if len(output) > *p.config.PluginGlobalConfig.MaxOutputLength {
+		return output[:*p.config.PluginGlobalConfig.MaxOutputLength]
+	}
+	return output
+}
+
+// parseJSONResult builds the result of a rule from the JSON document printed by
+// the plugin. The result is Unknown if the output is not a valid document, e.g.
+// when the plugin timed out.
+func (p *Plugin) parseJSONResult(rule *cpmtypes.CustomRule, exitStatus cpmtypes.Status, output string) cpmtypes.Result {
+	out, err := cpmtypes.ParseOutput(output)
+	if err != nil {
+		klog.Errorf("Error parsing JSON output of rule %+v: exit status - %d, error - %v", rule, exitStatus, err)
+		return cpmtypes.Result{
+			Rule:       rule,
+			ExitStatus: cpmtypes.Unknown,
+			Message:    p.truncateOutput(output),
+		}
+	}
+
+	result := cpmtypes.Result{
+		Rule:       rule,
+		ExitStatus: *out.Status,
+		Message:    p.truncateOutput(out.Message),
+		Reason:     out.Reason,
+		Metrics:    out.Metrics,
+	}
+	for _, condition := range out.Conditions {
+		result.Conditions = append(result.Conditions, cpmtypes.ConditionResult{
+			Type:    condition.Type,
+			Status:  *condition.Status,
+			Reason:  condition.Reason,
+			Message: p.truncateOutput(condition.Message),
+		})
+	}
+	return result
+}
+
+// parseNagiosResult builds the result of a rule from the state and the output
+// of a nagios plugin. WARNING and CRITICAL are NonOK with the warn and critical
+// severity, and the performance data are the metrics of the result.
+func (p *Plugin) parseNagiosResult(rule *cpmtypes.CustomRule, state cpmtypes.NagiosState, output string) cpmtypes.Result {
+	message, perfdata, err := cpmtypes.ParseNagiosOutput(output)
+	if err != nil {
+		klog.Warningf("Error parsing performance data of rule %+v: %v", rule, err)
+	}
+	result := cpmtypes.Result{
+		Rule:    rule,
+		Message: p.truncateOutput(message),
+		Metrics: perfdata,
+	}
+	switch state {
+	case cpmtypes.NagiosOK:
+		result.ExitStatus = cpmtypes.OK
+	case cpmtypes.NagiosWarning:
+		result.ExitStatus = cpmtypes.NonOK
+		result.Severity = types.Warn
+	case cpmtypes.NagiosCritical:
+		result.ExitStatus = cpmtypes.NonOK
+		result.Severity = types.Critical
+	default:
+		result.ExitStatus = cpmtypes.Unknown
+	}
+	return result
+}
+
+// pluginStatus maps the exit code of a plugin to its status.
+func pluginStatus(exitCode int) cpmtypes.Status {
+	switch exitCode {
+	case 0:
+		return cpmtypes.OK
+	case 1:
+		return cpmtypes.NonOK
+	default:
+		return cpmtypes.Unknown
+	}
+}
+
+// nagiosState maps the exit code of a nagios plugin to its state.
+func nagiosState(exitCode int) cpmtypes.NagiosState {
+	state := cpmtypes.NagiosState(exitCode)
+	if state < cpmtypes.NagiosOK || state > cpmtypes.NagiosUnknown {
+		return cpmtypes.NagiosUnknown
+	}
+	return state
+}
+
+// runPlugin executes the custom plugin of the rule and returns its exit code
+// and output. The exit code is failedExitCode if the plugin could not be run.
+// The output of a plugin killed on timeout is wrapped in a timeout note.
+func (p *Plugin) runPlugin(ctx context.Context, rule cpmtypes.CustomRule) (exitCode int, output string) {
+	exitCode, output, timeoutState := p.execPlugin(ctx, rule)
+	if timeoutState != "" {
+		output = timeoutOutput(rule, timeoutState, output)
+	}
+	return exitCode, output
+}
+
+// timeoutOutput wraps the output of the plugin of the rule killed on timeout
+// in a timeout note.
+func timeoutOutput(rule cpmtypes.CustomRule, processState, output string) string {
+	return fmt.Sprintf("Timeout when running plugin %q: state - %s. output - %q", rule.Path, processState, output)
+}
+
+// execPlugin executes the custom plugin of the rule and returns its exit code
+// and raw output, and the process state of the plugin if it was killed on
+// timeout.
+func (p *Plugin) execPlugin(ctx context.Context, rule cpmtypes.CustomRule) (exitCode int, output string, timeoutState string) {
 	cmd := util.Exec(ctx, rule.Path, rule.Args...)
 
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
 		klog.Errorf("Error creating stdout pipe for plugin %q: error - %v", rule.Path, err)
-		return cpmtypes.Unknown, "Error creating stdout pipe for plugin. Please check the error log"
+		return failedExitCode, "Error creating stdout pipe for plugin. Please check the error log", ""
 	}
 	stderrPipe, err := cmd.StderrPipe()
 	if err != nil {
 		klog.Errorf("Error creating stderr pipe for plugin %q: error - %v", rule.Path, err)
-		return cpmtypes.Unknown, "Error creating stderr pipe for plugin. Please check the error log"
+		return failedExitCode, "Error creating stderr pipe for plugin. Please check the error log", ""
 	}
-	if err := cmd.Start(); err != nil {
+	if err := p.startPlugin(cmd, &rule); err != nil {
 		klog.Errorf("Error in starting plugin %q: error - %v", rule.Path, err)
-		return cpmtypes.Unknown, "Error in starting plugin. Please check the error log"
+		return failedExitCode, "Error in starting plugin. Please check the error log", ""
 	}
 
 	waitChan := make(chan struct{})
@@ -305,18 +479,18 @@ func (p *Plugin) run(rule cpmtypes.CustomRule) (exitStatus cpmtypes.Status, outp
 
 	if stdoutErr != nil {
 		klog.Errorf("Error reading stdout for plugin %q: error - %v", rule.Path, err)
-		return cpmtypes.Unknown, "Error reading stdout for plugin. Please check the error log"
+		return failedExitCode, "Error reading stdout for plugin. Please check the error log", ""
 	}
 
 	if stderrErr != nil {
 		klog.Errorf("Error reading stderr for plugin %q: error - %v", rule.Path, err)
-		return cpmtypes.Unknown, "Error reading stderr for plugin. Please check the error log"
+		return failedExitCode, "Error reading stderr for plugin. Please check the error log", ""
 	}
 
 	if err := cmd.Wait(); err != nil {
 		if _, ok := err.(*exec.ExitError); !ok {
 			klog.Errorf("Error in waiting for plugin %q: error - %v. output - %q", rule.Path, err, string(stdout))
-			return cpmtypes.Unknown, "Error in waiting for plugin. Please check the error log"
+			return failedExitCode, "Error in waiting for plugin. Please check the error log", ""
 		}
 	}
 
@@ -329,26 +503,22 @@ func (p *Plugin) run(rule cpmtypes.CustomRule) (exitStatus cpmtypes.Status, outp
 	m.Unlock()
 
 	if cmdKilled {
-		output = fmt.Sprintf("Timeout when running plugin %q: state - %s. output - %q", rule.Path, cmd.ProcessState.String(), output)
+		if err := monitormetrics.GlobalMonitorMetricsManager.IncrementPluginTimeouts(p.config.Source, rule.MetricName(), 1); err != nil {
+			klog.Errorf("Failed to update plugin timeout metrics for rule %+v: %v", rule, err)
+		}
+		timeoutState = cmd.ProcessState.String()
 	}
 
-	// cut at position max_output_length if stdout is longer than max_output_length bytes
-	if len(output) > *p.config.PluginGlobalConfig.MaxOutputLength {
-		output = output[:*p.config.PluginGlobalConfig.MaxOutputLength]
+	exitCode = cmd.ProcessState.Sys().(syscall.WaitStatus).ExitStatus()
+	if err := monitormetrics.GlobalMonitorMetricsManager.IncrementPluginExitCode(p.config.Source, rule.MetricName(), exitCode); err != nil {
+		klog.Errorf("Failed to update plugin exit code metrics for rule %+v: %v", rule, err)
 	}
-
-	exitCode := cmd.ProcessState.Sys().(syscall.WaitStatus).ExitStatus()
-	switch exitCode {
-	case 0:
-		logPluginStderr(rule, string(stderr), 3)
-		return cpmtypes.OK, output
-	case 1:
-		logPluginStderr(rule, string(stderr), 0)
-		return cpmtypes.NonOK, output
-	default:
-		logPluginStderr(rule, string(stderr), 0)
-		return cpmtypes.Unknown, output
+	level := klog.Level(0)
+	if exitCode == 0 {
+		level = 3
 	}
+	logPluginStderr(rule, string(stderr), level)
+	return exitCode, output, timeoutState
 }
 
 // Stop the plugin.
@@ -99,7 +99,7 @@ func (r *executionRecorder) block(rule string, count int, release <-chan struct{
 	r.blockers[invocationKey{rule: rule, count: count}] = release
 }
 
-func (r *executionRecorder) run(rule cpmtypes.CustomRule) (cpmtypes.Status, string) {
+func (r *executionRecorder) run(rule *cpmtypes.CustomRule) cpmtypes.Result {
 	r.mu.Lock()
 	r.counts[rule.Path]++
 	count := r.counts[rule.Path]
@@ -127,7 +127,7 @@ func (r *executionRecorder) run(rule cpmtypes.CustomRule) (cpmtypes.Status, stri
 	r.active[rule.Path]--
 	r.activeTotal--
 	r.mu.Unlock()
-	return cpmtypes.OK, rule.Path
+	return cpmtypes.Result{Rule: rule, ExitStatus: cpmtypes.OK, Message: rule.Path}
 }
 
 type executionSnapshot struct {
@@ -17,11 +17,15 @@ limitations under the License.
 package plugin
 
 import (
+	"reflect"
 	"runtime"
 	"testing"
 	"time"
 
 	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
+	"k8s.io/node-problem-detector/pkg/types"
+	"k8s.io/node-problem-detector/pkg/util/metrics"
 )
 
 func TestNewPluginRun(t *testing.T) {
@@ -37,6 +41,7 @@ func TestNewPluginRun(t *testing.T) {
 	utMetas := map[string]struct {
 		Rule       cpmtypes.CustomRule
 		ExitStatus cpmtypes.Status
+		Severity   types.Severity
 		Output     string
 	}{
 		"ok": {
@@ -88,6 +93,72 @@ func TestNewPluginRun(t *testing.T) {
 			ExitStatus: cpmtypes.Unknown,
 			Output:     "NON-DEFINED-EXIT-STATUS",
 		},
+		"nagios warning": {
+			Rule: cpmtypes.CustomRule{
+				Path:     "./test-data/non-ok." + ext,
+				Timeout:  &ruleTimeout,
+				Protocol: cpmtypes.NagiosProtocol,
+			},
+			ExitStatus: cpmtypes.NonOK,
+			Severity:   types.Warn,
+			Output:     "NonOK",
+		},
+		"nagios critical": {
+			Rule: cpmtypes.CustomRule{
+				Path:     "./test-data/nagios-critical." + ext,
+				Timeout:  &ruleTimeout,
+				Protocol: cpmtypes.NagiosProtocol,
+			},
+			ExitStatus: cpmtypes.NonOK,
+			Severity:   types.Critical,
+			Output:     "DISK CRITICAL - free space: / 12 MB, /boot 30 MB",
+		},
+		"nagios unknown": {
+			Rule: cpmtypes.CustomRule{
+				Path:     "./test-data/unknown." + ext,
+				Timeout:  &ruleTimeout,
+				Protocol: cpmtypes.NagiosProtocol,
+			},
+			ExitStatus: cpmtypes.Unknown,
+			Output:     "UNKNOWN",
+		},
+		"nagios non defined exit status": {
+			Rule: cpmtypes.CustomRule{
+				Path:     "./test-data/non-defined-exit-status." + ext,
+				Timeout:  &ruleTimeout,
+				Protocol: cpmtypes.NagiosProtocol,
+			},
+			ExitStatus: cpmtypes.Unknown,
+			Output:     "NON-DEFINED-EXIT-STATUS",
+		},
+		"nagios non executable": {
+			Rule: cpmtypes.CustomRule{
+				Path:     "./test-data/non-executable.sh",
+				Timeout:  &ruleTimeout,
+				Protocol: cpmtypes.NagiosProtocol,
+			},
+			ExitStatus: cpmtypes.Unknown,
+			Output:     "Error in starting plugin. Please check the error log",
+		},
+		"nagios command check critical": {
+			Rule: cpmtypes.CustomRule{
+				Check:    &cpmtypes.CheckConfig{Kind: cpmtypes.CommandCheck, Command: []string{"./test-data/nagios-critical." + ext}},
+				Timeout:  &ruleTimeout,
+				Protocol: cpmtypes.NagiosProtocol,
+			},
+			ExitStatus: cpmtypes.NonOK,
+			Severity:   types.Critical,
+			Output:     "DISK CRITICAL - free space: / 12 MB, /boot 30 MB",
+		},
+		"nagios command check non executable": {
+			Rule: cpmtypes.CustomRule{
+				Check:    &cpmtypes.CheckConfig{Kind: cpmtypes.CommandCheck, Command: []string{"./test-data/non-executable.sh"}},
+				Timeout:  &ruleTimeout,
+				Protocol: cpmtypes.NagiosProtocol,
+			},
+			ExitStatus: cpmtypes.Unknown,
+			Output:     "Error in starting plugin. Please check the error log",
+		},
 		"sleep 3 second with ok exit status": {
 			Rule: cpmtypes.CustomRule{
 				Path:    "./test-data/sleep-3-second-with-ok-exit-status." + ext,
@@ -107,12 +178,13 @@ func TestNewPluginRun(t *testing.T) {
 				t.Errorf("Failed to apply configuration: %v", err)
 			}
 			p := Plugin{config: conf}
-			gotExitStatus, gotOutput := p.run(utMeta.Rule)
+			result := p.run(&utMeta.Rule)
+			gotExitStatus, gotOutput := result.ExitStatus, result.Message
 			// cut at position max_output_length if expected output is longer than max_output_length bytes
 			if len(utMeta.Output) > *p.config.PluginGlobalConfig.MaxOutputLength {
 				utMeta.Output = utMeta.Output[:*p.config.PluginGlobalConfig.MaxOutputLength]
 			}
-			if gotExitStatus != utMeta.ExitStatus || gotOutput != utMeta.Output {
+			if gotExitStatus != utMeta.ExitStatus || result.Severity != utMeta.Severity || gotOutput != utMeta.Output {
 				t.Errorf("Error in run plugin and get exit status and output for %q. "+
 					"Got exit status: %v, Expected exit status: %v. "+
 					"Got output: %q, Expected output: %q",
@@ -121,3 +193,210 @@ func TestNewPluginRun(t *testing.T) {
 		})
 	}
 }
+
+func TestParseJSONResult(t *testing.T) {
+	maxOutputLength := 10
+	p := Plugin{config: cpmtypes.CustomPluginConfig{}}
+	p.config.PluginGlobalConfig.MaxOutputLength = &maxOutputLength
+	rule := &cpmtypes.CustomRule{OutputFormat: cpmtypes.JSONOutputFormat}
+
+	utMetas := map[string]struct {
+		ExitStatus cpmtypes.Status
+		Output     string
+		Expected   cpmtypes.Result
+	}{
+		"document overrides exit status": {
+			ExitStatus: cpmtypes.OK,
+			Output:     `{"status": 1, "reason": "Slow", "message": "disk is slow", "metrics": {"latency": 2}}`,
+			Expected: cpmtypes.Result{
+				Rule:       rule,
+				ExitStatus: cpmtypes.NonOK,
+				Reason:     "Slow",
+				Message:    "disk is sl",
+				Metrics:    map[string]float64{"latency": 2},
+			},
+		},
+		"sub-conditions": {
+			ExitStatus: cpmtypes.OK,
+			Output:     `{"status": 0, "conditions": [{"type": "A", "status": 2, "message": "cannot tell why"}]}`,
+			Expected: cpmtypes.Result{
+				Rule:       rule,
+				ExitStatus: cpmtypes.OK,
+				Conditions: []cpmtypes.ConditionResult{{Type: "A", Status: cpmtypes.Unknown, Message: "cannot tel"}},
+			},
+		},
+		"invalid document": {
+			ExitStatus: cpmtypes.OK,
+			Output:     "Timeout when running plugin",
+			Expected: cpmtypes.Result{
+				Rule:       rule,
+				ExitStatus: cpmtypes.Unknown,
+				Message:    "Timeout wh",
+			},
+		},
+	}
+
+	t.Run("output is parsed before it is truncated", func(t *testing.T) {
+		ext := "sh"
+		if runtime.GOOS == "windows" {
+			ext = "cmd"
+		}
+		conf := cpmtypes.CustomPluginConfig{}
+		if err := (&conf).ApplyConfiguration(); err != nil {
+			t.Errorf("Failed to apply configuration: %v", err)
+		}
+		p := Plugin{config: conf}
+		rule := &cpmtypes.CustomRule{Path: "./test-data/json-output." + ext, OutputFormat: cpmtypes.JSONOutputFormat}
+		result := p.run(rule)
+		if result.ExitStatus != cpmtypes.NonOK || result.Reason != "JSONProblem" || result.Metrics["value"] != 1 {
+			t.Errorf("Error in parsing JSON output, got result: %+v", result)
+		}
+	})
+
+	for desc, utMeta := range utMetas {
+		result := p.parseJSONResult(rule, utMeta.ExitStatus, utMeta.Output)
+		if !reflect.DeepEqual(result, utMeta.Expected) {
+			t.Errorf("Error in parsing JSON result for %q.\nWanted: %+v.\nGot: %+v", desc, utMeta.Expected, result)
+		}
+	}
+}
+
+func TestParseNagiosResult(t *testing.T) {
+	maxOutputLength := 10
+	p := Plugin{config: cpmtypes.CustomPluginConfig{}}
+	p.config.PluginGlobalConfig.MaxOutputLength = &maxOutputLength
+	rule := &cpmtypes.CustomRule{Protocol: cpmtypes.NagiosProtocol}
+
+	utMetas := map[string]struct {
+		State    cpmtypes.NagiosState
+		Output   string
+		Expected cpmtypes.Result
+	}{
+		"ok": {
+			State:  cpmtypes.NagiosOK,
+			Output: "PING OK | rta=0.5ms;100;200;0",
+			Expected: cpmtypes.Result{
+				Rule:       rule,
+				ExitStatus: cpmtypes.OK,
+				Message:    "PING OK",
+				Metrics:    map[string]float64{"rta": 0.5},
+			},
+		},
+		"warning": {
+			State:  cpmtypes.NagiosWarning,
+			Output: "PING WARNING | rta=150ms;100;200;0",
+			Expected: cpmtypes.Result{
+				Rule:       rule,
+				ExitStatus: cpmtypes.NonOK,
+				Severity:   types.Warn,
+				Message:    "PING WARNI",
+				Metrics:    map[string]float64{"rta": 150},
+			},
+		},
+		"critical": {
+			State:  cpmtypes.NagiosCritical,
+			Output: "PING CRITICAL",
+			Expected: cpmtypes.Result{
+				Rule:       rule,
+				ExitStatus: cpmtypes.NonOK,
+				Severity:   types.Critical,
+				Message:    "PING CRITI",
+			},
+		},
+		"unknown": {
+			State:  cpmtypes.NagiosUnknown,
+			Output: "Usage: check_ping -H <host>",
+			Expected: cpmtypes.Result{
+				Rule:       rule,
+				ExitStatus: cpmtypes.Unknown,
+				Message:    "Usage: che",
+			},
+		},
+	}
+
+	t.Run("output is parsed before it is truncated", func(t *testing.T) {
+		ext := "sh"
+		if runtime.GOOS == "windows" {
+			ext = "cmd"
+		}
+		conf := cpmtypes.CustomPluginConfig{}
+		if err := (&conf).ApplyConfiguration(); err != nil {
+			t.Errorf("Failed to apply configuration: %v", err)
+		}
+		p := Plugin{config: conf}
+		rule := &cpmtypes.CustomRule{Path: "./test-data/nagios-critical." + ext, Protocol: cpmtypes.NagiosProtocol}
+		result := p.run(rule)
+		if result.ExitStatus != cpmtypes.NonOK || result.Severity != types.Critical || result.Metrics["/boot"] != 68 {
+			t.Errorf("Error in parsing nagios output, got result: %+v", result)
+		}
+	})
+
+	for desc, utMeta := range utMetas {
+		result := p.parseNagiosResult(rule, utMeta.State, utMeta.Output)
+		if !reflect.DeepEqual(result, utMeta.Expected) {
+			t.Errorf("Error in parsing nagios result for %q.\nWanted: %+v.\nGot: %+v", desc, utMeta.Expected, result)
+		}
+	}
+}
+
+func TestRunNagiosPluginTimeout(t *testing.T) {
+	ext := "sh"
+	if runtime.GOOS == "windows" {
+		ext = "cmd"
+	}
+	conf := cpmtypes.CustomPluginConfig{}
+	if err := (&conf).ApplyConfiguration(); err != nil {
+		t.Errorf("Failed to apply configuration: %v", err)
+	}
+	maxOutputLength := 200
+	conf.PluginGlobalConfig.MaxOutputLength = &maxOutputLength
+	p := Plugin{config: conf}
+	ruleTimeout := 1 * time.Second
+	rule := &cpmtypes.CustomRule{Path: "./test-data/nagios-timeout." + ext, Protocol: cpmtypes.NagiosProtocol, Timeout: &ruleTimeout}
+
+	// The output is parsed before it is quoted in the timeout note.
+	expected := cpmtypes.Result{
+		Rule:       rule,
+		ExitStatus: cpmtypes.Unknown,
+		Message:    `Timeout when running plugin "./test-data/nagios-timeout.` + ext + `": state - signal: killed. output - "DISK WARNING - free space: / 120 MB"`,
+		Metrics:    map[string]float64{"/": 5848},
+	}
+	if result := p.run(rule); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Error in running nagios plugin on timeout.\nWanted: %+v.\nGot: %+v", expected, result)
+	}
+}
+
+func TestRunPluginRecordsMonitorMetrics(t *testing.T) {
+	original := monitormetrics.GlobalMonitorMetricsManager
+	t.Cleanup(func() { monitormetrics.GlobalMonitorMetricsManager = original })
+	mmm, stub := monitormetrics.NewMonitorMetricsManagerStub()
+	monitormetrics.GlobalMonitorMetricsManager = mmm
+
+	ext := "sh"
+	if runtime.GOOS == "windows" {
+		ext = "cmd"
+	}
+	ruleTimeout := 100 * time.Millisecond
+	conf := cpmtypes.CustomPluginConfig{Source: "test-source"}
+	if err := (&conf).ApplyConfiguration(); err != nil {
+		t.Errorf("Failed to apply configuration: %v", err)
+	}
+	p := Plugin{config: conf}
+	p.run(&cpmtypes.CustomRule{Reason: "NonOK", Path: "./test-data/non-ok." + ext, Timeout: &ruleTimeout})
+	p.run(&cpmtypes.CustomRule{Reason: "Sleep", Path: "./test-data/sleep-3-second-with-ok-exit-status." + ext, Timeout: &ruleTimeout})
+
+	gotTimeouts := stub.PluginTimeouts.ListMetrics()
+	wantTimeouts := []metrics.Int64MetricRepresentation{
+		{Name: string(metrics.PluginTimeoutsID), Labels: map[string]string{"source": "test-source", "rule": "Sleep"}, Value: 1},
+	}
+	if !reflect.DeepEqual(gotTimeouts, wantTimeouts) {
+		t.Errorf("Wanted plugin timeout metrics %+v, got %+v", wantTimeouts, gotTimeouts)
+	}
+	gotExitCodes := stub.PluginExitCodes.ListMetrics()
+	wantExitCode := metrics.Int64MetricRepresentation{
+		Name: string(metrics.PluginExitCodesID), Labels: map[string]string{"source": "test-source", "rule": "NonOK", "exit_code": "1"}, Value: 1,
+	}
+	if len(gotExitCodes) == 0 || !reflect.DeepEqual(gotExitCodes[0], wantExitCode) {
+		t.Errorf("Wanted plugin exit code metric %+v, got %+v", wantExitCode, gotExitCodes)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+)
+
+// startPlugin starts the plugin command of the rule with the exec
+// configuration of the rule, merged with the global one.
+func (p *Plugin) startPlugin(cmd *exec.Cmd, rule *cpmtypes.CustomRule) error {
+	config := cpmtypes.MergeExecConfig(p.config.PluginGlobalConfig.Exec, rule.Exec)
+	if config == nil {
+		return cmd.Start()
+	}
+	cmd.Env = pluginEnv(os.Environ(), config)
+	if config.WorkingDir != "" {
+		// A relative plugin path stays relative to the working directory of NPD.
+		if !filepath.IsAbs(cmd.Path) {
+			if path, err := filepath.Abs(cmd.Path); err == nil {
+				cmd.Path = path
+			}
+		}
+		cmd.Dir = config.WorkingDir
+	}
+	return startSandboxed(cmd, config)
+}
+
+// pluginEnv returns the environment of a plugin: the variables of environ on
+// the allow-list, and the extra variables of the exec configuration. It
+// returns nil, so that the plugin inherits the environment, if neither is set.
+func pluginEnv(environ []string, config *cpmtypes.ExecConfig) []string {
+	if config.EnvAllowlist == nil && len(config.Env) == 0 {
+		return nil
+	}
+	env := []string{}
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := config.Env[name]; ok {
+			continue
+		}
+		if config.EnvAllowlist == nil || slices.Contains(config.EnvAllowlist, name) {
+			env = append(env, kv)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.Env)) {
+		env = append(env, name+"="+config.Env[name])
+	}
+	return env
+}
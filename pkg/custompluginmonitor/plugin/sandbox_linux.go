@@ -0,0 +1,221 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+
+	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+)
+
+// cpuMaxPeriod is the period of the CPU limit of a plugin cgroup, in
+// microseconds.
+const cpuMaxPeriod = 100000
+
+// rlimitShimName is the argv[0] NPD is executed with to run the rlimit shim.
+const rlimitShimName = "node-problem-detector-rlimit-shim"
+
+// rlimitShimExitCode is the exit code of the rlimit shim when it fails to
+// execute the plugin. Like any other exit code than 0 and 1, it is reported as
+// unknown.
+const rlimitShimExitCode = 126
+
+// rlimitResources are the resources of the rlimit names.
+var rlimitResources = map[string]int{
+	"as":      unix.RLIMIT_AS,
+	"core":    unix.RLIMIT_CORE,
+	"cpu":     unix.RLIMIT_CPU,
+	"data":    unix.RLIMIT_DATA,
+	"fsize":   unix.RLIMIT_FSIZE,
+	"memlock": unix.RLIMIT_MEMLOCK,
+	"nofile":  unix.RLIMIT_NOFILE,
+	"nproc":   unix.RLIMIT_NPROC,
+	"stack":   unix.RLIMIT_STACK,
+}
+
+// init runs the rlimit shim instead of the binary when it is executed as the
+// shim, see useRlimitShim. Every binary which starts plugins links this
+// package.
+func init() {
+	if len(os.Args) > 0 && os.Args[0] == rlimitShimName {
+		runRlimitShim(os.Args[1:])
+	}
+}
+
+// startSandboxed starts the command as the user and group, in the cgroup,
+// with the no_new_privs flag and with the rlimits of the exec configuration.
+// The rlimits are set by the rlimit shim, before the plugin is executed.
+func startSandboxed(cmd *exec.Cmd, config *cpmtypes.ExecConfig) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	if config.UID != nil || config.GID != nil {
+		uid, gid := uint32(os.Getuid()), uint32(os.Getgid())
+		if config.UID != nil {
+			uid = *config.UID
+		}
+		if config.GID != nil {
+			gid = *config.GID
+		}
+		// Without groups, the supplementary groups of NPD are dropped.
+		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uid, Gid: gid}
+	}
+	if config.Cgroup != nil {
+		fd, err := openCgroup(config.Cgroup)
+		if err != nil {
+			return failStart(cmd, fmt.Errorf("error in setting up cgroup %q: %v", config.Cgroup.Path, err))
+		}
+		defer func() {
+			if err := unix.Close(fd); err != nil {
+				klog.Errorf("Error in closing cgroup %q: %v", config.Cgroup.Path, err)
+			}
+		}()
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = fd
+	}
+
+	if len(config.Rlimits) != 0 && cmd.Err == nil {
+		useRlimitShim(cmd, config.Rlimits)
+	}
+
+	if config.NoNewPrivs != nil && *config.NoNewPrivs {
+		return startWithNoNewPrivs(cmd)
+	}
+	return cmd.Start()
+}
+
+// startWithNoNewPrivs starts the command from a thread with the no_new_privs
+// flag set, which the command inherits. The flag cannot be unset, so the
+// thread is not unlocked, and exits with its goroutine.
+func startWithNoNewPrivs(cmd *exec.Cmd) error {
+	errChan := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+			errChan <- failStart(cmd, fmt.Errorf("error in setting no_new_privs: %v", err))
+			return
+		}
+		errChan <- cmd.Start()
+	}()
+	return <-errChan
+}
+
+// failStart fails to start the command with the error. Start releases the
+// pipes of the command.
+func failStart(cmd *exec.Cmd, err error) error {
+	cmd.Err = err
+	return cmd.Start()
+}
+
+// useRlimitShim makes the command start NPD itself as the rlimit shim, which
+// sets the rlimits before it executes the plugin, so that the plugin never
+// runs without them. The binary of NPD must be executable by the plugin user.
+func useRlimitShim(cmd *exec.Cmd, rlimits map[string]uint64) {
+	args := []string{rlimitShimName}
+	for _, name := range slices.Sorted(maps.Keys(rlimits)) {
+		args = append(args, name+"="+strconv.FormatUint(rlimits[name], 10))
+	}
+	args = append(args, "--", cmd.Path)
+	cmd.Args = append(args, cmd.Args...)
+	cmd.Path = "/proc/self/exe"
+}
+
+// runRlimitShim sets the rlimits of the arguments and executes the plugin. It
+// only returns if it fails, by exiting with rlimitShimExitCode.
+func runRlimitShim(args []string) {
+	err := execWithRlimits(args)
+	fmt.Fprintf(os.Stderr, "Error in starting plugin with rlimits: %v\n", err)
+	os.Exit(rlimitShimExitCode)
+}
+
+// execWithRlimits sets the rlimits of the "name=value" arguments before "--",
+// and executes the path after it with the remaining arguments.
+func execWithRlimits(args []string) error {
+	sep := slices.Index(args, "--")
+	if sep < 0 || len(args) < sep+3 {
+		return fmt.Errorf("invalid arguments %q", args)
+	}
+	for _, arg := range args[:sep] {
+		name, value, _ := strings.Cut(arg, "=")
+		resource, ok := rlimitResources[name]
+		if !ok {
+			return fmt.Errorf("unknown rlimit %q", name)
+		}
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rlimit %s: %v", name, err)
+		}
+		if err := unix.Setrlimit(resource, &unix.Rlimit{Cur: limit, Max: limit}); err != nil {
+			return fmt.Errorf("error in setting rlimit %s: %v", name, err)
+		}
+	}
+	return unix.Exec(args[sep+1], args[sep+2:], os.Environ())
+}
+
+// openCgroup creates the cgroup if it does not exist, sets its limits and
+// returns a file descriptor of its directory.
+func openCgroup(config *cpmtypes.CgroupConfig) (int, error) {
+	if err := os.MkdirAll(config.Path, 0755); err != nil {
+		return -1, err
+	}
+	var controllers []string
+	if config.MemoryMax > 0 {
+		controllers = append(controllers, "+memory")
+	}
+	if config.CPUMax > 0 {
+		controllers = append(controllers, "+cpu")
+	}
+	if len(controllers) != 0 {
+		// The limits of a cgroup are only available when its parent enables
+		// their controllers.
+		if err := writeCgroupFile(filepath.Dir(config.Path), "cgroup.subtree_control", strings.Join(controllers, " ")); err != nil {
+			return -1, err
+		}
+	}
+	if config.MemoryMax > 0 {
+		if err := writeCgroupFile(config.Path, "memory.max", strconv.FormatInt(config.MemoryMax, 10)); err != nil {
+			return -1, err
+		}
+	}
+	if config.CPUMax > 0 {
+		quota := config.CPUMax * cpuMaxPeriod / 1000
+		if err := writeCgroupFile(config.Path, "cpu.max", fmt.Sprintf("%d %d", quota, cpuMaxPeriod)); err != nil {
+			return -1, err
+		}
+	}
+	return unix.Open(config.Path, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+}
+
+// writeCgroupFile writes the value to the interface file of the cgroup.
+func writeCgroupFile(dir, file, value string) error {
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(value), 0); err != nil {
+		return fmt.Errorf("error in writing %q to %s: %v", value, file, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+)
+
+func TestRunPluginWithExecConfig(t *testing.T) {
+	dir, err := filepath.Abs("test-data")
+	if err != nil {
+		t.Fatalf("Failed to get the test data directory: %v", err)
+	}
+	noNewPrivs := true
+	conf := cpmtypes.CustomPluginConfig{Source: "test-source"}
+	if err := (&conf).ApplyConfiguration(); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+	conf.PluginGlobalConfig.Exec = &cpmtypes.ExecConfig{
+		EnvAllowlist: []string{"PATH"},
+		Env:          map[string]string{"NPD_TEST_VALUE": "global"},
+	}
+	p := Plugin{config: conf}
+	rule := cpmtypes.CustomRule{
+		Path: "./test-data/print-env.sh",
+		Exec: &cpmtypes.ExecConfig{
+			Env:        map[string]string{"NPD_TEST_VALUE": "rule"},
+			WorkingDir: dir,
+			NoNewPrivs: &noNewPrivs,
+		},
+	}
+
+	result := p.run(&rule)
+	status, output := result.ExitStatus, result.Message
+	want := fmt.Sprintf("rule unset %s 1 %d", dir, os.Getuid())
+	if status != cpmtypes.OK || output != want {
+		t.Errorf("Wanted OK with output %q, got %v with %q", want, status, output)
+	}
+}
+
+func TestRunPluginAsUser(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("Running a plugin as another user needs root")
+	}
+	conf := cpmtypes.CustomPluginConfig{Source: "test-source"}
+	if err := (&conf).ApplyConfiguration(); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+	nobody := uint32(65534)
+	p := Plugin{config: conf}
+	result := p.run(&cpmtypes.CustomRule{
+		Path: "./test-data/print-env.sh",
+		Exec: &cpmtypes.ExecConfig{UID: &nobody, GID: &nobody},
+	})
+	if result.ExitStatus != cpmtypes.OK || !strings.HasSuffix(result.Message, " 65534") {
+		t.Errorf("Wanted OK with the uid of nobody, got %v with %q", result.ExitStatus, result.Message)
+	}
+}
+
+func TestRunPluginWithRlimits(t *testing.T) {
+	conf := cpmtypes.CustomPluginConfig{Source: "test-source"}
+	if err := (&conf).ApplyConfiguration(); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+	p := Plugin{config: conf}
+	result := p.run(&cpmtypes.CustomRule{
+		Path: "./test-data/print-rlimits.sh",
+		Args: []string{"a", "--", "b"},
+		Exec: &cpmtypes.ExecConfig{Rlimits: map[string]uint64{"nofile": 64, "core": 0}},
+	})
+	if result.ExitStatus != cpmtypes.OK || result.Message != "64 64 0 a -- b" {
+		t.Errorf("Wanted OK with the rlimits and the arguments, got %v with %q", result.ExitStatus, result.Message)
+	}
+}
+
+func TestExecWithRlimitsErrors(t *testing.T) {
+	for desc, args := range map[string][]string{
+		"no separator":   {"nofile=64", "/bin/true"},
+		"no path":        {"nofile=64", "--"},
+		"unknown rlimit": {"files=64", "--", "/bin/true", "true"},
+		"invalid value":  {"nofile=many", "--", "/bin/true", "true"},
+	} {
+		if err := execWithRlimits(args); err == nil {
+			t.Errorf("%s: expected an error", desc)
+		}
+	}
+}
+
+func TestOpenCgroup(t *testing.T) {
+	// A regular directory stands in for the cgroup v2 hierarchy.
+	root := t.TempDir()
+	path := filepath.Join(root, "plugins")
+	fd, err := openCgroup(&cpmtypes.CgroupConfig{Path: path, MemoryMax: 256 * 1024 * 1024, CPUMax: 500})
+	if err != nil {
+		t.Fatalf("Error in opening cgroup: %v", err)
+	}
+	if err := unix.Close(fd); err != nil {
+		t.Errorf("Error in closing cgroup: %v", err)
+	}
+	for file, want := range map[string]string{
+		filepath.Join(root, "cgroup.subtree_control"): "+memory +cpu",
+		filepath.Join(path, "memory.max"):             "268435456",
+		filepath.Join(path, "cpu.max"):                "50000 100000",
+	} {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Errorf("Failed to read %s: %v", file, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("Wanted %s to be %q, got %q", file, want, got)
+		}
+	}
+}
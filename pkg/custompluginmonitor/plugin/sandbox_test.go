@@ -0,0 +1,56 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"reflect"
+	"testing"
+
+	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+)
+
+func TestPluginEnv(t *testing.T) {
+	environ := []string{"PATH=/usr/bin", "HOME=/root", "LANG=en_US.UTF-8"}
+	for desc, test := range map[string]struct {
+		config *cpmtypes.ExecConfig
+		want   []string
+	}{
+		"inherited": {
+			config: &cpmtypes.ExecConfig{WorkingDir: "/"},
+		},
+		"allow-list": {
+			config: &cpmtypes.ExecConfig{EnvAllowlist: []string{"PATH", "TZ"}},
+			want:   []string{"PATH=/usr/bin"},
+		},
+		"empty allow-list": {
+			config: &cpmtypes.ExecConfig{EnvAllowlist: []string{}},
+			want:   []string{},
+		},
+		"extra variables": {
+			config: &cpmtypes.ExecConfig{Env: map[string]string{"LANG": "C", "TZ": "UTC"}},
+			want:   []string{"PATH=/usr/bin", "HOME=/root", "LANG=C", "TZ=UTC"},
+		},
+		"allow-list and extra variables": {
+			config: &cpmtypes.ExecConfig{EnvAllowlist: []string{"PATH", "LANG"}, Env: map[string]string{"LANG": "C"}},
+			want:   []string{"PATH=/usr/bin", "LANG=C"},
+		},
+	} {
+		if got := pluginEnv(environ, test.config); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: wanted environment %q, got %q", desc, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
+	"k8s.io/node-problem-detector/pkg/util"
+)
+
+const (
+	// minStreamBackoff is the delay before a streaming plugin which exited is
+	// restarted. It doubles every time the plugin exits early, up to
+	// maxStreamBackoff.
+	minStreamBackoff = time.Second
+	// maxStreamBackoff is the maximum restart delay of a streaming plugin. A
+	// plugin which ran for longer than this restarts after minStreamBackoff.
+	maxStreamBackoff = time.Minute
+	// streamStopGracePeriod is how long a streaming plugin is given to exit
+	// after it is terminated, before it is killed.
+	streamStopGracePeriod = 5 * time.Second
+	// maxStreamLineBytes is the maximum length of a line printed by a
+	// streaming plugin with the text output format.
+	maxStreamLineBytes = 64 * 1024
+)
+
+// runStream runs the streaming plugin of the rule until the plugin is stopped,
+// and restarts it with backoff when it exits.
+func (p *Plugin) runStream(rule *cpmtypes.CustomRule) {
+	defer p.Done()
+	backoff := minStreamBackoff
+	for {
+		start := p.clock.Now()
+		err := p.stream(rule)
+		select {
+		case <-p.tomb.Stopping():
+			return
+		default:
+		}
+		if p.clock.Since(start) > maxStreamBackoff {
+			backoff = minStreamBackoff
+		}
+		klog.Errorf("Streaming plugin %q exited, restarting in %v: %v", rule.Path, backoff, err)
+		if !p.sendResult(cpmtypes.Result{
+			Rule:       rule,
+			ExitStatus: cpmtypes.Unknown,
+			Message:    p.truncateOutput(fmt.Sprintf("Streaming plugin exited: %v", err)),
+		}) {
+			return
+		}
+		select {
+		case <-p.clock.After(backoff):
+		case <-p.tomb.Stopping():
+			return
+		}
+		backoff = min(2*backoff, maxStreamBackoff)
+	}
+}
+
+// stream starts the streaming plugin of the rule, and reports the results it
+// prints until it exits or the plugin is stopped. The plugin is terminated,
+// and killed after streamStopGracePeriod, when the plugin is stopped. It
+// returns why the plugin exited.
+func (p *Plugin) stream(rule *cpmtypes.CustomRule) error {
+	cmd := util.Exec(context.Background(), rule.Path, rule.Args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("error creating stdout pipe: %v", err)
+	}
+	cmd.Stderr = stderrLogger{path: rule.Path}
+	if err := p.startPlugin(cmd, rule); err != nil {
+		return fmt.Errorf("error in starting plugin: %v", err)
+	}
+	klog.Infof("Started streaming plugin %q with pid %d", rule.Path, cmd.Process.Pid)
+
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-p.tomb.Stopping():
+		case <-exited:
+			return
+		}
+		if err := util.Terminate(cmd); err != nil {
+			klog.Errorf("Error in terminating streaming plugin %q: %v", rule.Path, err)
+		}
+		select {
+		case <-p.clock.After(streamStopGracePeriod):
+			klog.Warningf("Streaming plugin %q did not exit in %v, killing it", rule.Path, streamStopGracePeriod)
+			if err := util.Kill(cmd); err != nil {
+				klog.Errorf("Error in killing streaming plugin %q: %v", rule.Path, err)
+			}
+		case <-exited:
+		}
+	}()
+
+	readErr := p.readStream(rule, stdout)
+	if readErr != nil {
+		// The rest of the output cannot be parsed, so the plugin is restarted.
+		if err := util.Kill(cmd); err != nil {
+			klog.Errorf("Error in killing streaming plugin %q: %v", rule.Path, err)
+		}
+	}
+	waitErr := cmd.Wait()
+	if cmd.ProcessState != nil {
+		if err := monitormetrics.GlobalMonitorMetricsManager.IncrementPluginExitCode(p.config.Source, rule.MetricName(), cmd.ProcessState.ExitCode()); err != nil {
+			klog.Errorf("Failed to update plugin exit code metrics for rule %+v: %v", rule, err)
+		}
+	}
+	if readErr != nil {
+		return fmt.Errorf("error reading output: %v", readErr)
+	}
+	if waitErr != nil {
+		return waitErr
+	}
+	return errors.New("exit status 0")
+}
+
+// readStream reports every line the streaming plugin of the rule prints as a
+// NonOK result, or every JSON document with the json output format, until the
+// output ends or the plugin is stopped.
+func (p *Plugin) readStream(rule *cpmtypes.CustomRule, r io.Reader) error {
+	if rule.OutputFormat == cpmtypes.JSONOutputFormat {
+		decoder := json.NewDecoder(r)
+		for {
+			var document json.RawMessage
+			if err := decoder.Decode(&document); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+			if !p.sendResult(p.parseJSONResult(rule, cpmtypes.OK, string(document))) {
+				return nil
+			}
+		}
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, maxCustomPluginBufferBytes), maxStreamLineBytes)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !p.sendResult(cpmtypes.Result{
+			Rule:       rule,
+			ExitStatus: cpmtypes.NonOK,
+			Message:    p.truncateOutput(line),
+		}) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// sendResult sends the result to the custom plugin monitor. It returns false
+// if the plugin is stopped.
+func (p *Plugin) sendResult(result cpmtypes.Result) bool {
+	select {
+	case p.resultChan <- result:
+		klog.V(3).Infof("Add stream result %+v for rule %+v", result, result.Rule)
+		return true
+	case <-p.tomb.Stopping():
+		return false
+	}
+}
+
+// stderrLogger logs the standard error of a streaming plugin.
+type stderrLogger struct {
+	path string
+}
+
+func (l stderrLogger) Write(b []byte) (int, error) {
+	if msg := bytes.TrimSpace(b); len(msg) != 0 {
+		klog.V(2).Infof("Streaming plugin %q: %s", l.path, msg)
+	}
+	return len(b), nil
+}
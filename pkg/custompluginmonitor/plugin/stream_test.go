@@ -0,0 +1,151 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	testclock "k8s.io/utils/clock/testing"
+
+	cpmtypes "k8s.io/node-problem-detector/pkg/custompluginmonitor/types"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+const streamTestTimeout = 5 * time.Second
+
+// newStreamPlugin creates a plugin running the streaming test plugin.
+func newStreamPlugin(t *testing.T, name string, outputFormat cpmtypes.OutputFormat) (*Plugin, *cpmtypes.CustomRule) {
+	t.Helper()
+	ext := "sh"
+	if runtime.GOOS == "windows" {
+		ext = "cmd"
+	}
+	rule := &cpmtypes.CustomRule{
+		Type:         types.Temp,
+		Reason:       "Stream",
+		Path:         "./test-data/" + name + "." + ext,
+		Mode:         cpmtypes.StreamMode,
+		OutputFormat: outputFormat,
+	}
+	conf := cpmtypes.CustomPluginConfig{Source: "test-source", Rules: []*cpmtypes.CustomRule{rule}}
+	if err := (&conf).ApplyConfiguration(); err != nil {
+		t.Fatalf("Failed to apply configuration: %v", err)
+	}
+	return NewPlugin(conf), rule
+}
+
+// receiveResult returns the next result of the plugin or fails the test.
+func receiveResult(t *testing.T, p *Plugin) cpmtypes.Result {
+	t.Helper()
+	select {
+	case result := <-p.GetResultChan():
+		return result
+	case <-time.After(streamTestTimeout):
+		t.Fatal("Timed out waiting for a stream result")
+		return cpmtypes.Result{}
+	}
+}
+
+// stopStream stops the plugin and fails the test if the streaming plugin does
+// not shut down in time.
+func stopStream(t *testing.T, p *Plugin) {
+	t.Helper()
+	p.Stop()
+	timeout := time.After(streamStopGracePeriod)
+	for {
+		select {
+		case _, ok := <-p.GetResultChan():
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Streaming plugin did not stop before its grace period")
+		}
+	}
+}
+
+func TestStreamReportsLines(t *testing.T) {
+	p, rule := newStreamPlugin(t, "stream", "")
+	go p.Run()
+
+	for _, message := range []string{"link eth0 down", "link eth1 down"} {
+		want := cpmtypes.Result{Rule: rule, ExitStatus: cpmtypes.NonOK, Message: message}
+		if got := receiveResult(t, p); !reflect.DeepEqual(got, want) {
+			t.Errorf("Wanted stream result %+v, got %+v", want, got)
+		}
+	}
+	stopStream(t, p)
+}
+
+func TestStreamReportsJSONDocuments(t *testing.T) {
+	p, rule := newStreamPlugin(t, "stream-json", cpmtypes.JSONOutputFormat)
+	go p.Run()
+
+	for _, want := range []cpmtypes.Result{
+		{Rule: rule, ExitStatus: cpmtypes.NonOK, Reason: "LinkDown", Message: "eth0 is down"},
+		{Rule: rule, ExitStatus: cpmtypes.OK, Message: "eth0 is up"},
+	} {
+		if got := receiveResult(t, p); !reflect.DeepEqual(got, want) {
+			t.Errorf("Wanted stream result %+v, got %+v", want, got)
+		}
+	}
+	stopStream(t, p)
+}
+
+func TestStreamRestartsWithBackoff(t *testing.T) {
+	p, rule := newStreamPlugin(t, "stream-exit", "")
+	fakeClock := testclock.NewFakeClock(time.Unix(0, 0))
+	p.clock = fakeClock
+	go p.Run()
+
+	// waitForRestart checks the plugin output and exit, and waits until the
+	// restart backoff starts.
+	waitForRestart := func() {
+		t.Helper()
+		if got := receiveResult(t, p); got.ExitStatus != cpmtypes.NonOK || got.Message != "crashed" {
+			t.Errorf("Wanted the output of the plugin, got %+v", got)
+		}
+		want := cpmtypes.Result{Rule: rule, ExitStatus: cpmtypes.Unknown, Message: "Streaming plugin exited: exit status 1"}
+		if got := receiveResult(t, p); !reflect.DeepEqual(got, want) {
+			t.Errorf("Wanted stream result %+v, got %+v", want, got)
+		}
+		deadline := time.Now().Add(streamTestTimeout)
+		for !fakeClock.HasWaiters() {
+			if time.Now().After(deadline) {
+				t.Fatal("Timed out waiting for the restart backoff")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitForRestart()
+	fakeClock.Step(minStreamBackoff)
+	waitForRestart()
+	// The backoff doubled.
+	fakeClock.Step(minStreamBackoff)
+	select {
+	case result := <-p.GetResultChan():
+		t.Fatalf("Wanted no result before the backoff elapsed, got %+v", result)
+	case <-time.After(100 * time.Millisecond):
+	}
+	fakeClock.Step(minStreamBackoff)
+	waitForRestart()
+	stopStream(t, p)
+}
@@ -0,0 +1,4 @@
+@echo off
+
+echo {"status": 1, "reason": "JSONProblem", "message": "the document is longer than 80 bytes", "metrics": {"value": 1}}
+exit 0
@@ -0,0 +1,4 @@
+#!/usr/bin/env bash
+
+echo "DISK CRITICAL - free space: / 12 MB, /boot 30 MB | /=5956MB;5948;5958;0;5968 /boot=68MB;88;93;0;98"
+exit 2
@@ -0,0 +1,5 @@
+@echo off
+
+echo DISK WARNING - free space: / 120 MB ^| /=5848MB;5948;5958;0;5968
+ping 127.0.0.1 -n 3 > nul
+exit 1
@@ -0,0 +1,5 @@
+#!/usr/bin/env bash
+
+echo "DISK WARNING - free space: / 120 MB | /=5848MB;5948;5958;0;5968"
+sleep 3
+exit 1
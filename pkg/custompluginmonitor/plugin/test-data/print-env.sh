@@ -0,0 +1,4 @@
+#!/usr/bin/env bash
+
+echo "${NPD_TEST_VALUE:-unset} ${HOME:-unset} $(pwd) $(grep NoNewPrivs /proc/self/status | cut -f2) $(id -u)"
+exit 0
@@ -0,0 +1,4 @@
+@echo off
+
+echo crashed
+exit 1
@@ -0,0 +1,4 @@
+#!/usr/bin/env bash
+
+echo "crashed"
+exit 1
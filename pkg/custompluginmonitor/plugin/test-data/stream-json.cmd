@@ -0,0 +1,9 @@
+@echo off
+
+echo {
+echo   "status": 1,
+echo   "reason": "LinkDown",
+echo   "message": "eth0 is down"
+echo }
+echo {"status": 0, "message": "eth0 is up"}
+ping 127.0.0.1 -n 3600 > nul
@@ -0,0 +1,11 @@
+#!/usr/bin/env bash
+
+cat <<'DOCUMENTS'
+{
+  "status": 1,
+  "reason": "LinkDown",
+  "message": "eth0 is down"
+}
+{"status": 0, "message": "eth0 is up"}
+DOCUMENTS
+exec sleep 3600
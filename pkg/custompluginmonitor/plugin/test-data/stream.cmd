@@ -0,0 +1,6 @@
+@echo off
+
+echo link eth0 down
+echo.
+echo link eth1 down
+ping 127.0.0.1 -n 3600 > nul
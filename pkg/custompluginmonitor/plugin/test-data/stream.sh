@@ -0,0 +1,6 @@
+#!/usr/bin/env bash
+
+echo "link eth0 down"
+echo ""
+echo "link eth1 down"
+exec sleep 3600
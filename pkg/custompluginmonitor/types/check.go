@@ -0,0 +1,221 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CheckKind is the kind of a built-in check.
+type CheckKind string
+
+const (
+	// HTTPCheck sends a GET request and checks the status code and the body.
+	HTTPCheck CheckKind = "http"
+	// TCPCheck connects to a TCP address.
+	TCPCheck CheckKind = "tcp"
+	// DNSCheck looks up a host name.
+	DNSCheck CheckKind = "dns"
+	// FileCheck checks that a file exists, and optionally its content or numeric value.
+	FileCheck CheckKind = "file"
+	// CommandCheck runs a command directly, without a shell, and maps its exit code
+	// like a plugin.
+	CommandCheck CheckKind = "command"
+	// PrometheusCheck scrapes a Prometheus endpoint and checks the value of a
+	// series, or its rate, against a threshold.
+	PrometheusCheck CheckKind = "prometheus"
+)
+
+// defaultHTTPStatusCode is the status code an http check expects by default.
+const defaultHTTPStatusCode = 200
+
+// CheckConfig configures a built-in check a rule runs instead of a plugin.
+// A check that passes is OK, a check that fails is NonOK, and a check that
+// cannot tell is Unknown.
+type CheckConfig struct {
+	// Kind is the kind of the check.
+	Kind CheckKind `json:"kind"`
+	// URL is the URL an http check requests, or a prometheus check scrapes.
+	URL string `json:"url,omitempty"`
+	// Socket is the path of the unix socket the request of an http or a
+	// prometheus check is sent through, instead of the host of the URL.
+	Socket string `json:"socket,omitempty"`
+	// StatusCode is the status code an http check expects. Defaults to 200.
+	StatusCode int `json:"status_code,omitempty"`
+	// BodyPattern is the regular expression the body of an http check must match.
+	BodyPattern string `json:"body_pattern,omitempty"`
+	// Address is the host:port a tcp check connects to.
+	Address string `json:"address,omitempty"`
+	// Host is the host name a dns check looks up.
+	Host string `json:"host,omitempty"`
+	// Path is the path of the file a file check reads, e.g. a /proc or /sys file.
+	Path string `json:"path,omitempty"`
+	// ContentPattern is the regular expression the content of a file check must match.
+	ContentPattern string `json:"content_pattern,omitempty"`
+	// ThresholdString is the condition the numeric value of a file or a
+	// prometheus check must satisfy, e.g. "< 90". The operator is one of <, <=,
+	// >, >=, == and !=.
+	ThresholdString string `json:"threshold,omitempty"`
+	// Field is the index of the whitespace separated field of the file content
+	// that holds the numeric value. Defaults to the first field.
+	Field int `json:"field,omitempty"`
+	// Command is the command and arguments a command check runs.
+	Command []string `json:"command,omitempty"`
+	// Metric is the name of the series a prometheus check selects.
+	Metric string `json:"metric,omitempty"`
+	// Labels are the labels the series a prometheus check selects must have.
+	// The series may have other labels, but only one series may be selected.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Rate makes a prometheus check compare the per-second rate of the series
+	// between the last two scrapes against the threshold, e.g. for counters.
+	Rate bool `json:"rate,omitempty"`
+
+	// BodyRegexp is the compiled BodyPattern.
+	BodyRegexp *regexp.Regexp `json:"-"`
+	// ContentRegexp is the compiled ContentPattern.
+	ContentRegexp *regexp.Regexp `json:"-"`
+	// Threshold is the parsed ThresholdString.
+	Threshold *Threshold `json:"-"`
+}
+
+// ApplyConfiguration applies default configurations and compiles the patterns.
+func (c *CheckConfig) ApplyConfiguration() error {
+	if c.Kind == HTTPCheck && c.StatusCode == 0 {
+		c.StatusCode = defaultHTTPStatusCode
+	}
+	var err error
+	if c.BodyPattern != "" {
+		if c.BodyRegexp, err = regexp.Compile(c.BodyPattern); err != nil {
+			return fmt.Errorf("error in compiling body pattern %q: %v", c.BodyPattern, err)
+		}
+	}
+	if c.ContentPattern != "" {
+		if c.ContentRegexp, err = regexp.Compile(c.ContentPattern); err != nil {
+			return fmt.Errorf("error in compiling content pattern %q: %v", c.ContentPattern, err)
+		}
+	}
+	if c.ThresholdString != "" {
+		if c.Threshold, err = ParseThreshold(c.ThresholdString); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate verifies that the check has the settings its kind requires.
+func (c CheckConfig) Validate() error {
+	switch c.Kind {
+	case HTTPCheck, PrometheusCheck:
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s check requires an http or https url, got %q", c.Kind, c.URL)
+		}
+		if c.Socket != "" && !filepath.IsAbs(c.Socket) {
+			return fmt.Errorf("%s check requires an absolute socket path, got %q", c.Kind, c.Socket)
+		}
+		if c.Kind == HTTPCheck {
+			break
+		}
+		if c.Metric == "" {
+			return fmt.Errorf("prometheus check requires a metric")
+		}
+		if c.ThresholdString == "" {
+			return fmt.Errorf("prometheus check requires a threshold")
+		}
+	case TCPCheck:
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("tcp check requires a host:port address, got %q: %v", c.Address, err)
+		}
+	case DNSCheck:
+		if c.Host == "" {
+			return fmt.Errorf("dns check requires a host")
+		}
+	case FileCheck:
+		if c.Path == "" {
+			return fmt.Errorf("file check requires a path")
+		}
+		if c.Field < 0 {
+			return fmt.Errorf("file check field must not be negative: %d", c.Field)
+		}
+	case CommandCheck:
+		if len(c.Command) == 0 || c.Command[0] == "" {
+			return fmt.Errorf("command check requires a command")
+		}
+	default:
+		return fmt.Errorf("unknown check kind %q", c.Kind)
+	}
+	return nil
+}
+
+// Threshold is a comparison a numeric value must satisfy.
+type Threshold struct {
+	// Operator is one of <, <=, >, >=, == and !=.
+	Operator string
+	// Value is the value compared against.
+	Value float64
+}
+
+// thresholdOperators are the supported threshold operators. The two character
+// operators come first, so that they take precedence when parsing.
+var thresholdOperators = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+// ParseThreshold parses a threshold expression such as "> 0.9".
+func ParseThreshold(expr string) (*Threshold, error) {
+	expr = strings.TrimSpace(expr)
+	for _, op := range thresholdOperators {
+		rest, ok := strings.CutPrefix(expr, op)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(rest), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold %q: %v", expr, err)
+		}
+		return &Threshold{Operator: op, Value: value}, nil
+	}
+	return nil, fmt.Errorf("invalid threshold %q, expected an operator of %v followed by a number", expr, thresholdOperators)
+}
+
+// Satisfied reports whether the value satisfies the threshold.
+func (t Threshold) Satisfied(value float64) bool {
+	switch t.Operator {
+	case "<":
+		return value < t.Value
+	case "<=":
+		return value <= t.Value
+	case ">":
+		return value > t.Value
+	case ">=":
+		return value >= t.Value
+	case "==":
+		return value == t.Value
+	case "!=":
+		return value != t.Value
+	}
+	return false
+}
+
+// String returns the threshold expression.
+func (t Threshold) String() string {
+	return fmt.Sprintf("%s %v", t.Operator, t.Value)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseThreshold(t *testing.T) {
+	for _, test := range []struct {
+		expr      string
+		expected  *Threshold
+		satisfied []float64
+		violated  []float64
+	}{
+		{expr: "> 0.9", expected: &Threshold{Operator: ">", Value: 0.9}, satisfied: []float64{1}, violated: []float64{0.9}},
+		{expr: ">=0.9", expected: &Threshold{Operator: ">=", Value: 0.9}, satisfied: []float64{0.9}, violated: []float64{0.8}},
+		{expr: "< 10", expected: &Threshold{Operator: "<", Value: 10}, satisfied: []float64{9}, violated: []float64{10}},
+		{expr: "<= 10", expected: &Threshold{Operator: "<=", Value: 10}, satisfied: []float64{10}, violated: []float64{11}},
+		{expr: "== 1", expected: &Threshold{Operator: "==", Value: 1}, satisfied: []float64{1}, violated: []float64{0}},
+		{expr: "!= 1", expected: &Threshold{Operator: "!=", Value: 1}, satisfied: []float64{0}, violated: []float64{1}},
+		{expr: "1"},
+		{expr: "> one"},
+	} {
+		t.Run(test.expr, func(t *testing.T) {
+			threshold, err := ParseThreshold(test.expr)
+			if test.expected == nil {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, threshold)
+			for _, value := range test.satisfied {
+				assert.True(t, threshold.Satisfied(value), "%v should satisfy %s", value, threshold)
+			}
+			for _, value := range test.violated {
+				assert.False(t, threshold.Satisfied(value), "%v should not satisfy %s", value, threshold)
+			}
+		})
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		check    CheckConfig
+		applyErr bool
+		validErr bool
+	}{
+		{name: "http", check: CheckConfig{Kind: HTTPCheck, URL: "http://127.0.0.1:10248/healthz", BodyPattern: "ok"}},
+		{name: "http without url", check: CheckConfig{Kind: HTTPCheck}, validErr: true},
+		{name: "http with invalid body pattern", check: CheckConfig{Kind: HTTPCheck, URL: "http://127.0.0.1", BodyPattern: "("}, applyErr: true},
+		{name: "tcp", check: CheckConfig{Kind: TCPCheck, Address: "127.0.0.1:10250"}},
+		{name: "tcp without port", check: CheckConfig{Kind: TCPCheck, Address: "127.0.0.1"}, validErr: true},
+		{name: "dns", check: CheckConfig{Kind: DNSCheck, Host: "kubernetes.default"}},
+		{name: "dns without host", check: CheckConfig{Kind: DNSCheck}, validErr: true},
+		{name: "file", check: CheckConfig{Kind: FileCheck, Path: "/proc/sys/fs/file-nr", ThresholdString: "< 100000"}},
+		{name: "file without path", check: CheckConfig{Kind: FileCheck}, validErr: true},
+		{name: "file with invalid threshold", check: CheckConfig{Kind: FileCheck, Path: "/proc/loadavg", ThresholdString: "~ 1"}, applyErr: true},
+		{name: "command", check: CheckConfig{Kind: CommandCheck, Command: []string{"systemctl", "is-active", "kubelet"}}},
+		{name: "command without command", check: CheckConfig{Kind: CommandCheck}, validErr: true},
+		{name: "http through a socket", check: CheckConfig{Kind: HTTPCheck, URL: "http://localhost/healthz", Socket: filepath.Join(os.TempDir(), "health.sock")}},
+		{name: "http with relative socket", check: CheckConfig{Kind: HTTPCheck, URL: "http://localhost/healthz", Socket: "health.sock"}, validErr: true},
+		{
+			name: "prometheus",
+			check: CheckConfig{Kind: PrometheusCheck, URL: "http://127.0.0.1:10255/metrics", Metric: "kubelet_pleg_relist_duration_seconds_sum",
+				Labels: map[string]string{"node": "node-1"}, ThresholdString: "< 1", Rate: true},
+		},
+		{name: "prometheus without url", check: CheckConfig{Kind: PrometheusCheck, Metric: "up", ThresholdString: "== 1"}, validErr: true},
+		{name: "prometheus without metric", check: CheckConfig{Kind: PrometheusCheck, URL: "http://127.0.0.1:10255/metrics", ThresholdString: "== 1"}, validErr: true},
+		{name: "prometheus without threshold", check: CheckConfig{Kind: PrometheusCheck, URL: "http://127.0.0.1:10255/metrics", Metric: "up"}, validErr: true},
+		{name: "prometheus with invalid threshold", check: CheckConfig{Kind: PrometheusCheck, URL: "http://127.0.0.1:10255/metrics", Metric: "up", ThresholdString: "1"}, applyErr: true},
+		{name: "unknown kind", check: CheckConfig{Kind: "ping"}, validErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.check.ApplyConfiguration()
+			if test.applyErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			err = test.check.Validate()
+			if test.validErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"k8s.io/node-problem-detector/pkg/types"
+	"k8s.io/node-problem-detector/pkg/util"
 )
 
 var (
@@ -55,6 +56,8 @@ type pluginGlobalConfig struct {
 	EnableMessageChangeBasedConditionUpdate *bool `json:"enable_message_change_based_condition_update,omitempty"`
 	// SkipInitialStatus prevents the first status update with default conditions
 	SkipInitialStatus *bool `json:"skip_initial_status,omitempty"`
+	// Exec is the execution configuration of every plugin.
+	Exec *ExecConfig `json:"exec,omitempty"`
 }
 
 // CustomPluginConfig is the configuration of custom plugin monitor.
@@ -76,6 +79,25 @@ type CustomPluginConfig struct {
 
 // ApplyConfiguration applies default configurations.
 func (cpc *CustomPluginConfig) ApplyConfiguration() error {
+	if err := cpc.applyGlobalTimeout(); err != nil {
+		return err
+	}
+	if err := cpc.applyGlobalInvokeInterval(); err != nil {
+		return err
+	}
+	if err := cpc.applyGlobalExec(); err != nil {
+		return err
+	}
+	cpc.applyDefaults()
+	for _, rule := range cpc.Rules {
+		if err := rule.applyConfiguration(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cpc *CustomPluginConfig) applyGlobalTimeout() error {
 	if cpc.PluginGlobalConfig.TimeoutString == nil {
 		cpc.PluginGlobalConfig.TimeoutString = &defaultGlobalTimeoutString
 	}
@@ -86,7 +108,10 @@ func (cpc *CustomPluginConfig) ApplyConfiguration() error {
 	}
 
 	cpc.PluginGlobalConfig.Timeout = &timeout
+	return nil
+}
 
+func (cpc *CustomPluginConfig) applyGlobalInvokeInterval() error {
 	if cpc.PluginGlobalConfig.InvokeIntervalString == nil {
 		cpc.PluginGlobalConfig.InvokeIntervalString = &defaultInvokeIntervalString
 	}
@@ -97,7 +122,20 @@ func (cpc *CustomPluginConfig) ApplyConfiguration() error {
 	}
 
 	cpc.PluginGlobalConfig.InvokeInterval = &invokeInterval
+	return nil
+}
+
+func (cpc *CustomPluginConfig) applyGlobalExec() error {
+	if cpc.PluginGlobalConfig.Exec == nil {
+		return nil
+	}
+	if err := cpc.PluginGlobalConfig.Exec.ApplyConfiguration(); err != nil {
+		return fmt.Errorf("error in applying global exec configuration: %v", err)
+	}
+	return nil
+}
 
+func (cpc *CustomPluginConfig) applyDefaults() {
 	if cpc.PluginGlobalConfig.MaxOutputLength == nil {
 		cpc.PluginGlobalConfig.MaxOutputLength = &defaultMaxOutputLength
 	}
@@ -112,71 +150,297 @@ func (cpc *CustomPluginConfig) ApplyConfiguration() error {
 		cpc.PluginGlobalConfig.SkipInitialStatus = &defaultSkipInitialStatus
 	}
 
+	if cpc.EnableMetricsReporting == nil {
+		cpc.EnableMetricsReporting = &defaultEnableMetricsReporting
+	}
+}
+
+// applyConfiguration parses the durations and applies the check configuration
+// of the rule.
+func (rule *CustomRule) applyConfiguration() error {
+	if rule.TimeoutString != nil {
+		timeout, err := time.ParseDuration(*rule.TimeoutString)
+		if err != nil {
+			return fmt.Errorf("error in parsing rule timeout %+v: %v", rule, err)
+		}
+		rule.Timeout = &timeout
+	}
+	if rule.InvokeIntervalString != nil {
+		invokeInterval, err := time.ParseDuration(*rule.InvokeIntervalString)
+		if err != nil {
+			return fmt.Errorf("error in parsing rule invoke interval %+v: %v", rule, err)
+		}
+		rule.InvokeInterval = &invokeInterval
+	}
+	if rule.CooldownString != nil {
+		cooldown, err := time.ParseDuration(*rule.CooldownString)
+		if err != nil {
+			return fmt.Errorf("error in parsing rule cooldown %+v: %v", rule, err)
+		}
+		rule.Cooldown = &cooldown
+	}
+	if rule.Check != nil {
+		if err := rule.Check.ApplyConfiguration(); err != nil {
+			return fmt.Errorf("error in applying check configuration of rule %+v: %v", rule, err)
+		}
+	}
+	if rule.Exec != nil {
+		if err := rule.Exec.ApplyConfiguration(); err != nil {
+			return fmt.Errorf("error in applying exec configuration of rule %+v: %v", rule, err)
+		}
+	}
+	return nil
+}
+
+// Validate verifies whether the settings in CustomPluginConfig are valid.
+func (cpc CustomPluginConfig) Validate() error {
+	if err := cpc.validatePlugin(); err != nil {
+		return err
+	}
+	if err := cpc.validateGlobalInvokeInterval(); err != nil {
+		return err
+	}
+	if err := cpc.validateGlobalExec(); err != nil {
+		return err
+	}
+
 	for _, rule := range cpc.Rules {
-		if rule.TimeoutString != nil {
-			timeout, err := time.ParseDuration(*rule.TimeoutString)
-			if err != nil {
-				return fmt.Errorf("error in parsing rule timeout %+v: %v", rule, err)
-			}
-			rule.Timeout = &timeout
+		if err := cpc.validateRule(rule); err != nil {
+			return err
 		}
-		if rule.InvokeIntervalString != nil {
-			invokeInterval, err := time.ParseDuration(*rule.InvokeIntervalString)
-			if err != nil {
-				return fmt.Errorf("error in parsing rule invoke interval %+v: %v", rule, err)
-			}
-			rule.InvokeInterval = &invokeInterval
+	}
+
+	for _, rule := range cpc.Rules {
+		if err := validateRuleCommand(rule); err != nil {
+			return err
 		}
 	}
 
-	if cpc.EnableMetricsReporting == nil {
-		cpc.EnableMetricsReporting = &defaultEnableMetricsReporting
+	for _, rule := range cpc.Rules {
+		if err := cpc.validateRuleCondition(rule); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// Validate verifies whether the settings in CustomPluginConfig are valid.
-func (cpc CustomPluginConfig) Validate() error {
+func (cpc CustomPluginConfig) validatePlugin() error {
 	if cpc.Plugin != customPluginName {
 		return fmt.Errorf("NPD does not support %q plugin for now. Only support \"custom\"", cpc.Plugin)
 	}
+	return nil
+}
+
+func (cpc CustomPluginConfig) validateGlobalInvokeInterval() error {
 	if *cpc.PluginGlobalConfig.InvokeInterval <= 0 {
 		return fmt.Errorf("global invoke interval must be greater than zero: %v", *cpc.PluginGlobalConfig.InvokeInterval)
 	}
+	return nil
+}
 
-	for _, rule := range cpc.Rules {
-		if rule.InvokeInterval != nil && *rule.InvokeInterval <= 0 {
-			return fmt.Errorf("rule invoke interval must be greater than zero. Rule: %+v", rule)
-		}
-		if rule.Timeout != nil && *rule.Timeout > *cpc.PluginGlobalConfig.Timeout {
-			return fmt.Errorf("plugin timeout is greater than global timeout. "+
-				"Rule: %+v. Global timeout: %v", rule, cpc.PluginGlobalConfig.Timeout)
+func (cpc CustomPluginConfig) validateGlobalExec() error {
+	if cpc.PluginGlobalConfig.Exec == nil {
+		return nil
+	}
+	if err := cpc.PluginGlobalConfig.Exec.Validate(); err != nil {
+		return fmt.Errorf("invalid global exec configuration: %v", err)
+	}
+	return nil
+}
+
+// validateRule verifies the invoke interval, mode, cooldown, thresholds,
+// output format, severity, timeout and exec configuration of the rule.
+func (cpc CustomPluginConfig) validateRule(rule *CustomRule) error {
+	if rule.InvokeInterval != nil && *rule.InvokeInterval <= 0 {
+		return fmt.Errorf("rule invoke interval must be greater than zero. Rule: %+v", rule)
+	}
+	if err := validateRuleMode(rule); err != nil {
+		return err
+	}
+	if err := validateRuleProtocol(rule); err != nil {
+		return err
+	}
+	if err := validateRuleCooldown(rule); err != nil {
+		return err
+	}
+	if err := validateRuleThresholds(rule); err != nil {
+		return err
+	}
+	if rule.OutputFormat != "" && rule.OutputFormat != TextOutputFormat && rule.OutputFormat != JSONOutputFormat {
+		return fmt.Errorf("unknown output format %q. Rule: %+v", rule.OutputFormat, rule)
+	}
+	if _, err := types.ParseSeverity(rule.Severity); err != nil {
+		return fmt.Errorf("%v. Rule: %+v", err, rule)
+	}
+	if rule.Timeout != nil && *rule.Timeout > *cpc.PluginGlobalConfig.Timeout {
+		return fmt.Errorf("plugin timeout is greater than global timeout. "+
+			"Rule: %+v. Global timeout: %v", rule, cpc.PluginGlobalConfig.Timeout)
+	}
+	if rule.Exec != nil {
+		if err := rule.Exec.Validate(); err != nil {
+			return fmt.Errorf("invalid exec configuration of rule %+v: %v", rule, err)
 		}
 	}
+	return nil
+}
 
-	for _, rule := range cpc.Rules {
-		if _, err := os.Stat(rule.Path); os.IsNotExist(err) {
-			return fmt.Errorf("rule path %q does not exist. Rule: %+v", rule.Path, rule)
+// validateRuleMode verifies the mode of the rule. A streaming plugin is not
+// invoked periodically, and every line of its text output is a problem, so
+// it needs the json output format to update conditions.
+func validateRuleMode(rule *CustomRule) error {
+	switch rule.Mode {
+	case "", PeriodicMode:
+		return nil
+	case StreamMode:
+	default:
+		return fmt.Errorf("unknown mode %q. Rule: %+v", rule.Mode, rule)
+	}
+	if rule.Check != nil {
+		return fmt.Errorf("stream mode does not support built-in checks. Rule: %+v", rule)
+	}
+	if rule.InvokeInterval != nil || rule.Timeout != nil {
+		return fmt.Errorf("stream mode does not support invoke_interval and timeout. Rule: %+v", rule)
+	}
+	if rule.Type != types.Temp && rule.OutputFormat != JSONOutputFormat {
+		return fmt.Errorf("stream mode with text output only supports temporary rules. Rule: %+v", rule)
+	}
+	return nil
+}
+
+// validateRuleProtocol verifies the protocol of the rule. A nagios plugin
+// reports its result through its exit code and text output, so it is run
+// periodically, either from the path of the rule or by a command check.
+func validateRuleProtocol(rule *CustomRule) error {
+	switch rule.Protocol {
+	case "", NPDProtocol:
+		return nil
+	case NagiosProtocol:
+	default:
+		return fmt.Errorf("unknown protocol %q. Rule: %+v", rule.Protocol, rule)
+	}
+	if rule.OutputFormat == JSONOutputFormat {
+		return fmt.Errorf("nagios protocol does not support the json output format. Rule: %+v", rule)
+	}
+	if rule.IsStream() {
+		return fmt.Errorf("nagios protocol does not support stream mode. Rule: %+v", rule)
+	}
+	if rule.Check != nil && rule.Check.Kind != CommandCheck {
+		return fmt.Errorf("nagios protocol only supports command checks. Rule: %+v", rule)
+	}
+	return nil
+}
+
+// validateRuleCooldown verifies that only a temporary rule has a cooldown, and
+// the events it emits per cooldown window.
+func validateRuleCooldown(rule *CustomRule) error {
+	if rule.Cooldown == nil {
+		if rule.MaxEventsPerWindow != 0 {
+			return fmt.Errorf("max_events_per_window is only supported with cooldown. Rule: %+v", rule)
 		}
+		return nil
 	}
+	if rule.Type != types.Temp {
+		return fmt.Errorf("cooldown is only supported by temporary rules. Rule: %+v", rule)
+	}
+	if *rule.Cooldown <= 0 {
+		return fmt.Errorf("rule cooldown must be greater than zero. Rule: %+v", rule)
+	}
+	if rule.MaxEventsPerWindow < 0 {
+		return fmt.Errorf("max_events_per_window must not be negative. Rule: %+v", rule)
+	}
+	return nil
+}
 
-	for _, rule := range cpc.Rules {
-		if rule.Type != types.Perm {
-			continue
+// validateRuleThresholds verifies the failure and success thresholds and the
+// unknown policy of the rule.
+func validateRuleThresholds(rule *CustomRule) error {
+	if rule.FailureThreshold < 0 {
+		return fmt.Errorf("failure_threshold must not be negative. Rule: %+v", rule)
+	}
+	if rule.SuccessThreshold < 0 {
+		return fmt.Errorf("success_threshold must not be negative. Rule: %+v", rule)
+	}
+	switch rule.UnknownPolicy {
+	case "", UnknownPolicyUnknown, UnknownPolicyKeep, UnknownPolicyFailure:
+		return nil
+	default:
+		return fmt.Errorf("unknown unknown_policy %q. Rule: %+v", rule.UnknownPolicy, rule)
+	}
+}
+
+// validateRuleCommand verifies that the rule runs either an existing plugin
+// or a valid built-in check.
+func validateRuleCommand(rule *CustomRule) error {
+	if rule.Check != nil {
+		if rule.Path != "" {
+			return fmt.Errorf("rule must not set both path and check. Rule: %+v", rule)
 		}
-		conditionType := rule.Condition
-		defaultConditionExists := false
-		for _, cond := range cpc.DefaultConditions {
-			if conditionType == cond.Type {
-				defaultConditionExists = true
-				break
-			}
+		if err := rule.Check.Validate(); err != nil {
+			return fmt.Errorf("invalid check of rule %+v: %v", rule, err)
 		}
-		if !defaultConditionExists {
-			return fmt.Errorf("permanent problem %s does not have preset default condition", conditionType)
+		return nil
+	}
+	if _, err := os.Stat(rule.Path); os.IsNotExist(err) {
+		return fmt.Errorf("rule path %q does not exist. Rule: %+v", rule.Path, rule)
+	}
+	return nil
+}
+
+// validateRuleCondition verifies that a permanent rule has a preset default
+// condition.
+func (cpc CustomPluginConfig) validateRuleCondition(rule *CustomRule) error {
+	if rule.Type != types.Perm {
+		return nil
+	}
+	for _, cond := range cpc.DefaultConditions {
+		if rule.Condition == cond.Type {
+			return nil
 		}
 	}
+	return fmt.Errorf("permanent problem %s does not have preset default condition", rule.Condition)
+}
 
-	return nil
+// ValidateAll applies default configurations and verifies the settings like
+// ApplyConfiguration and Validate, but reports every invalid value rather than
+// the first one. The errors are util.ConfigErrors at the JSON path of the
+// invalid value.
+func (cpc *CustomPluginConfig) ValidateAll() []error {
+	var errs []error
+	add := func(path string, err error) {
+		if err != nil {
+			errs = append(errs, util.NewConfigError(path, err))
+		}
+	}
+	add("$.plugin", cpc.validatePlugin())
+	if err := cpc.applyGlobalTimeout(); err != nil {
+		add("$.pluginConfig.timeout", err)
+		cpc.PluginGlobalConfig.Timeout = &defaultGlobalTimeout
+	}
+	if err := cpc.applyGlobalInvokeInterval(); err != nil {
+		add("$.pluginConfig.invoke_interval", err)
+	} else {
+		add("$.pluginConfig.invoke_interval", cpc.validateGlobalInvokeInterval())
+	}
+	if err := cpc.applyGlobalExec(); err != nil {
+		add("$.pluginConfig.exec", err)
+	} else {
+		add("$.pluginConfig.exec", cpc.validateGlobalExec())
+	}
+	cpc.applyDefaults()
+	for i, rule := range cpc.Rules {
+		path := fmt.Sprintf("$.rules[%d]", i)
+		if err := rule.applyConfiguration(); err != nil {
+			add(path, err)
+			continue
+		}
+		add(path, cpc.validateRule(rule))
+		if rule.Check != nil && rule.Path == "" {
+			add(path+".check", validateRuleCommand(rule))
+		} else {
+			add(path+".path", validateRuleCommand(rule))
+		}
+		add(path+".condition", cpc.validateRuleCondition(rule))
+	}
+	return errs
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package types
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -24,6 +25,7 @@ import (
 	"time"
 
 	"k8s.io/node-problem-detector/pkg/types"
+	"k8s.io/node-problem-detector/pkg/util"
 )
 
 type applyConfigurationTestCase struct {
@@ -48,6 +50,9 @@ func applyConfigurationTestCases() map[string]applyConfigurationTestCase {
 	ruleInvokeInterval := 7 * time.Second
 	ruleInvokeIntervalString := ruleInvokeInterval.String()
 	invalidRuleInvokeIntervalString := "invalid"
+	ruleCooldown := 5 * time.Minute
+	ruleCooldownString := ruleCooldown.String()
+	invalidRuleCooldownString := "invalid"
 
 	return map[string]applyConfigurationTestCase{
 		"global default settings": {
@@ -131,6 +136,49 @@ func applyConfigurationTestCases() map[string]applyConfigurationTestCase {
 			},
 			ErrorMessageStart: "error in parsing rule invoke interval",
 		},
+		"custom rule cooldown": {
+			Orig: CustomPluginConfig{
+				Rules: []*CustomRule{
+					{
+						Type:           types.Temp,
+						Path:           "../plugin/test-data/ok.sh",
+						CooldownString: &ruleCooldownString,
+					},
+				},
+			},
+			Wanted: CustomPluginConfig{
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeIntervalString:                    &defaultInvokeIntervalString,
+					InvokeInterval:                          &defaultInvokeInterval,
+					TimeoutString:                           &defaultGlobalTimeoutString,
+					Timeout:                                 &defaultGlobalTimeout,
+					MaxOutputLength:                         &defaultMaxOutputLength,
+					Concurrency:                             &defaultConcurrency,
+					EnableMessageChangeBasedConditionUpdate: &defaultMessageChangeBasedConditionUpdate,
+					SkipInitialStatus:                       &defaultSkipInitialStatus,
+				},
+				EnableMetricsReporting: &defaultEnableMetricsReporting,
+				Rules: []*CustomRule{
+					{
+						Type:           types.Temp,
+						Path:           "../plugin/test-data/ok.sh",
+						CooldownString: &ruleCooldownString,
+						Cooldown:       &ruleCooldown,
+					},
+				},
+			},
+		},
+		"invalid rule cooldown": {
+			Orig: CustomPluginConfig{
+				Rules: []*CustomRule{
+					{
+						Path:           "../plugin/test-data/ok.sh",
+						CooldownString: &invalidRuleCooldownString,
+					},
+				},
+			},
+			ErrorMessageStart: "error in parsing rule cooldown",
+		},
 		"custom invoke interval": {
 			Orig: CustomPluginConfig{
 				PluginGlobalConfig: pluginGlobalConfig{
@@ -303,6 +351,8 @@ func TestCustomPluginConfigValidate(t *testing.T) {
 	exceededRuleTimeout := defaultGlobalTimeout + 1*time.Second
 	zeroInvokeInterval := time.Duration(0)
 	negativeInvokeInterval := -1 * time.Second
+	cooldown := 5 * time.Minute
+	zeroCooldown := time.Duration(0)
 
 	utMetas := map[string]struct {
 		Conf              CustomPluginConfig
@@ -384,6 +434,317 @@ func TestCustomPluginConfigValidate(t *testing.T) {
 			},
 			IsError: true,
 		},
+		"temporary rule cooldown": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type:               types.Temp,
+						Path:               "../plugin/test-data/ok.sh",
+						Cooldown:           &cooldown,
+						MaxEventsPerWindow: 3,
+					},
+				},
+			},
+			IsError: false,
+		},
+		"permanent rule cooldown": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type:      types.Perm,
+						Condition: "TestCondition",
+						Path:      "../plugin/test-data/ok.sh",
+						Cooldown:  &cooldown,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"zero rule cooldown": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type:     types.Temp,
+						Path:     "../plugin/test-data/ok.sh",
+						Cooldown: &zeroCooldown,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"negative max events per window": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type:               types.Temp,
+						Path:               "../plugin/test-data/ok.sh",
+						Cooldown:           &cooldown,
+						MaxEventsPerWindow: -1,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"max events per window without cooldown": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type:               types.Temp,
+						Path:               "../plugin/test-data/ok.sh",
+						MaxEventsPerWindow: 3,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"rule thresholds": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:             "../plugin/test-data/ok.sh",
+						FailureThreshold: 3,
+						SuccessThreshold: 2,
+						UnknownPolicy:    UnknownPolicyKeep,
+					},
+				},
+			},
+			IsError: false,
+		},
+		"negative failure threshold": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:             "../plugin/test-data/ok.sh",
+						FailureThreshold: -1,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"negative success threshold": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:             "../plugin/test-data/ok.sh",
+						SuccessThreshold: -1,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"unknown unknown policy": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:          "../plugin/test-data/ok.sh",
+						UnknownPolicy: "ignore",
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"temporary stream rule": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type: types.Temp,
+						Path: "../plugin/test-data/ok.sh",
+						Mode: StreamMode,
+					},
+				},
+			},
+			IsError: false,
+		},
+		"permanent stream rule with json output": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				DefaultConditions: []types.Condition{{Type: "TestCondition"}},
+				Rules: []*CustomRule{
+					{
+						Type:         types.Perm,
+						Condition:    "TestCondition",
+						Path:         "../plugin/test-data/ok.sh",
+						Mode:         StreamMode,
+						OutputFormat: JSONOutputFormat,
+					},
+				},
+			},
+			IsError: false,
+		},
+		"permanent stream rule with text output": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type: types.Perm,
+						Path: "../plugin/test-data/ok.sh",
+						Mode: StreamMode,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"stream rule with check": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type:  types.Temp,
+						Check: &CheckConfig{Kind: TCPCheck, Address: "127.0.0.1:80"},
+						Mode:  StreamMode,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"stream rule with invoke interval": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type:           types.Temp,
+						Path:           "../plugin/test-data/ok.sh",
+						Mode:           StreamMode,
+						InvokeInterval: &defaultInvokeInterval,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
+		"unknown rule mode": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path: "../plugin/test-data/ok.sh",
+						Mode: "daemon",
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "Rule:",
+			ErrorIncludesRule: true,
+		},
 		"zero rule invoke interval": {
 			Conf: CustomPluginConfig{
 				Plugin: customPluginName,
@@ -424,6 +785,183 @@ func TestCustomPluginConfigValidate(t *testing.T) {
 			ErrorContains:     "Rule:",
 			ErrorIncludesRule: true,
 		},
+		"unknown output format": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:         "../plugin/test-data/ok.sh",
+						OutputFormat: "yaml",
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "output format",
+			ErrorIncludesRule: true,
+		},
+		"json output format": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:         "../plugin/test-data/ok.sh",
+						OutputFormat: JSONOutputFormat,
+					},
+				},
+			},
+			IsError: false,
+		},
+		"nagios protocol": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:     "../plugin/test-data/ok.sh",
+						Protocol: NagiosProtocol,
+					},
+				},
+			},
+			IsError: false,
+		},
+		"nagios protocol with command check": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Protocol: NagiosProtocol,
+						Check:    &CheckConfig{Kind: CommandCheck, Command: []string{"true"}},
+					},
+				},
+			},
+			IsError: false,
+		},
+		"unknown protocol": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:     "../plugin/test-data/ok.sh",
+						Protocol: "snmp",
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "unknown protocol",
+			ErrorIncludesRule: true,
+		},
+		"nagios protocol with json output format": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:         "../plugin/test-data/ok.sh",
+						Protocol:     NagiosProtocol,
+						OutputFormat: JSONOutputFormat,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "json output format",
+			ErrorIncludesRule: true,
+		},
+		"nagios protocol with stream mode": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Type:     types.Temp,
+						Path:     "../plugin/test-data/ok.sh",
+						Protocol: NagiosProtocol,
+						Mode:     StreamMode,
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "stream mode",
+			ErrorIncludesRule: true,
+		},
+		"nagios protocol with http check": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Protocol: NagiosProtocol,
+						Check:    &CheckConfig{Kind: HTTPCheck, URL: "http://localhost:10248/healthz"},
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "command checks",
+			ErrorIncludesRule: true,
+		},
+		"unknown severity": {
+			Conf: CustomPluginConfig{
+				Plugin: customPluginName,
+				PluginGlobalConfig: pluginGlobalConfig{
+					InvokeInterval:  &defaultInvokeInterval,
+					Timeout:         &defaultGlobalTimeout,
+					MaxOutputLength: &defaultMaxOutputLength,
+					Concurrency:     &defaultConcurrency,
+				},
+				Rules: []*CustomRule{
+					{
+						Path:     "../plugin/test-data/ok.sh",
+						Severity: "fatal",
+					},
+				},
+			},
+			IsError:           true,
+			ErrorContains:     "severity",
+			ErrorIncludesRule: true,
+		},
 		"zero global invoke interval": {
 			Conf: CustomPluginConfig{
 				Plugin: customPluginName,
@@ -506,3 +1044,45 @@ func TestCustomPluginConfigValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestCustomPluginConfigValidateAll(t *testing.T) {
+	badDuration := "1x"
+	longTimeout := "1h"
+	conf := CustomPluginConfig{
+		Plugin: "unknown",
+		PluginGlobalConfig: pluginGlobalConfig{
+			TimeoutString: &badDuration,
+			Exec:          &ExecConfig{WorkingDir: "relative"},
+		},
+		DefaultConditions: []types.Condition{{Type: "A"}},
+		Rules: []*CustomRule{
+			{Type: types.Temp, Path: "../plugin/test-data/ok.sh"},
+			{Type: types.Temp, Path: "../plugin/test-data/ok.sh", InvokeIntervalString: &badDuration},
+			{Type: types.Temp, Path: "../plugin/test-data/ok.sh", TimeoutString: &longTimeout, Severity: "fatal"},
+			{Type: types.Perm, Condition: "B", Path: "../plugin/test-data/missing.sh"},
+			{Type: types.Temp, Path: "../plugin/test-data/ok.sh", Exec: &ExecConfig{Rlimits: map[string]uint64{"files": 1}}},
+		},
+	}
+
+	var paths []string
+	for _, err := range conf.ValidateAll() {
+		var configErr *util.ConfigError
+		if !errors.As(err, &configErr) {
+			t.Fatalf("expected a ConfigError, got %v", err)
+		}
+		paths = append(paths, configErr.Path)
+	}
+	expected := []string{
+		"$.plugin",
+		"$.pluginConfig.timeout",
+		"$.pluginConfig.exec",
+		"$.rules[1]",
+		"$.rules[2]",
+		"$.rules[3].path",
+		"$.rules[3].condition",
+		"$.rules[4]",
+	}
+	if !reflect.DeepEqual(expected, paths) {
+		t.Errorf("expected errors at %q, got %q", expected, paths)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// RlimitNames are the names of the resource limits a plugin can be given, see
+// setrlimit(2).
+var RlimitNames = []string{"as", "core", "cpu", "data", "fsize", "memlock", "nofile", "nproc", "stack"}
+
+// ExecConfig configures the execution of plugins, so that a buggy plugin
+// cannot exhaust the node. Only Env, EnvAllowlist and WorkingDir are
+// supported on other operating systems than Linux.
+type ExecConfig struct {
+	// UID is the user the plugin runs as. Defaults to the user of NPD.
+	UID *uint32 `json:"uid,omitempty"`
+	// GID is the group the plugin runs as. Defaults to the group of NPD. The
+	// supplementary groups of NPD are dropped when UID or GID is set.
+	GID *uint32 `json:"gid,omitempty"`
+	// EnvAllowlist is the names of the environment variables of NPD passed to
+	// the plugin. The plugin inherits the whole environment of NPD when it is
+	// not set.
+	EnvAllowlist []string `json:"env_allowlist,omitempty"`
+	// Env is the extra environment variables of the plugin. They take
+	// precedence over the ones of NPD.
+	Env map[string]string `json:"env,omitempty"`
+	// WorkingDir is the working directory of the plugin. Defaults to the one
+	// of NPD.
+	WorkingDir string `json:"working_dir,omitempty"`
+	// Rlimits are the soft and hard resource limits of the plugin by name, see
+	// RlimitNames. They are set before the plugin is executed.
+	Rlimits map[string]uint64 `json:"rlimits,omitempty"`
+	// Cgroup is the cgroup v2 the plugin is placed in.
+	Cgroup *CgroupConfig `json:"cgroup,omitempty"`
+	// NoNewPrivs sets the no_new_privs flag of the plugin, so that it cannot
+	// gain privileges, e.g. through setuid binaries.
+	NoNewPrivs *bool `json:"no_new_privs,omitempty"`
+}
+
+// CgroupConfig configures the cgroup v2 plugins are placed in.
+type CgroupConfig struct {
+	// Path is the directory of the cgroup in the cgroup v2 hierarchy, e.g.
+	// /sys/fs/cgroup/node-problem-detector/plugins. It is created if it does
+	// not exist. The plugins of the rules with the same path share the cgroup
+	// and its limits.
+	Path string `json:"path"`
+	// MemoryMaxString is the memory limit of the cgroup, a quantity such as
+	// 256Mi. The memory is not limited when it is empty.
+	MemoryMaxString string `json:"memory_max,omitempty"`
+	// CPUMaxString is the CPU limit of the cgroup in cores, a quantity such as
+	// 500m. The CPU is not limited when it is empty.
+	CPUMaxString string `json:"cpu_max,omitempty"`
+
+	// MemoryMax is the memory limit in bytes, 0 if the memory is not limited.
+	MemoryMax int64 `json:"-"`
+	// CPUMax is the CPU limit in millicores, 0 if the CPU is not limited.
+	CPUMax int64 `json:"-"`
+}
+
+// ApplyConfiguration parses the limits of the exec configuration.
+func (e *ExecConfig) ApplyConfiguration() error {
+	if e.Cgroup == nil {
+		return nil
+	}
+	if e.Cgroup.MemoryMaxString != "" {
+		q, err := resource.ParseQuantity(e.Cgroup.MemoryMaxString)
+		if err != nil {
+			return fmt.Errorf("error in parsing cgroup memory_max %q: %v", e.Cgroup.MemoryMaxString, err)
+		}
+		e.Cgroup.MemoryMax = q.Value()
+	}
+	if e.Cgroup.CPUMaxString != "" {
+		q, err := resource.ParseQuantity(e.Cgroup.CPUMaxString)
+		if err != nil {
+			return fmt.Errorf("error in parsing cgroup cpu_max %q: %v", e.Cgroup.CPUMaxString, err)
+		}
+		e.Cgroup.CPUMax = q.MilliValue()
+	}
+	return nil
+}
+
+// Validate verifies the settings of the exec configuration.
+func (e ExecConfig) Validate() error {
+	if runtime.GOOS != "linux" && (e.UID != nil || e.GID != nil || len(e.Rlimits) != 0 || e.Cgroup != nil || e.NoNewPrivs != nil) {
+		return fmt.Errorf("uid, gid, rlimits, cgroup and no_new_privs are only supported on linux")
+	}
+	if e.WorkingDir != "" && !filepath.IsAbs(e.WorkingDir) {
+		return fmt.Errorf("working_dir must be an absolute path, got %q", e.WorkingDir)
+	}
+	for _, name := range slices.Sorted(maps.Keys(e.Rlimits)) {
+		if !slices.Contains(RlimitNames, name) {
+			return fmt.Errorf("unknown rlimit %q, expected one of %v", name, RlimitNames)
+		}
+	}
+	if e.Cgroup != nil {
+		if !filepath.IsAbs(e.Cgroup.Path) {
+			return fmt.Errorf("cgroup path must be an absolute path, got %q", e.Cgroup.Path)
+		}
+		if e.Cgroup.MemoryMax < 0 {
+			return fmt.Errorf("cgroup memory_max must not be negative: %s", e.Cgroup.MemoryMaxString)
+		}
+		if e.Cgroup.CPUMax < 0 {
+			return fmt.Errorf("cgroup cpu_max must not be negative: %s", e.Cgroup.CPUMaxString)
+		}
+	}
+	return nil
+}
+
+// MergeExecConfig returns the exec configuration of a rule, whose settings
+// override the global ones. The environment variables and the rlimits are
+// merged by name. It returns nil if neither is set.
+func MergeExecConfig(global, rule *ExecConfig) *ExecConfig {
+	if global == nil {
+		return rule
+	}
+	if rule == nil {
+		return global
+	}
+	merged := *global
+	if rule.UID != nil {
+		merged.UID = rule.UID
+	}
+	if rule.GID != nil {
+		merged.GID = rule.GID
+	}
+	if rule.EnvAllowlist != nil {
+		merged.EnvAllowlist = rule.EnvAllowlist
+	}
+	if rule.WorkingDir != "" {
+		merged.WorkingDir = rule.WorkingDir
+	}
+	if rule.Cgroup != nil {
+		merged.Cgroup = rule.Cgroup
+	}
+	if rule.NoNewPrivs != nil {
+		merged.NoNewPrivs = rule.NoNewPrivs
+	}
+	merged.Env = mergeMaps(global.Env, rule.Env)
+	merged.Rlimits = mergeMaps(global.Rlimits, rule.Rlimits)
+	return &merged
+}
+
+// mergeMaps returns the entries of both maps, the ones of override taking
+// precedence.
+func mergeMaps[V any](base, override map[string]V) map[string]V {
+	if len(override) == 0 {
+		return base
+	}
+	if len(base) == 0 {
+		return override
+	}
+	merged := maps.Clone(base)
+	maps.Copy(merged, override)
+	return merged
+}
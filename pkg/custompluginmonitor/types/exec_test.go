@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExecConfigApplyConfiguration(t *testing.T) {
+	e := ExecConfig{Cgroup: &CgroupConfig{Path: "/sys/fs/cgroup/npd", MemoryMaxString: "256Mi", CPUMaxString: "500m"}}
+	if err := e.ApplyConfiguration(); err != nil {
+		t.Fatalf("Error in applying exec configuration: %v", err)
+	}
+	if e.Cgroup.MemoryMax != 256*1024*1024 || e.Cgroup.CPUMax != 500 {
+		t.Errorf("Wanted memory max 256Mi and CPU max 500m, got %d and %d", e.Cgroup.MemoryMax, e.Cgroup.CPUMax)
+	}
+
+	for desc, cgroup := range map[string]*CgroupConfig{
+		"invalid memory max": {Path: "/sys/fs/cgroup/npd", MemoryMaxString: "lots"},
+		"invalid cpu max":    {Path: "/sys/fs/cgroup/npd", CPUMaxString: "half"},
+	} {
+		e := ExecConfig{Cgroup: cgroup}
+		if err := e.ApplyConfiguration(); err == nil {
+			t.Errorf("%s: wanted an error, got nil", desc)
+		}
+	}
+}
+
+func TestExecConfigValidate(t *testing.T) {
+	for desc, test := range map[string]struct {
+		config  ExecConfig
+		isError bool
+	}{
+		"empty": {},
+		"valid": {
+			config: ExecConfig{
+				EnvAllowlist: []string{"PATH"},
+				Env:          map[string]string{"LANG": "C"},
+				WorkingDir:   "/var/lib/npd",
+				Rlimits:      map[string]uint64{"nofile": 1024, "nproc": 64},
+				Cgroup:       &CgroupConfig{Path: "/sys/fs/cgroup/npd", MemoryMax: 1 << 28, CPUMax: 500},
+			},
+		},
+		"relative working dir": {
+			config:  ExecConfig{WorkingDir: "plugins"},
+			isError: true,
+		},
+		"unknown rlimit": {
+			config:  ExecConfig{Rlimits: map[string]uint64{"files": 1024}},
+			isError: true,
+		},
+		"relative cgroup path": {
+			config:  ExecConfig{Cgroup: &CgroupConfig{Path: "npd"}},
+			isError: true,
+		},
+		"negative memory max": {
+			config:  ExecConfig{Cgroup: &CgroupConfig{Path: "/sys/fs/cgroup/npd", MemoryMax: -1}},
+			isError: true,
+		},
+	} {
+		err := test.config.Validate()
+		if test.isError != (err != nil) {
+			t.Errorf("%s: wanted error %v, got %v", desc, test.isError, err)
+		}
+	}
+}
+
+func TestMergeExecConfig(t *testing.T) {
+	uid, otherUID := uint32(1000), uint32(2000)
+	global := &ExecConfig{
+		UID:        &uid,
+		Env:        map[string]string{"LANG": "C", "TZ": "UTC"},
+		WorkingDir: "/",
+		Rlimits:    map[string]uint64{"nofile": 1024},
+	}
+	rule := &ExecConfig{
+		UID:          &otherUID,
+		EnvAllowlist: []string{"PATH"},
+		Env:          map[string]string{"TZ": "CET"},
+		Rlimits:      map[string]uint64{"nproc": 64},
+	}
+
+	if got := MergeExecConfig(nil, nil); got != nil {
+		t.Errorf("Wanted nil, got %+v", got)
+	}
+	if got := MergeExecConfig(global, nil); got != global {
+		t.Errorf("Wanted the global exec configuration, got %+v", got)
+	}
+	if got := MergeExecConfig(nil, rule); got != rule {
+		t.Errorf("Wanted the exec configuration of the rule, got %+v", got)
+	}
+	want := &ExecConfig{
+		UID:          &otherUID,
+		EnvAllowlist: []string{"PATH"},
+		Env:          map[string]string{"LANG": "C", "TZ": "CET"},
+		WorkingDir:   "/",
+		Rlimits:      map[string]uint64{"nofile": 1024, "nproc": 64},
+	}
+	if got := MergeExecConfig(global, rule); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted merged exec configuration %+v, got %+v", want, got)
+	}
+	if global.Env["TZ"] != "UTC" {
+		t.Errorf("Merging changed the global exec configuration: %+v", global)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Protocol is the convention a plugin follows to report its result through
+// its exit code and output.
+type Protocol string
+
+const (
+	// NPDProtocol is the default protocol. The exit code is the status, 0 for
+	// OK, 1 for NonOK and Unknown otherwise, and the output is the message, or
+	// the document of the json output format.
+	NPDProtocol Protocol = "npd"
+	// NagiosProtocol follows the Nagios and Monitoring Plugins guidelines. The
+	// exit code is one of the NagiosState values and the output is the message
+	// followed by optional performance data, see ParseNagiosOutput.
+	NagiosProtocol Protocol = "nagios"
+)
+
+// NagiosState is the exit code of a plugin that follows the nagios protocol.
+type NagiosState int
+
+const (
+	NagiosOK       NagiosState = 0
+	NagiosWarning  NagiosState = 1
+	NagiosCritical NagiosState = 2
+	NagiosUnknown  NagiosState = 3
+)
+
+// perfdataValue matches the value of a performance data entry, a number
+// followed by an optional unit of measurement.
+var perfdataValue = regexp.MustCompile(`^([-+]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][-+]?[0-9]+)?)([a-zA-Z%]*)$`)
+
+// ParseNagiosOutput splits the output of a nagios plugin into its message and
+// its performance data. The output is
+//
+//	TEXT OUTPUT | PERFDATA
+//	LONG TEXT LINE 1
+//	LONG TEXT LINE 2 | PERFDATA
+//	PERFDATA
+//
+// where the performance data is a space separated list of
+// 'label'=value[UOM];[warn];[crit];[min];[max]. The message is the text output
+// followed by the long text, and the performance data maps each label to its
+// value, as printed, in its unit of measurement. Values that are unknown (U)
+// are left out. Malformed entries are left out and reported by the error,
+// which does not prevent the rest of the output from being used.
+func ParseNagiosOutput(output string) (message string, perfdata map[string]float64, err error) {
+	first, long, _ := strings.Cut(output, "\n")
+	text, firstPerfdata, _ := strings.Cut(first, "|")
+	longText, longPerfdata, _ := strings.Cut(long, "|")
+
+	message = strings.TrimSpace(text)
+	if longText = strings.TrimSpace(longText); longText != "" {
+		message += "\n" + longText
+	}
+
+	var errs []error
+	for _, entry := range splitPerfdata(firstPerfdata + " " + longPerfdata) {
+		label, value, err := parsePerfdataEntry(entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		if perfdata == nil {
+			perfdata = make(map[string]float64)
+		}
+		perfdata[label] = *value
+	}
+	return message, perfdata, errors.Join(errs...)
+}
+
+// splitPerfdata splits performance data into its entries, which are separated
+// by whitespace outside of quoted labels.
+func splitPerfdata(perfdata string) []string {
+	var entries []string
+	var entry strings.Builder
+	quoted := false
+	for _, r := range perfdata {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			if entry.Len() > 0 {
+				entries = append(entries, entry.String())
+				entry.Reset()
+			}
+			continue
+		}
+		entry.WriteRune(r)
+	}
+	if entry.Len() > 0 {
+		entries = append(entries, entry.String())
+	}
+	return entries
+}
+
+// parsePerfdataEntry parses the label and value of a performance data entry.
+// The value is nil when it is unknown.
+func parsePerfdataEntry(entry string) (string, *float64, error) {
+	i := strings.LastIndex(entry, "=")
+	if i <= 0 {
+		return "", nil, fmt.Errorf("performance data %q is not label=value", entry)
+	}
+	label := entry[:i]
+	if len(label) >= 2 && strings.HasPrefix(label, "'") && strings.HasSuffix(label, "'") {
+		// Quotes in a quoted label are escaped by doubling them.
+		label = strings.ReplaceAll(label[1:len(label)-1], "''", "'")
+	}
+	if label == "" {
+		return "", nil, fmt.Errorf("performance data %q has an empty label", entry)
+	}
+	raw, _, _ := strings.Cut(entry[i+1:], ";")
+	if raw == "U" {
+		return label, nil, nil
+	}
+	match := perfdataValue.FindStringSubmatch(raw)
+	if match == nil {
+		return "", nil, fmt.Errorf("performance data %q has an invalid value %q", entry, raw)
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("performance data %q has an invalid value %q: %v", entry, raw, err)
+	}
+	return label, &value, nil
+}
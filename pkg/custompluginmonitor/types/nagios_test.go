@@ -0,0 +1,90 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNagiosOutput(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		output   string
+		message  string
+		perfdata map[string]float64
+		isError  bool
+	}{
+		{
+			name:    "text only",
+			output:  "DISK OK - free space: / 3326 MB (56%);",
+			message: "DISK OK - free space: / 3326 MB (56%);",
+		},
+		{
+			name:     "text and perfdata",
+			output:   "DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968",
+			message:  "DISK OK - free space: / 3326 MB (56%);",
+			perfdata: map[string]float64{"/": 2643},
+		},
+		{
+			name: "long text and perfdata on several lines",
+			output: "DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968\n" +
+				"/ 15272 MB (77%);\n" +
+				"/boot 68 MB (69%); | /boot=68MB;88;93;0;98\n" +
+				"/home=69357MB;253404;253409;0;253414",
+			message:  "DISK OK - free space: / 3326 MB (56%);\n/ 15272 MB (77%);\n/boot 68 MB (69%);",
+			perfdata: map[string]float64{"/": 2643, "/boot": 68, "/home": 69357},
+		},
+		{
+			name:     "units, signs and exponents",
+			output:   "LOAD OK|load1=0.5;; used=75% time=-1.5e-3s packets=12c",
+			message:  "LOAD OK",
+			perfdata: map[string]float64{"load1": 0.5, "used": 75, "time": -0.0015, "packets": 12},
+		},
+		{
+			name:     "quoted labels",
+			output:   "OK | 'free space'=10GB 'it''s'=1 'a=b'=2",
+			message:  "OK",
+			perfdata: map[string]float64{"free space": 10, "it's": 1, "a=b": 2},
+		},
+		{
+			name:     "unknown value",
+			output:   "UNKNOWN | rtt=U;100;200 loss=0%",
+			message:  "UNKNOWN",
+			perfdata: map[string]float64{"loss": 0},
+		},
+		{
+			name:     "malformed entries are left out",
+			output:   "OK | novalue rtt=fast ''=1 loss=5%",
+			message:  "OK",
+			perfdata: map[string]float64{"loss": 5},
+			isError:  true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			message, perfdata, err := ParseNagiosOutput(test.output)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.message, message)
+			assert.Equal(t, test.perfdata, perfdata)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OutputFormat is the format of the output a plugin prints.
+type OutputFormat string
+
+const (
+	// TextOutputFormat is the default format. The exit code of the plugin is
+	// the status and its output is the message.
+	TextOutputFormat OutputFormat = "text"
+	// JSONOutputFormat is a JSON document, see Output.
+	JSONOutputFormat OutputFormat = "json"
+)
+
+// Output is the document a plugin prints when its rule sets the json output format.
+type Output struct {
+	// Status is the status of the check, with the same values as the exit code
+	// of a text plugin.
+	Status *Status `json:"status"`
+	// Reason overrides the reason of the rule.
+	Reason string `json:"reason,omitempty"`
+	// Message is the message of the problem.
+	Message string `json:"message,omitempty"`
+	// Conditions are the sub-conditions the plugin reports in addition to the
+	// condition of the rule.
+	Conditions []ConditionOutput `json:"conditions,omitempty"`
+	// Metrics are numeric values recorded under the name of the rule.
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// ConditionOutput is a sub-condition in the Output of a plugin.
+type ConditionOutput struct {
+	// Type is the type of the condition. It must be one of the default
+	// conditions of the custom plugin monitor.
+	Type string `json:"type"`
+	// Status is the status of the condition.
+	Status *Status `json:"status"`
+	// Reason is the reason of the condition. Defaults to the reason of the result.
+	Reason string `json:"reason,omitempty"`
+	// Message is the message of the condition.
+	Message string `json:"message,omitempty"`
+}
+
+// ConditionResult is a sub-condition in the Result of a plugin.
+type ConditionResult struct {
+	Type    string
+	Status  Status
+	Reason  string
+	Message string
+}
+
+// ParseOutput parses the Output printed by a plugin.
+func ParseOutput(output string) (*Output, error) {
+	var out Output
+	if err := json.Unmarshal([]byte(output), &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal output: %v", err)
+	}
+	if err := validateStatus(out.Status); err != nil {
+		return nil, err
+	}
+	for _, condition := range out.Conditions {
+		if condition.Type == "" {
+			return nil, fmt.Errorf("condition type must not be empty")
+		}
+		if err := validateStatus(condition.Status); err != nil {
+			return nil, fmt.Errorf("invalid condition %q: %v", condition.Type, err)
+		}
+	}
+	return &out, nil
+}
+
+func validateStatus(status *Status) error {
+	if status == nil {
+		return fmt.Errorf("status is missing")
+	}
+	switch *status {
+	case OK, NonOK, Unknown:
+		return nil
+	default:
+		return fmt.Errorf("status %d is not one of %d, %d and %d", *status, OK, NonOK, Unknown)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutput(t *testing.T) {
+	nonOK := NonOK
+	ok := OK
+	for _, test := range []struct {
+		name     string
+		output   string
+		expected *Output
+	}{
+		{
+			name:     "status only",
+			output:   `{"status": 0}`,
+			expected: &Output{Status: &ok},
+		},
+		{
+			name: "full document",
+			output: `{"status": 1, "reason": "DiskSlow", "message": "sda is slow",
+				"conditions": [{"type": "DiskPressure", "status": 0}],
+				"metrics": {"latency_seconds": 0.25}}`,
+			expected: &Output{
+				Status:     &nonOK,
+				Reason:     "DiskSlow",
+				Message:    "sda is slow",
+				Conditions: []ConditionOutput{{Type: "DiskPressure", Status: &ok}},
+				Metrics:    map[string]float64{"latency_seconds": 0.25},
+			},
+		},
+		{name: "not json", output: "OK"},
+		{name: "missing status", output: `{"message": "hello"}`},
+		{name: "undefined status", output: `{"status": 3}`},
+		{name: "condition without type", output: `{"status": 0, "conditions": [{"status": 0}]}`},
+		{name: "condition without status", output: `{"status": 0, "conditions": [{"type": "DiskPressure"}]}`},
+		{name: "non numeric metric", output: `{"status": 0, "metrics": {"latency_seconds": "slow"}}`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := ParseOutput(test.output)
+			if test.expected == nil {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, out)
+		})
+	}
+}
@@ -30,11 +30,45 @@ const (
 	Unknown Status = 2
 )
 
+// Mode is how the plugin of a rule is run.
+type Mode string
+
+const (
+	// PeriodicMode is the default mode. The plugin is invoked at the invoke
+	// interval of the rule, and its exit code and output are the result.
+	PeriodicMode Mode = "periodic"
+	// StreamMode starts the plugin once and restarts it when it exits. Every
+	// line, or JSON document with the json output format, the plugin prints
+	// is a result.
+	StreamMode Mode = "stream"
+)
+
+// UnknownPolicy is how the Unknown results of a rule update its conditions.
+type UnknownPolicy string
+
+const (
+	// UnknownPolicyUnknown is the default policy. The condition becomes
+	// Unknown.
+	UnknownPolicyUnknown UnknownPolicy = "unknown"
+	// UnknownPolicyKeep keeps the condition as it is.
+	UnknownPolicyKeep UnknownPolicy = "keep"
+	// UnknownPolicyFailure counts the result as a NonOK result.
+	UnknownPolicyFailure UnknownPolicy = "failure"
+)
+
 // Result is the custom plugin check result returned by plugin.
 type Result struct {
 	Rule       *CustomRule
 	ExitStatus Status
 	Message    string
+	// Reason overrides the reason of the rule when the plugin reports one.
+	Reason string
+	// Severity overrides the severity of the rule when the plugin reports one.
+	Severity types.Severity
+	// Conditions are the sub-conditions the plugin reports.
+	Conditions []ConditionResult
+	// Metrics are the numeric values the plugin reports.
+	Metrics map[string]float64
 }
 
 // CustomRule describes how custom plugin monitor should invoke and analyze plugins.
@@ -47,8 +81,27 @@ type CustomRule struct {
 	Condition string `json:"condition"`
 	// Reason is the short reason of the problem.
 	Reason string `json:"reason"`
+	// Severity is the severity of the problem: info, warning, error or critical.
+	// Events default to warning, and the problem metrics have no severity
+	// label when it is empty.
+	Severity string `json:"severity,omitempty"`
+	// Name is the name of the rule, which labels the metrics it reports.
+	// Defaults to the reason, see MetricName.
+	Name string `json:"name,omitempty"`
 	// Path is the path to the custom plugin.
 	Path string `json:"path"`
+	// Check is the built-in check the rule runs instead of the custom plugin.
+	Check *CheckConfig `json:"check,omitempty"`
+	// Mode is how the plugin is run, periodic or stream. Defaults to periodic.
+	Mode Mode `json:"mode,omitempty"`
+	// Exec is the execution configuration of the plugin, which overrides the
+	// global one.
+	Exec *ExecConfig `json:"exec,omitempty"`
+	// Protocol is the convention the plugin follows to report its result, npd
+	// or nagios. Defaults to npd.
+	Protocol Protocol `json:"protocol,omitempty"`
+	// OutputFormat is the format of the plugin output, text or json. Defaults to text.
+	OutputFormat OutputFormat `json:"output_format,omitempty"`
 	// Args is the args passed to the custom plugin.
 	Args []string `json:"args"`
 	// Timeout is the timeout string for the custom plugin to execute.
@@ -59,4 +112,75 @@ type CustomRule struct {
 	InvokeIntervalString *string `json:"invoke_interval,omitempty"`
 	// InvokeInterval is the interval at which the plugin will be invoked.
 	InvokeInterval *time.Duration `json:"-"`
+	// CooldownString is the duration string of the window in which at most
+	// MaxEventsPerWindow events of a temporary rule are emitted. The events
+	// suppressed in a window are rolled up into a summary event once the
+	// window elapses, and still counted by the problem_counter metric.
+	CooldownString *string `json:"cooldown,omitempty"`
+	// Cooldown is the duration of the event window of the rule.
+	Cooldown *time.Duration `json:"-"`
+	// MaxEventsPerWindow is the maximum number of events emitted per cooldown
+	// window, 1 by default. It is only supported with cooldown.
+	MaxEventsPerWindow int `json:"max_events_per_window,omitempty"`
+	// FailureThreshold is the number of consecutive NonOK results after which
+	// a condition of the rule becomes True. Defaults to 1.
+	FailureThreshold int `json:"failure_threshold,omitempty"`
+	// SuccessThreshold is the number of consecutive OK results after which a
+	// True or Unknown condition of the rule becomes False. Defaults to 1.
+	SuccessThreshold int `json:"success_threshold,omitempty"`
+	// UnknownPolicy is how the Unknown results of the rule update its
+	// conditions. Defaults to UnknownPolicyUnknown.
+	UnknownPolicy UnknownPolicy `json:"unknown_policy,omitempty"`
+}
+
+// IsStream returns whether the plugin of the rule is a long-running stream.
+func (r *CustomRule) IsStream() bool {
+	return r.Mode == StreamMode
+}
+
+// IsNagios returns whether the plugin of the rule follows the nagios protocol.
+func (r *CustomRule) IsNagios() bool {
+	return r.Protocol == NagiosProtocol
+}
+
+// ProblemSeverity returns the configured severity of the rule, empty if it has none.
+func (r *CustomRule) ProblemSeverity() types.Severity {
+	// The severity was validated with the configuration.
+	severity, _ := types.ParseSeverity(r.Severity)
+	return severity
+}
+
+// MetricName returns the name the metrics reported by the rule are recorded under.
+func (r *CustomRule) MetricName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Reason
+}
+
+// FailuresToTrigger returns the number of consecutive NonOK results after
+// which a condition of the rule becomes True.
+func (r *CustomRule) FailuresToTrigger() int {
+	if r.FailureThreshold == 0 {
+		return 1
+	}
+	return r.FailureThreshold
+}
+
+// SuccessesToRecover returns the number of consecutive OK results after which
+// a condition of the rule becomes False.
+func (r *CustomRule) SuccessesToRecover() int {
+	if r.SuccessThreshold == 0 {
+		return 1
+	}
+	return r.SuccessThreshold
+}
+
+// EventsPerWindow returns the maximum number of events emitted per cooldown
+// window of the rule.
+func (r *CustomRule) EventsPerWindow() int {
+	if r.MaxEventsPerWindow == 0 {
+		return 1
+	}
+	return r.MaxEventsPerWindow
 }
@@ -17,11 +17,14 @@ limitations under the License.
 package prometheusexporter
 
 import (
+	"maps"
 	"net"
 	"net/http"
 	"strconv"
+	"sync"
 
 	"contrib.go.opencensus.io/exporter/prometheus"
+	prom "github.com/prometheus/client_golang/prometheus"
 	"go.opencensus.io/stats/view"
 	"k8s.io/klog/v2"
 
@@ -29,7 +32,25 @@ import (
 	"k8s.io/node-problem-detector/pkg/types"
 )
 
-type prometheusExporter struct{}
+// conditionKey identifies a condition reported by a problem daemon.
+type conditionKey struct {
+	source        string
+	conditionType string
+}
+
+type prometheusExporter struct {
+	// conditionGauge is 1 for the current reason and status of each condition.
+	conditionGauge *prom.GaugeVec
+	// transitionGauge is the last transition time of each condition.
+	transitionGauge *prom.GaugeVec
+	// eventCounter counts the events reported by problem daemons.
+	eventCounter *prom.CounterVec
+
+	mu sync.Mutex
+	// conditions are the labels of the current condition series, so that the
+	// series can be deleted when the reason or status of a condition changes.
+	conditions map[conditionKey]prom.Labels
+}
 
 // NewExporterOrDie creates an exporter to export metrics to Prometheus, panics if error occurs.
 func NewExporterOrDie(npdo *options.NodeProblemDetectorOptions) types.Exporter {
@@ -38,10 +59,15 @@ func NewExporterOrDie(npdo *options.NodeProblemDetectorOptions) types.Exporter {
 	}
 
 	addr := net.JoinHostPort(npdo.PrometheusServerAddress, strconv.Itoa(npdo.PrometheusServerPort))
-	pe, err := prometheus.NewExporter(prometheus.Options{})
+	registry := prom.NewRegistry()
+	pe, err := prometheus.NewExporter(prometheus.Options{Registry: registry})
 	if err != nil {
 		klog.Fatalf("Failed to create Prometheus exporter: %v", err)
 	}
+	exporter, err := newPrometheusExporter(registry)
+	if err != nil {
+		klog.Fatalf("Failed to register Prometheus problem metrics: %v", err)
+	}
 	go func() {
 		mux := http.NewServeMux()
 		mux.Handle("/metrics", pe)
@@ -50,10 +76,59 @@ func NewExporterOrDie(npdo *options.NodeProblemDetectorOptions) types.Exporter {
 		}
 	}()
 	view.RegisterExporter(pe)
-	return &prometheusExporter{}
+	return exporter
 }
 
-// ExportProblems does nothing.
-// Prometheus exporter only exports metrics.
+// newPrometheusExporter creates the condition and event metrics and registers
+// them with the registerer.
+func newPrometheusExporter(registerer prom.Registerer) (*prometheusExporter, error) {
+	pe := &prometheusExporter{
+		conditionGauge: prom.NewGaugeVec(prom.GaugeOpts{
+			Name: "npd_node_condition",
+			Help: "Current reason, status and severity of a node condition reported by node problem detector. The value is always 1.",
+		}, []string{"type", "reason", "status", "severity", "source"}),
+		transitionGauge: prom.NewGaugeVec(prom.GaugeOpts{
+			Name: "npd_node_condition_last_transition_timestamp_seconds",
+			Help: "Unix time of the last status transition of a node condition reported by node problem detector.",
+		}, []string{"type", "source"}),
+		eventCounter: prom.NewCounterVec(prom.CounterOpts{
+			Name: "npd_events_total",
+			Help: "Number of events reported by node problem detector.",
+		}, []string{"source", "reason", "severity"}),
+		conditions: make(map[conditionKey]prom.Labels),
+	}
+	for _, collector := range []prom.Collector{pe.conditionGauge, pe.transitionGauge, pe.eventCounter} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return pe, nil
+}
+
+// ExportProblems publishes the conditions and counts the events of the status.
 func (pe *prometheusExporter) ExportProblems(status *types.Status) {
+	for _, event := range status.Events {
+		pe.eventCounter.WithLabelValues(status.Source, event.Reason, string(event.Severity)).Inc()
+	}
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	for _, condition := range status.Conditions {
+		key := conditionKey{source: status.Source, conditionType: condition.Type}
+		labels := prom.Labels{
+			"type":     condition.Type,
+			"reason":   condition.Reason,
+			"status":   string(condition.Status),
+			"severity": string(condition.Severity),
+			"source":   status.Source,
+		}
+		if previous, ok := pe.conditions[key]; ok && !maps.Equal(previous, labels) {
+			pe.conditionGauge.Delete(previous)
+		}
+		pe.conditions[key] = labels
+		pe.conditionGauge.With(labels).Set(1)
+		if !condition.Transition.IsZero() {
+			pe.transitionGauge.WithLabelValues(condition.Type, status.Source).Set(float64(condition.Transition.UnixNano()) / 1e9)
+		}
+	}
 }
@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheusexporter
+
+import (
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// series is a metric sample flattened for comparison.
+type series struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+func gather(t *testing.T, registry *prom.Registry) []series {
+	t.Helper()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var result []series
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			value := metric.GetGauge().GetValue()
+			if metric.GetCounter() != nil {
+				value = metric.GetCounter().GetValue()
+			}
+			result = append(result, series{name: family.GetName(), labels: labels, value: value})
+		}
+	}
+	return result
+}
+
+func TestExportProblems(t *testing.T) {
+	registry := prom.NewRegistry()
+	pe, err := newPrometheusExporter(registry)
+	require.NoError(t, err)
+
+	transition := time.Unix(1700000000, 0)
+	pe.ExportProblems(&types.Status{
+		Source: "kernel-monitor",
+		Events: []types.Event{
+			{Severity: types.Warn, Reason: "OOMKilling"},
+			{Severity: types.Warn, Reason: "OOMKilling"},
+		},
+		Conditions: []types.Condition{
+			{Type: "KernelDeadlock", Status: types.False, Reason: "KernelHasNoDeadlock", Transition: transition},
+		},
+	})
+	pe.ExportProblems(&types.Status{
+		Source: "kernel-monitor",
+		Events: []types.Event{
+			{Severity: types.Info, Reason: "DockerHung"},
+		},
+		Conditions: []types.Condition{
+			{Type: "KernelDeadlock", Status: types.True, Reason: "DockerHung", Severity: types.Critical, Transition: transition.Add(time.Minute)},
+		},
+	})
+
+	assert.ElementsMatch(t, []series{
+		{
+			name:   "npd_node_condition",
+			labels: map[string]string{"type": "KernelDeadlock", "reason": "DockerHung", "status": "True", "severity": "critical", "source": "kernel-monitor"},
+			value:  1,
+		},
+		{
+			name:   "npd_node_condition_last_transition_timestamp_seconds",
+			labels: map[string]string{"type": "KernelDeadlock", "source": "kernel-monitor"},
+			value:  1700000060,
+		},
+		{
+			name:   "npd_events_total",
+			labels: map[string]string{"source": "kernel-monitor", "reason": "OOMKilling", "severity": "warn"},
+			value:  2,
+		},
+		{
+			name:   "npd_events_total",
+			labels: map[string]string{"source": "kernel-monitor", "reason": "DockerHung", "severity": "info"},
+			value:  1,
+		},
+	}, gather(t, registry))
+}
+
+func TestExportProblemsKeepsConditionsOfOtherSources(t *testing.T) {
+	registry := prom.NewRegistry()
+	pe, err := newPrometheusExporter(registry)
+	require.NoError(t, err)
+
+	for _, source := range []string{"a", "b"} {
+		pe.ExportProblems(&types.Status{
+			Source:     source,
+			Conditions: []types.Condition{{Type: "Same", Status: types.False, Reason: "Fine"}},
+		})
+	}
+
+	var sources []string
+	for _, s := range gather(t, registry) {
+		assert.Equal(t, "npd_node_condition", s.name)
+		sources = append(sources, s.labels["source"])
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, sources)
+}
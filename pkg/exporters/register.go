@@ -18,6 +18,8 @@ package exporters
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 
 	"k8s.io/node-problem-detector/pkg/types"
 )
@@ -59,3 +61,17 @@ func NewExporters() []types.Exporter {
 	}
 	return exporters
 }
+
+// ValidateConfigs validates the configurations of all exporters which support
+// validation, without creating the exporters.
+func ValidateConfigs() []error {
+	var errs []error
+	for _, exporterType := range slices.Sorted(maps.Keys(handlers)) {
+		handler := handlers[exporterType]
+		if handler.ValidateConfig == nil {
+			continue
+		}
+		errs = append(errs, handler.ValidateConfig(handler.Options)...)
+	}
+	return errs
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"k8s.io/node-problem-detector/pkg/exporters/stackdriver/gce"
+	"k8s.io/node-problem-detector/pkg/util"
 )
 
 var (
@@ -54,3 +56,19 @@ func (sec *StackdriverExporterConfig) ApplyConfiguration() {
 		sec.APIEndpoint = defaultEndpoint
 	}
 }
+
+// Validate verifies the durations of the configuration, and returns every
+// invalid one as a util.ConfigError.
+func (sec *StackdriverExporterConfig) Validate() []error {
+	var errs []error
+	for _, d := range []struct{ name, value string }{
+		{"exportPeriod", sec.ExportPeriod},
+		{"metadataFetchTimeout", sec.MetadataFetchTimeout},
+		{"metadataFetchInterval", sec.MetadataFetchInterval},
+	} {
+		if _, err := time.ParseDuration(d.value); err != nil {
+			errs = append(errs, util.NewConfigError("$."+d.name, fmt.Errorf("failed to parse %s %q: %v", d.name, d.value, err)))
+		}
+	}
+	return errs
+}
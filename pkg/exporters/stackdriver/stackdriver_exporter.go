@@ -18,9 +18,11 @@ package stackdriverexporter
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"time"
 
 	"contrib.go.opencensus.io/exporter/stackdriver"
@@ -28,12 +30,14 @@ import (
 	"github.com/avast/retry-go/v4"
 	"github.com/spf13/pflag"
 	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 	"google.golang.org/api/option"
 	"k8s.io/klog/v2"
 
 	"k8s.io/node-problem-detector/pkg/exporters"
 	seconfig "k8s.io/node-problem-detector/pkg/exporters/stackdriver/config"
 	"k8s.io/node-problem-detector/pkg/types"
+	"k8s.io/node-problem-detector/pkg/util"
 	"k8s.io/node-problem-detector/pkg/util/metrics"
 )
 
@@ -41,6 +45,7 @@ func init() {
 	clo := commandLineOptions{}
 	exporters.Register(exporterName, types.ExporterHandler{
 		CreateExporterOrDie: NewExporterOrDie,
+		ValidateConfig:      ValidateConfig,
 		Options:             &clo,
 	})
 }
@@ -95,9 +100,102 @@ var NPDMetricToSDMetric = map[metrics.MetricID]string{
 	metrics.NetDevTxCompressed:      "kubernetes.io/internal/node/guest/net/tx_compressed",
 }
 
+// sdProblemMetricLabels are the labels of the Stackdriver metrics the problem
+// metrics are exported as. The other labels of the problem metrics, e.g.
+// severity, are dropped from these metrics.
+var sdProblemMetricLabels = map[metrics.MetricID][]string{
+	metrics.ProblemCounterID: {"reason"},
+	metrics.ProblemGaugeID:   {"type", "reason"},
+}
+
+// severityProblemMetricNames are the names of the custom metrics the problem
+// metrics are also exported as, with all their labels.
+var severityProblemMetricNames = map[metrics.MetricID]string{
+	metrics.ProblemCounterID: "problem_counter_by_severity",
+	metrics.ProblemGaugeID:   "problem_gauge_by_severity",
+}
+
+// problemMetricExporter drops the labels of the problem metrics the
+// Stackdriver metrics don't have before exporting them. With exportSeverity,
+// it also exports the problem metrics with all their labels as custom
+// metrics.
+type problemMetricExporter struct {
+	view.Exporter
+	exportSeverity bool
+}
+
+func (e problemMetricExporter) ExportView(vd *view.Data) {
+	e.Exporter.ExportView(dropProblemMetricLabels(vd))
+	if !e.exportSeverity {
+		return
+	}
+	if severityData := severityProblemMetric(vd); severityData != nil {
+		e.Exporter.ExportView(severityData)
+	}
+}
+
+// severityProblemMetric returns the view data of a problem metric with all its
+// labels, renamed to the custom metric it is exported as, or nil if the view
+// is not a problem metric.
+func severityProblemMetric(vd *view.Data) *view.Data {
+	metricID, ok := metrics.MetricMap.ViewNameToMetricID(vd.View.Name)
+	if !ok {
+		return nil
+	}
+	name, ok := severityProblemMetricNames[metricID]
+	if !ok {
+		return nil
+	}
+	v := *vd.View
+	v.Name = name
+	return &view.Data{View: &v, Start: vd.Start, End: vd.End, Rows: vd.Rows}
+}
+
+// dropProblemMetricLabels returns the view data of a problem metric without
+// the labels the Stackdriver metric doesn't have. The rows which only differ
+// in the dropped labels are merged: counters are summed, and gauges take the
+// largest value.
+func dropProblemMetricLabels(vd *view.Data) *view.Data {
+	metricID, ok := metrics.MetricMap.ViewNameToMetricID(vd.View.Name)
+	if !ok {
+		return vd
+	}
+	labels, ok := sdProblemMetricLabels[metricID]
+	if !ok {
+		return vd
+	}
+	keep := func(key tag.Key) bool { return slices.Contains(labels, key.Name()) }
+
+	v := *vd.View
+	v.TagKeys = slices.DeleteFunc(slices.Clone(vd.View.TagKeys), func(key tag.Key) bool { return !keep(key) })
+	if len(v.TagKeys) == len(vd.View.TagKeys) {
+		return vd
+	}
+	var rows []*view.Row
+	for _, row := range vd.Rows {
+		tags := slices.DeleteFunc(slices.Clone(row.Tags), func(t tag.Tag) bool { return !keep(t.Key) })
+		i := slices.IndexFunc(rows, func(r *view.Row) bool { return slices.Equal(r.Tags, tags) })
+		if i < 0 {
+			rows = append(rows, &view.Row{Tags: tags, Data: row.Data})
+			continue
+		}
+		switch merged := rows[i].Data.(type) {
+		case *view.SumData:
+			if data, ok := row.Data.(*view.SumData); ok {
+				rows[i].Data = &view.SumData{Value: merged.Value + data.Value}
+			}
+		case *view.LastValueData:
+			if data, ok := row.Data.(*view.LastValueData); ok && data.Value > merged.Value {
+				rows[i].Data = data
+			}
+		}
+	}
+	return &view.Data{View: &v, Start: vd.Start, End: vd.End, Rows: rows}
+}
+
 func getMetricTypeConversionFunction(customMetricPrefix string) func(*view.View) string {
 	return func(view *view.View) string {
-		viewName := view.Measure.Name()
+		viewName := view.Name
 
 		fallbackMetricType := ""
 		if customMetricPrefix != "" {
@@ -148,7 +246,12 @@ func (se *stackdriverExporter) setupOpenCensusViewExporterOrDie() {
 	}
 
 	view.SetReportingPeriod(exportPeriod)
-	view.RegisterExporter(viewExporter)
+	// The problem metrics with severity can only be exported as custom
+	// metrics.
+	view.RegisterExporter(problemMetricExporter{
+		Exporter:       viewExporter,
+		exportSeverity: se.config.CustomMetricPrefix != "",
+	})
 }
 
 func (se *stackdriverExporter) populateMetadataOrDie() {
@@ -227,3 +330,29 @@ func NewExporterOrDie(clo types.CommandLineOptions) types.Exporter {
 
 	return &se
 }
+
+// ValidateConfig validates the configuration file of the Stackdriver exporter
+// without creating the exporter. The errors are prefixed with the file path.
+func ValidateConfig(clo types.CommandLineOptions) []error {
+	options, ok := clo.(*commandLineOptions)
+	if !ok {
+		return []error{fmt.Errorf("wrong type for the command line options of Stackdriver Exporter: %s", reflect.TypeOf(clo))}
+	}
+	if options.configPath == "" {
+		return nil
+	}
+	f, err := os.ReadFile(options.configPath)
+	if err != nil {
+		return []error{err}
+	}
+	var config seconfig.StackdriverExporterConfig
+	if err := util.UnmarshalConfig(f, &config); err != nil {
+		return []error{fmt.Errorf("%s: %w", options.configPath, err)}
+	}
+	config.ApplyConfiguration()
+	var errs []error
+	for _, err := range config.Validate() {
+		errs = append(errs, fmt.Errorf("%s: %w", options.configPath, err))
+	}
+	return errs
+}
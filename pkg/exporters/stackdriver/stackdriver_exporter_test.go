@@ -22,8 +22,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 
 	"k8s.io/node-problem-detector/pkg/exporters"
+	"k8s.io/node-problem-detector/pkg/util/metrics"
 )
 
 func TestRegistration(t *testing.T) {
@@ -31,3 +36,87 @@ func TestRegistration(t *testing.T) {
 		func() { exporters.GetExporterHandlerOrDie(exporterName) },
 		"Stackdriver exporter failed to register itself as an exporter.")
 }
+
+func TestDropProblemMetricLabels(t *testing.T) {
+	metrics.MetricMap.AddMapping(metrics.ProblemCounterID, string(metrics.ProblemCounterID))
+	metrics.MetricMap.AddMapping(metrics.ProblemGaugeID, string(metrics.ProblemGaugeID))
+	reason, _ := tag.NewKey("reason")
+	severity, _ := tag.NewKey("severity")
+	problemType, _ := tag.NewKey("type")
+
+	counter := &view.Data{
+		View: &view.View{Name: string(metrics.ProblemCounterID), TagKeys: []tag.Key{reason, severity}, Aggregation: view.Sum()},
+		Rows: []*view.Row{
+			{Tags: []tag.Tag{{Key: reason, Value: "OOMKilling"}}, Data: &view.SumData{Value: 1}},
+			{Tags: []tag.Tag{{Key: reason, Value: "OOMKilling"}, {Key: severity, Value: "error"}}, Data: &view.SumData{Value: 2}},
+			{Tags: []tag.Tag{{Key: reason, Value: "TaskHung"}, {Key: severity, Value: "error"}}, Data: &view.SumData{Value: 3}},
+		},
+	}
+	got := dropProblemMetricLabels(counter)
+	assert.Equal(t, []tag.Key{reason}, got.View.TagKeys)
+	assert.Equal(t, []*view.Row{
+		{Tags: []tag.Tag{{Key: reason, Value: "OOMKilling"}}, Data: &view.SumData{Value: 3}},
+		{Tags: []tag.Tag{{Key: reason, Value: "TaskHung"}}, Data: &view.SumData{Value: 3}},
+	}, got.Rows)
+	assert.Equal(t, []tag.Key{reason, severity}, counter.View.TagKeys, "the exported view must not change")
+
+	gauge := &view.Data{
+		View: &view.View{Name: string(metrics.ProblemGaugeID), TagKeys: []tag.Key{reason, severity, problemType}, Aggregation: view.LastValue()},
+		Rows: []*view.Row{
+			{Tags: []tag.Tag{{Key: reason, Value: "KernelDeadlock"}, {Key: severity, Value: "error"}, {Key: problemType, Value: "KernelDeadlock"}}, Data: &view.LastValueData{Value: 0}},
+			{Tags: []tag.Tag{{Key: reason, Value: "KernelDeadlock"}, {Key: severity, Value: "critical"}, {Key: problemType, Value: "KernelDeadlock"}}, Data: &view.LastValueData{Value: 1}},
+		},
+	}
+	got = dropProblemMetricLabels(gauge)
+	assert.Equal(t, []tag.Key{reason, problemType}, got.View.TagKeys)
+	assert.Equal(t, []*view.Row{
+		{Tags: []tag.Tag{{Key: reason, Value: "KernelDeadlock"}, {Key: problemType, Value: "KernelDeadlock"}}, Data: &view.LastValueData{Value: 1}},
+	}, got.Rows)
+
+	other := &view.Data{View: &view.View{Name: "disk/io_time", TagKeys: []tag.Key{severity}}}
+	assert.Same(t, other, dropProblemMetricLabels(other))
+}
+
+type fakeViewExporter struct {
+	exported []*view.Data
+}
+
+func (e *fakeViewExporter) ExportView(vd *view.Data) {
+	e.exported = append(e.exported, vd)
+}
+
+func TestProblemMetricExporterExportsSeverity(t *testing.T) {
+	metrics.MetricMap.AddMapping(metrics.ProblemCounterID, string(metrics.ProblemCounterID))
+	reason, _ := tag.NewKey("reason")
+	severity, _ := tag.NewKey("severity")
+	measure := stats.Int64(string(metrics.ProblemCounterID), "", "1")
+	counter := &view.Data{
+		View: &view.View{Name: string(metrics.ProblemCounterID), Measure: measure, TagKeys: []tag.Key{reason, severity}, Aggregation: view.Sum()},
+		Rows: []*view.Row{
+			{Tags: []tag.Tag{{Key: reason, Value: "OOMKilling"}, {Key: severity, Value: "info"}}, Data: &view.SumData{Value: 1}},
+			{Tags: []tag.Tag{{Key: reason, Value: "OOMKilling"}, {Key: severity, Value: "critical"}}, Data: &view.SumData{Value: 2}},
+		},
+	}
+	other := &view.Data{View: &view.View{Name: "disk/io_time", TagKeys: []tag.Key{severity}}}
+	toMetricType := getMetricTypeConversionFunction("custom.googleapis.com/npd")
+
+	fake := &fakeViewExporter{}
+	e := problemMetricExporter{Exporter: fake, exportSeverity: true}
+	e.ExportView(counter)
+	e.ExportView(other)
+	require.Len(t, fake.exported, 3)
+	assert.Equal(t, []tag.Key{reason}, fake.exported[0].View.TagKeys)
+	assert.Equal(t, "compute.googleapis.com/guest/system/problem_count", toMetricType(fake.exported[0].View))
+	assert.Equal(t, "problem_counter_by_severity", fake.exported[1].View.Name)
+	assert.Equal(t, []tag.Key{reason, severity}, fake.exported[1].View.TagKeys)
+	assert.Equal(t, counter.Rows, fake.exported[1].Rows)
+	assert.Equal(t, "custom.googleapis.com/npd/problem_counter_by_severity", toMetricType(fake.exported[1].View))
+	assert.Same(t, other, fake.exported[2])
+	assert.Equal(t, string(metrics.ProblemCounterID), counter.View.Name, "the exported view must not change")
+
+	fake = &fakeViewExporter{}
+	e = problemMetricExporter{Exporter: fake}
+	e.ExportView(counter)
+	require.Len(t, fake.exported, 1)
+	assert.Equal(t, []tag.Key{reason}, fake.exported[0].View.TagKeys)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logreplay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+var (
+	// kmsgRecordRegexp matches a record read from /dev/kmsg, e.g.
+	// "6,339,5140900,-;message", capturing its priority, sequence number,
+	// microseconds since boot and message.
+	kmsgRecordRegexp = regexp.MustCompile(`^(\d+),(\d+),(\d+),[^;]*;(.*)$`)
+	// dmesgLineRegexp matches a line printed by dmesg, e.g.
+	// "<6>[  5.140900] message", capturing its optional raw priority printed
+	// by `dmesg -r`, seconds since boot and message.
+	dmesgLineRegexp = regexp.MustCompile(`^(?:<(\d+)>)?\[\s*(\d+\.\d+)\]\s?(.*)$`)
+	// dmesgISOLineRegexp matches a line printed by `dmesg --time-format iso`,
+	// e.g. "2024-01-02T03:04:05,123456+00:00 message", capturing its optional
+	// raw priority, timestamp and message.
+	dmesgISOLineRegexp = regexp.MustCompile(`^(?:<(\d+)>)?(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d,\d+[+-]\d\d:\d\d)\s?(.*)$`)
+)
+
+// dmesgISOTimestampFormat is the format of the timestamps printed by
+// `dmesg --time-format iso`.
+const dmesgISOTimestampFormat = "2006-01-02T15:04:05,999999999-07:00"
+
+// readDmesg reads the kernel log dumped by dmesg, or read from /dev/kmsg, and
+// translates it like the kmsg watcher. The timestamps relative to boot are
+// added to the boot time. The fields of the logs are only known when the dump
+// has the raw priorities, and the continuation lines of /dev/kmsg records are
+// skipped.
+func readDmesg(r io.Reader, bootTime time.Time) ([]*logtypes.Log, error) {
+	var logs []*logtypes.Log
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, " ") {
+			continue
+		}
+		log, err := translateDmesgLine(line, bootTime)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		if log.Message != "" {
+			logs = append(logs, log)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// translateDmesgLine translates a line of a kernel log dump into a log.
+func translateDmesgLine(line string, bootTime time.Time) (*logtypes.Log, error) {
+	if m := kmsgRecordRegexp.FindStringSubmatch(line); m != nil {
+		usec, err := strconv.ParseInt(m[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %v", m[3], err)
+		}
+		log := &logtypes.Log{
+			Timestamp: bootTime.Add(time.Duration(usec) * time.Microsecond),
+			Message:   strings.TrimSpace(m[4]),
+		}
+		if err := setPriorityFields(log, m[1]); err != nil {
+			return nil, err
+		}
+		log.Fields["SEQNUM"] = m[2]
+		return log, nil
+	}
+	if m := dmesgLineRegexp.FindStringSubmatch(line); m != nil {
+		seconds, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %v", m[2], err)
+		}
+		log := &logtypes.Log{
+			Timestamp: bootTime.Add(time.Duration(seconds * float64(time.Second)).Round(time.Microsecond)),
+			Message:   strings.TrimSpace(m[3]),
+		}
+		return log, setPriorityFields(log, m[1])
+	}
+	if m := dmesgISOLineRegexp.FindStringSubmatch(line); m != nil {
+		timestamp, err := time.Parse(dmesgISOTimestampFormat, m[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %v", m[2], err)
+		}
+		log := &logtypes.Log{
+			Timestamp: timestamp,
+			Message:   strings.TrimSpace(m[3]),
+		}
+		return log, setPriorityFields(log, m[1])
+	}
+	return nil, fmt.Errorf("unknown kernel log line %q, dump it with dmesg, dmesg -r or dmesg --time-format iso", line)
+}
+
+// setPriorityFields sets the syslog level and facility fields of the log from
+// its raw priority, like the kmsg watcher. It does nothing when the priority
+// is empty.
+func setPriorityFields(log *logtypes.Log, priority string) error {
+	if priority == "" {
+		return nil
+	}
+	value, err := strconv.Atoi(priority)
+	if err != nil {
+		return fmt.Errorf("invalid priority %q: %v", priority, err)
+	}
+	log.Fields = map[string]string{
+		"PRIORITY":        strconv.Itoa(value & 7),
+		"SYSLOG_FACILITY": strconv.Itoa(value >> 3),
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logreplay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+func TestReadDmesg(t *testing.T) {
+	bootTime := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		name string
+		dump string
+		want []*logtypes.Log
+	}{
+		{
+			name: "dmesg",
+			dump: "[    0.000000] Linux version 6.1\n[  100.500000] Out of memory\n",
+			want: []*logtypes.Log{
+				{Timestamp: bootTime, Message: "Linux version 6.1"},
+				{Timestamp: bootTime.Add(100500 * time.Millisecond), Message: "Out of memory"},
+			},
+		},
+		{
+			name: "dmesg with raw priorities",
+			dump: "<3>[  100.500000] Out of memory\n",
+			want: []*logtypes.Log{
+				{
+					Timestamp: bootTime.Add(100500 * time.Millisecond),
+					Message:   "Out of memory",
+					Fields:    map[string]string{"PRIORITY": "3", "SYSLOG_FACILITY": "0"},
+				},
+			},
+		},
+		{
+			name: "dmesg with iso timestamps",
+			dump: "2024-01-02T03:04:05,123456+01:00 Out of memory\n",
+			want: []*logtypes.Log{
+				{Timestamp: time.Date(2024, 1, 2, 2, 4, 5, 123456000, time.UTC), Message: "Out of memory"},
+			},
+		},
+		{
+			name: "kmsg records",
+			dump: "6,339,5140900,-;Out of memory\n SUBSYSTEM=pci\n\n12,340,5140901,c;\n",
+			want: []*logtypes.Log{
+				{
+					Timestamp: bootTime.Add(5140900 * time.Microsecond),
+					Message:   "Out of memory",
+					Fields:    map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "0", "SEQNUM": "339"},
+				},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			logs, err := readDmesg(strings.NewReader(test.dump), bootTime)
+			require.NoError(t, err)
+			require.Len(t, logs, len(test.want))
+			for i := range logs {
+				assert.True(t, test.want[i].Timestamp.Equal(logs[i].Timestamp),
+					"expected timestamp %v, got %v", test.want[i].Timestamp, logs[i].Timestamp)
+				assert.Equal(t, test.want[i].Message, logs[i].Message)
+				assert.Equal(t, test.want[i].Fields, logs[i].Fields)
+			}
+		})
+	}
+}
+
+func TestReadDmesgRejectsUnknownLines(t *testing.T) {
+	_, err := readDmesg(strings.NewReader("[    0.000000] Linux version 6.1\nJan  2 03:04:05 kernel: Out of memory\n"), time.Time{})
+	assert.ErrorContains(t, err, "line 2")
+}
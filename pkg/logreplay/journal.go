@@ -0,0 +1,125 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logreplay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+const (
+	// journalMessageField is the journal field of the log message.
+	journalMessageField = "MESSAGE"
+	// journalRealtimeField is the journal field of the time, in microseconds
+	// since epoch, at which the entry was received.
+	journalRealtimeField = "__REALTIME_TIMESTAMP"
+	// journalAddressFieldPrefix is the prefix of the journal fields addressing
+	// an entry rather than describing it, e.g. __CURSOR.
+	journalAddressFieldPrefix = "__"
+)
+
+// readJournalExport reads the journal entries exported by
+// `journalctl -o export`, and translates them like the journald watcher. The
+// entries are separated by empty lines, and each field is either a
+// "NAME=value" line, or a binary field made of the name line, its 64-bit
+// little endian size, its value and a newline.
+func readJournalExport(r io.Reader) ([]*logtypes.Log, error) {
+	var logs []*logtypes.Log
+	reader := bufio.NewReader(r)
+	fields := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		name := strings.TrimSuffix(line, "\n")
+		switch {
+		case name == "":
+			if len(fields) != 0 {
+				log, translateErr := translateJournalEntry(fields)
+				if translateErr != nil {
+					return nil, fmt.Errorf("entry %d: %v", len(logs)+1, translateErr)
+				}
+				logs = append(logs, log)
+				fields = map[string]string{}
+			}
+		case strings.Contains(name, "="):
+			name, value, _ := strings.Cut(name, "=")
+			fields[name] = value
+		default:
+			value, binaryErr := readJournalBinaryField(reader)
+			if binaryErr != nil {
+				return nil, fmt.Errorf("field %q of entry %d: %v", name, len(logs)+1, binaryErr)
+			}
+			fields[name] = value
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if len(fields) != 0 {
+		log, err := translateJournalEntry(fields)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %v", len(logs)+1, err)
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+// readJournalBinaryField reads the size, value and trailing newline of a
+// binary field.
+func readJournalBinaryField(reader *bufio.Reader) (string, error) {
+	var size uint64
+	if err := binary.Read(reader, binary.LittleEndian, &size); err != nil {
+		return "", fmt.Errorf("failed to read the size: %v", err)
+	}
+	value := make([]byte, size+1)
+	if _, err := io.ReadFull(reader, value); err != nil {
+		return "", fmt.Errorf("failed to read the value: %v", err)
+	}
+	if value[size] != '\n' {
+		return "", fmt.Errorf("missing newline after the value")
+	}
+	return string(value[:size]), nil
+}
+
+// translateJournalEntry translates the fields of a journal entry into a log.
+func translateJournalEntry(fields map[string]string) (*logtypes.Log, error) {
+	usec, err := strconv.ParseInt(fields[journalRealtimeField], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %v", journalRealtimeField, fields[journalRealtimeField], err)
+	}
+	log := &logtypes.Log{
+		Timestamp: time.UnixMicro(usec),
+		Message:   strings.TrimSpace(fields[journalMessageField]),
+		Fields:    map[string]string{},
+	}
+	for name, value := range fields {
+		if !strings.HasPrefix(name, journalAddressFieldPrefix) {
+			log.Fields[name] = value
+		}
+	}
+	return log, nil
+}
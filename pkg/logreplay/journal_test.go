@@ -0,0 +1,70 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logreplay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+func TestReadJournalExport(t *testing.T) {
+	var export bytes.Buffer
+	export.WriteString("__CURSOR=s=1\n__REALTIME_TIMESTAMP=1704164645000001\nSYSLOG_IDENTIFIER=kernel\nMESSAGE=first line \n\n")
+	export.WriteString("__REALTIME_TIMESTAMP=1704164646000000\nSYSLOG_IDENTIFIER=dockerd\nMESSAGE\n")
+	message := "multi\nline"
+	require.NoError(t, binary.Write(&export, binary.LittleEndian, uint64(len(message))))
+	export.WriteString(message + "\n")
+	export.WriteString("PRIORITY=3\n")
+
+	logs, err := readJournalExport(&export)
+	require.NoError(t, err)
+	assert.Equal(t, []*logtypes.Log{
+		{
+			Timestamp: time.UnixMicro(1704164645000001),
+			Message:   "first line",
+			Fields:    map[string]string{"SYSLOG_IDENTIFIER": "kernel", "MESSAGE": "first line "},
+		},
+		{
+			Timestamp: time.UnixMicro(1704164646000000),
+			Message:   "multi\nline",
+			Fields:    map[string]string{"SYSLOG_IDENTIFIER": "dockerd", "MESSAGE": "multi\nline", "PRIORITY": "3"},
+		},
+	}, logs)
+}
+
+func TestReadJournalExportErrors(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		export string
+	}{
+		{name: "missing timestamp", export: "MESSAGE=hello\n\n"},
+		{name: "truncated binary field", export: "__REALTIME_TIMESTAMP=1\nMESSAGE\n\x10\x00\x00\x00\x00\x00\x00\x00short\n"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := readJournalExport(strings.NewReader(test.export))
+			assert.Error(t, err)
+		})
+	}
+}
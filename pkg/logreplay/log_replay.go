@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logreplay replays captured logs through a system log monitor
+// configuration, and prints the statuses the log monitor would have reported.
+package logreplay
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"k8s.io/node-problem-detector/cmd/logreplay/options"
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor"
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/filelog"
+	watchertypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// pluginInputFormats are the default input formats of the log watcher plugins.
+var pluginInputFormats = map[string]string{
+	"filelog":  options.FileInput,
+	"journald": options.JournalInput,
+	"kmsg":     options.DmesgInput,
+}
+
+// Replay replays the captured logs through the system log monitor
+// configuration of the options, and writes the statuses it reports to out.
+func Replay(lro *options.LogReplayOptions, out io.Writer) error {
+	replayer, err := systemlogmonitor.NewReplayer(lro.ConfigPath)
+	if err != nil {
+		return err
+	}
+	logs, err := readInput(lro, replayer.WatcherConfig())
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", lro.InputPath, err)
+	}
+	statuses := replayer.Replay(logs)
+	if lro.OutputFormat == options.JSONOutput {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(statuses)
+	}
+	return writeText(out, statuses)
+}
+
+// readInput reads the captured logs in the input format of the options, which
+// defaults to the format of the log watcher plugin of the configuration.
+func readInput(lro *options.LogReplayOptions, cfg watchertypes.WatcherConfig) ([]*logtypes.Log, error) {
+	format := lro.InputFormat
+	if format == "" {
+		var ok bool
+		if format, ok = pluginInputFormats[cfg.Plugin]; !ok {
+			return nil, fmt.Errorf("no input format for log watcher plugin %q, set --input-format", cfg.Plugin)
+		}
+	}
+
+	var r io.Reader = os.Stdin
+	if lro.InputPath != "-" {
+		f, err := os.Open(lro.InputPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	if strings.HasSuffix(lro.InputPath, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	switch format {
+	case options.FileInput:
+		return filelog.ReadLogs(cfg, r, clock.RealClock{})
+	case options.JournalInput:
+		return readJournalExport(r)
+	default:
+		// The boot time was validated with the options.
+		bootTime, _ := time.Parse(time.RFC3339Nano, lro.BootTime)
+		return readDmesg(r, bootTime)
+	}
+}
+
+// writeText writes the statuses for humans: the events of each status, and
+// the conditions that changed since the previous status.
+func writeText(out io.Writer, statuses []*types.Status) error {
+	previous := map[string]types.Condition{}
+	for i, status := range statuses {
+		if _, err := fmt.Fprintf(out, "Status %d from %s:\n", i+1, status.Source); err != nil {
+			return err
+		}
+		for _, event := range status.Events {
+			if _, err := fmt.Fprintf(out, "  Event %s %s %s: %s\n",
+				event.Timestamp.Format(time.RFC3339Nano), event.Severity, event.Reason, event.Message); err != nil {
+				return err
+			}
+		}
+		for _, condition := range status.Conditions {
+			if last, ok := previous[condition.Type]; ok && last == condition {
+				continue
+			}
+			previous[condition.Type] = condition
+			severity := ""
+			if condition.Severity != "" {
+				severity = fmt.Sprintf(" (%s)", condition.Severity)
+			}
+			if _, err := fmt.Fprintf(out, "  Condition %s=%s%s %s since %s: %s\n",
+				condition.Type, condition.Status, severity, condition.Reason,
+				condition.Transition.Format(time.RFC3339Nano), condition.Message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logreplay
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/node-problem-detector/cmd/logreplay/options"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+const testConfig = `{
+  "plugin": "filelog",
+  "pluginConfig": {"format": "jsonlines"},
+  "logPath": "/var/log/app.log",
+  "source": "app-monitor",
+  "conditions": [
+    {"type": "AppDeadlock", "reason": "AppHasNoDeadlock", "message": "app has no deadlock"}
+  ],
+  "rules": [
+    {"type": "temporary", "reason": "AppPanic", "pattern": "panic: .*"},
+    {"type": "permanent", "condition": "AppDeadlock", "reason": "AppHung", "pattern": "all goroutines are asleep.*"}
+  ]
+}`
+
+const testInput = `{"time": "2024-01-02T03:04:05Z", "msg": "started"}
+{"time": "2024-01-02T03:05:05Z", "msg": "panic: nil map"}
+not a json line
+{"time": "2024-01-02T03:06:05Z", "msg": "all goroutines are asleep - deadlock!"}
+`
+
+func writeTestFiles(t *testing.T) *options.LogReplayOptions {
+	t.Helper()
+	dir := t.TempDir()
+	lro := options.NewLogReplayOptions()
+	lro.ConfigPath = filepath.Join(dir, "config.json")
+	lro.InputPath = filepath.Join(dir, "app.log")
+	lro.OutputFormat = options.TextOutput
+	lro.BootTime = "1970-01-01T00:00:00Z"
+	require.NoError(t, os.WriteFile(lro.ConfigPath, []byte(testConfig), 0644))
+	require.NoError(t, os.WriteFile(lro.InputPath, []byte(testInput), 0644))
+	return lro
+}
+
+func TestReplayText(t *testing.T) {
+	lro := writeTestFiles(t)
+	var out bytes.Buffer
+	require.NoError(t, Replay(lro, &out))
+	assert.Equal(t, `Status 1 from app-monitor:
+  Condition AppDeadlock=False AppHasNoDeadlock since 2024-01-02T03:04:05Z: app has no deadlock
+Status 2 from app-monitor:
+  Event 2024-01-02T03:05:05Z warn AppPanic: panic: nil map
+Status 3 from app-monitor:
+  Event 2024-01-02T03:06:05Z warn AppHung: Node condition AppDeadlock is now: True, reason: AppHung, message: "all goroutines are asleep - deadlock!"
+  Condition AppDeadlock=True AppHung since 2024-01-02T03:06:05Z: all goroutines are asleep - deadlock!
+`, out.String())
+}
+
+func TestReplayJSON(t *testing.T) {
+	lro := writeTestFiles(t)
+	lro.OutputFormat = options.JSONOutput
+	var out bytes.Buffer
+	require.NoError(t, Replay(lro, &out))
+
+	var statuses []types.Status
+	require.NoError(t, json.Unmarshal(out.Bytes(), &statuses))
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "AppPanic", statuses[1].Events[0].Reason)
+	assert.Equal(t, types.True, statuses[2].Conditions[0].Status)
+}
+
+func TestReplayRejectsUnknownPlugin(t *testing.T) {
+	lro := writeTestFiles(t)
+	config := []byte(`{"plugin": "custom", "source": "app-monitor"}`)
+	require.NoError(t, os.WriteFile(lro.ConfigPath, config, 0644))
+	assert.ErrorContains(t, Replay(lro, &bytes.Buffer{}), "--input-format")
+}
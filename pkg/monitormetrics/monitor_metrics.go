@@ -0,0 +1,182 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitormetrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"k8s.io/node-problem-detector/pkg/util/metrics"
+)
+
+// GlobalMonitorMetricsManager is a singleton of MonitorMetricsManager,
+// which should be used to record the self-metrics of all problem daemons.
+var GlobalMonitorMetricsManager *MonitorMetricsManager
+
+func init() {
+	GlobalMonitorMetricsManager = NewMonitorMetricsManagerOrDie()
+}
+
+// MonitorMetricsManager manages the metrics problem daemons report about
+// themselves, labelled by the source of the monitor.
+// MonitorMetricsManager is thread-safe.
+type MonitorMetricsManager struct {
+	logLinesRead      metrics.Int64MetricInterface
+	logLinesParsed    metrics.Int64MetricInterface
+	logLinesFailed    metrics.Int64MetricInterface
+	ruleMatches       metrics.Int64MetricInterface
+	statusSendLatency metrics.Float64MetricInterface
+	watcherRestarts   metrics.Int64MetricInterface
+	pluginDuration    metrics.Float64MetricInterface
+	pluginTimeouts    metrics.Int64MetricInterface
+	pluginExitCodes   metrics.Int64MetricInterface
+}
+
+func NewMonitorMetricsManagerOrDie() *MonitorMetricsManager {
+	mmm := MonitorMetricsManager{}
+
+	int64Metrics := []struct {
+		metric      *metrics.Int64MetricInterface
+		id          metrics.MetricID
+		description string
+		tagNames    []string
+	}{
+		{&mmm.logLinesRead, metrics.LogLinesReadID, "Number of log lines read by a log watcher.", []string{"source"}},
+		{&mmm.logLinesParsed, metrics.LogLinesParsedID, "Number of log lines a log watcher translated.", []string{"source"}},
+		{&mmm.logLinesFailed, metrics.LogLinesFailedID, "Number of log lines a log watcher failed to translate.", []string{"source"}},
+		{&mmm.ruleMatches, metrics.RuleMatchesID, "Number of times a rule matched.", []string{"source", "reason"}},
+		{&mmm.watcherRestarts, metrics.WatcherRestartsID, "Number of times a log watcher restarted.", []string{"source"}},
+		{&mmm.pluginTimeouts, metrics.PluginTimeoutsID, "Number of times a plugin timed out.", []string{"source", "rule"}},
+		{&mmm.pluginExitCodes, metrics.PluginExitCodesID, "Number of times a plugin exited with an exit code.", []string{"source", "rule", "exit_code"}},
+	}
+	for _, m := range int64Metrics {
+		metric, err := metrics.NewInt64Metric(m.id, string(m.id), m.description, "1", metrics.Sum, m.tagNames)
+		if err != nil {
+			klog.Fatalf("Failed to create %s metric: %v", m.id, err)
+		}
+		*m.metric = metric
+	}
+
+	var err error
+	mmm.statusSendLatency, err = metrics.NewFloat64Metric(
+		metrics.StatusSendLatencyID,
+		string(metrics.StatusSendLatencyID),
+		"Time a monitor waited to hand a status over to the problem detector.",
+		"s",
+		metrics.Distribution,
+		[]string{"source"})
+	if err != nil {
+		klog.Fatalf("Failed to create %s metric: %v", metrics.StatusSendLatencyID, err)
+	}
+
+	mmm.pluginDuration, err = metrics.NewFloat64Metric(
+		metrics.PluginDurationID,
+		string(metrics.PluginDurationID),
+		"Time a plugin or built-in check took to run.",
+		"s",
+		metrics.Distribution,
+		[]string{"source", "rule"})
+	if err != nil {
+		klog.Fatalf("Failed to create %s metric: %v", metrics.PluginDurationID, err)
+	}
+
+	return &mmm
+}
+
+// RecordLogLines increments a log line counter of the log watcher of a monitor
+// by one, e.g. GlobalMonitorMetricsManager.IncrementLogLinesRead. Log watchers
+// can't do anything about a failure to record, so it is only logged.
+func RecordLogLines(source string, increment func(source string, count int64) error) {
+	if err := increment(source, 1); err != nil {
+		klog.Errorf("Failed to update log line metrics for %q: %v", source, err)
+	}
+}
+
+// IncrementLogLinesRead increments the number of log lines read by the log watcher of a monitor.
+func (mmm *MonitorMetricsManager) IncrementLogLinesRead(source string, count int64) error {
+	if mmm.logLinesRead == nil {
+		return errors.New("log lines read counter is being incremented before initialized")
+	}
+	return mmm.logLinesRead.Record(map[string]string{"source": source}, count)
+}
+
+// IncrementLogLinesParsed increments the number of log lines the log watcher of a monitor translated.
+func (mmm *MonitorMetricsManager) IncrementLogLinesParsed(source string, count int64) error {
+	if mmm.logLinesParsed == nil {
+		return errors.New("log lines parsed counter is being incremented before initialized")
+	}
+	return mmm.logLinesParsed.Record(map[string]string{"source": source}, count)
+}
+
+// IncrementLogLinesFailed increments the number of log lines the log watcher of a monitor failed to translate.
+func (mmm *MonitorMetricsManager) IncrementLogLinesFailed(source string, count int64) error {
+	if mmm.logLinesFailed == nil {
+		return errors.New("log lines failed counter is being incremented before initialized")
+	}
+	return mmm.logLinesFailed.Record(map[string]string{"source": source}, count)
+}
+
+// IncrementRuleMatches increments the number of times a rule of a monitor matched.
+func (mmm *MonitorMetricsManager) IncrementRuleMatches(source string, reason string, count int64) error {
+	if mmm.ruleMatches == nil {
+		return errors.New("rule matches counter is being incremented before initialized")
+	}
+	return mmm.ruleMatches.Record(map[string]string{"source": source, "reason": reason}, count)
+}
+
+// ObserveStatusSendLatency records how long a monitor waited to send a status.
+func (mmm *MonitorMetricsManager) ObserveStatusSendLatency(source string, latency time.Duration) error {
+	if mmm.statusSendLatency == nil {
+		return errors.New("status send latency is being observed before initialized")
+	}
+	return mmm.statusSendLatency.Record(map[string]string{"source": source}, latency.Seconds())
+}
+
+// IncrementWatcherRestarts increments the number of times the log watcher of a monitor restarted.
+func (mmm *MonitorMetricsManager) IncrementWatcherRestarts(source string, count int64) error {
+	if mmm.watcherRestarts == nil {
+		return errors.New("watcher restarts counter is being incremented before initialized")
+	}
+	return mmm.watcherRestarts.Record(map[string]string{"source": source}, count)
+}
+
+// ObservePluginDuration records how long the plugin of a rule took to run.
+func (mmm *MonitorMetricsManager) ObservePluginDuration(source string, rule string, duration time.Duration) error {
+	if mmm.pluginDuration == nil {
+		return errors.New("plugin duration is being observed before initialized")
+	}
+	return mmm.pluginDuration.Record(map[string]string{"source": source, "rule": rule}, duration.Seconds())
+}
+
+// IncrementPluginTimeouts increments the number of times the plugin of a rule timed out.
+func (mmm *MonitorMetricsManager) IncrementPluginTimeouts(source string, rule string, count int64) error {
+	if mmm.pluginTimeouts == nil {
+		return errors.New("plugin timeouts counter is being incremented before initialized")
+	}
+	return mmm.pluginTimeouts.Record(map[string]string{"source": source, "rule": rule}, count)
+}
+
+// IncrementPluginExitCode increments the number of times the plugin of a rule exited with the exit code.
+func (mmm *MonitorMetricsManager) IncrementPluginExitCode(source string, rule string, exitCode int) error {
+	if mmm.pluginExitCodes == nil {
+		return errors.New("plugin exit codes counter is being incremented before initialized")
+	}
+	return mmm.pluginExitCodes.Record(map[string]string{"source": source, "rule": rule, "exit_code": strconv.Itoa(exitCode)}, 1)
+}
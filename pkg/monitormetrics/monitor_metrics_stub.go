@@ -0,0 +1,63 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitormetrics
+
+import (
+	"k8s.io/node-problem-detector/pkg/util/metrics"
+)
+
+// MonitorMetricsStub holds the fake metrics of a stubbed MonitorMetricsManager.
+type MonitorMetricsStub struct {
+	LogLinesRead      *metrics.FakeInt64Metric
+	LogLinesParsed    *metrics.FakeInt64Metric
+	LogLinesFailed    *metrics.FakeInt64Metric
+	RuleMatches       *metrics.FakeInt64Metric
+	StatusSendLatency *metrics.FakeFloat64Metric
+	WatcherRestarts   *metrics.FakeInt64Metric
+	PluginDuration    *metrics.FakeFloat64Metric
+	PluginTimeouts    *metrics.FakeInt64Metric
+	PluginExitCodes   *metrics.FakeInt64Metric
+}
+
+// NewMonitorMetricsManagerStub creates a MonitorMetricsManager stubbed by fake metrics.
+// The stubbed MonitorMetricsManager and fake metrics are returned.
+func NewMonitorMetricsManagerStub() (*MonitorMetricsManager, *MonitorMetricsStub) {
+	stub := MonitorMetricsStub{
+		LogLinesRead:      metrics.NewFakeInt64Metric(string(metrics.LogLinesReadID), metrics.Sum, []string{"source"}),
+		LogLinesParsed:    metrics.NewFakeInt64Metric(string(metrics.LogLinesParsedID), metrics.Sum, []string{"source"}),
+		LogLinesFailed:    metrics.NewFakeInt64Metric(string(metrics.LogLinesFailedID), metrics.Sum, []string{"source"}),
+		RuleMatches:       metrics.NewFakeInt64Metric(string(metrics.RuleMatchesID), metrics.Sum, []string{"source", "reason"}),
+		StatusSendLatency: metrics.NewFakeFloat64Metric(string(metrics.StatusSendLatencyID), metrics.Distribution, []string{"source"}),
+		WatcherRestarts:   metrics.NewFakeInt64Metric(string(metrics.WatcherRestartsID), metrics.Sum, []string{"source"}),
+		PluginDuration:    metrics.NewFakeFloat64Metric(string(metrics.PluginDurationID), metrics.Distribution, []string{"source", "rule"}),
+		PluginTimeouts:    metrics.NewFakeInt64Metric(string(metrics.PluginTimeoutsID), metrics.Sum, []string{"source", "rule"}),
+		PluginExitCodes:   metrics.NewFakeInt64Metric(string(metrics.PluginExitCodesID), metrics.Sum, []string{"source", "rule", "exit_code"}),
+	}
+
+	mmm := MonitorMetricsManager{
+		logLinesRead:      stub.LogLinesRead,
+		logLinesParsed:    stub.LogLinesParsed,
+		logLinesFailed:    stub.LogLinesFailed,
+		ruleMatches:       stub.RuleMatches,
+		statusSendLatency: stub.StatusSendLatency,
+		watcherRestarts:   stub.WatcherRestarts,
+		pluginDuration:    stub.PluginDuration,
+		pluginTimeouts:    stub.PluginTimeouts,
+		pluginExitCodes:   stub.PluginExitCodes,
+	}
+	return &mmm, &stub
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitormetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/node-problem-detector/pkg/util/metrics"
+)
+
+func TestRecordMonitorMetrics(t *testing.T) {
+	mmm, stub := NewMonitorMetricsManagerStub()
+
+	assert.NoError(t, mmm.IncrementLogLinesRead("kernel-monitor", 3))
+	assert.NoError(t, mmm.IncrementLogLinesParsed("kernel-monitor", 2))
+	assert.NoError(t, mmm.IncrementLogLinesFailed("kernel-monitor", 1))
+	assert.NoError(t, mmm.IncrementRuleMatches("kernel-monitor", "OOMKilling", 1))
+	assert.NoError(t, mmm.IncrementWatcherRestarts("kernel-monitor", 1))
+	assert.NoError(t, mmm.ObserveStatusSendLatency("kernel-monitor", 100*time.Millisecond))
+	assert.NoError(t, mmm.ObserveStatusSendLatency("kernel-monitor", 400*time.Millisecond))
+	assert.NoError(t, mmm.ObservePluginDuration("ntp-monitor", "NTPIsDown", 2*time.Second))
+	assert.NoError(t, mmm.IncrementPluginTimeouts("ntp-monitor", "NTPIsDown", 1))
+	assert.NoError(t, mmm.IncrementPluginExitCode("ntp-monitor", "NTPIsDown", 1))
+	assert.NoError(t, mmm.IncrementPluginExitCode("ntp-monitor", "NTPIsDown", 1))
+
+	source := map[string]string{"source": "kernel-monitor"}
+	rule := map[string]string{"source": "ntp-monitor", "rule": "NTPIsDown"}
+	assert.Equal(t, []metrics.Int64MetricRepresentation{{Name: string(metrics.LogLinesReadID), Labels: source, Value: 3}}, stub.LogLinesRead.ListMetrics())
+	assert.Equal(t, []metrics.Int64MetricRepresentation{{Name: string(metrics.LogLinesParsedID), Labels: source, Value: 2}}, stub.LogLinesParsed.ListMetrics())
+	assert.Equal(t, []metrics.Int64MetricRepresentation{{Name: string(metrics.LogLinesFailedID), Labels: source, Value: 1}}, stub.LogLinesFailed.ListMetrics())
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: string(metrics.RuleMatchesID), Labels: map[string]string{"source": "kernel-monitor", "reason": "OOMKilling"}, Value: 1},
+	}, stub.RuleMatches.ListMetrics())
+	assert.Equal(t, []metrics.Int64MetricRepresentation{{Name: string(metrics.WatcherRestartsID), Labels: source, Value: 1}}, stub.WatcherRestarts.ListMetrics())
+	assert.Equal(t, []metrics.Float64MetricRepresentation{{Name: string(metrics.StatusSendLatencyID), Labels: source, Value: 0.5}}, stub.StatusSendLatency.ListMetrics())
+	assert.Equal(t, []metrics.Float64MetricRepresentation{{Name: string(metrics.PluginDurationID), Labels: rule, Value: 2}}, stub.PluginDuration.ListMetrics())
+	assert.Equal(t, []metrics.Int64MetricRepresentation{{Name: string(metrics.PluginTimeoutsID), Labels: rule, Value: 1}}, stub.PluginTimeouts.ListMetrics())
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: string(metrics.PluginExitCodesID), Labels: map[string]string{"source": "ntp-monitor", "rule": "NTPIsDown", "exit_code": "1"}, Value: 2},
+	}, stub.PluginExitCodes.ListMetrics())
+}
+
+func TestRecordBeforeInitialized(t *testing.T) {
+	mmm := &MonitorMetricsManager{}
+	assert.Error(t, mmm.IncrementLogLinesRead("kernel-monitor", 1))
+	assert.Error(t, mmm.ObserveStatusSendLatency("kernel-monitor", time.Second))
+	assert.Error(t, mmm.IncrementPluginExitCode("ntp-monitor", "NTPIsDown", 0))
+}
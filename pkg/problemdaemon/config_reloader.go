@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package problemdaemon
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// ReplaceFunc stops the problem daemon created from the configuration file and
+// starts the new one in its place.
+type ReplaceFunc func(configPath string, m types.Monitor) error
+
+// ConfigReloader recreates problem daemons whose configuration files changed.
+// A problem daemon is only replaced when its new configuration is valid, so
+// a bad configuration keeps the old problem daemon running.
+type ConfigReloader struct {
+	daemons []*configuredDaemon
+	replace ReplaceFunc
+}
+
+// configuredDaemon is a problem daemon with the configuration it was created from.
+type configuredDaemon struct {
+	problemDaemonType types.ProblemDaemonType
+	configPath        string
+	// config is the last seen content of the configuration file.
+	config []byte
+}
+
+// NewConfigReloader creates a ConfigReloader for the problem daemons created by
+// NewProblemDaemonMap from the same configurations.
+func NewConfigReloader(monitorConfigPaths types.ProblemDaemonConfigPathMap, problemDaemons map[string]types.Monitor, replace ReplaceFunc) *ConfigReloader {
+	r := &ConfigReloader{replace: replace}
+	for problemDaemonType, configs := range monitorConfigPaths {
+		for _, config := range *configs {
+			if _, ok := problemDaemons[config]; !ok {
+				continue
+			}
+			content, err := os.ReadFile(config)
+			if err != nil {
+				klog.Errorf("Failed to read configuration file %q: %v", config, err)
+			}
+			r.daemons = append(r.daemons, &configuredDaemon{
+				problemDaemonType: problemDaemonType,
+				configPath:        config,
+				config:            content,
+			})
+		}
+	}
+	return r
+}
+
+// Run reloads the changed configurations every period, and whenever trigger
+// receives. A zero period disables the periodic reload.
+func (r *ConfigReloader) Run(ctx context.Context, period time.Duration, trigger <-chan os.Signal) {
+	var tick <-chan time.Time
+	if period > 0 {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			r.Reload()
+		case sig := <-trigger:
+			klog.Infof("Received %v, reloading problem daemon configurations", sig)
+			r.Reload()
+		}
+	}
+}
+
+// Reload recreates and replaces every problem daemon whose configuration file
+// content changed since it was created.
+func (r *ConfigReloader) Reload() {
+	for _, d := range r.daemons {
+		content, err := os.ReadFile(d.configPath)
+		if err != nil {
+			klog.Errorf("Failed to read configuration file %q: %v", d.configPath, err)
+			continue
+		}
+		if bytes.Equal(content, d.config) {
+			continue
+		}
+		// Remember the content so that an invalid configuration is only
+		// reported once.
+		d.config = content
+		create := handlers[d.problemDaemonType].CreateProblemDaemon
+		if create == nil {
+			klog.Warningf("Configuration file %q changed, but problem daemon %v does not support reloading", d.configPath, d.problemDaemonType)
+			continue
+		}
+		monitor, err := create(d.configPath)
+		if err != nil {
+			klog.Errorf("Keep running the old problem daemon, failed to reload configuration file %q: %v", d.configPath, err)
+			continue
+		}
+		if err := r.replace(d.configPath, monitor); err != nil {
+			klog.Errorf("Failed to replace problem daemon for configuration file %q: %v", d.configPath, err)
+			continue
+		}
+		klog.Infof("Reloaded configuration file %q", d.configPath)
+	}
+}
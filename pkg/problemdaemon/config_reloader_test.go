@@ -0,0 +1,99 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package problemdaemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// fakeMonitor records the config it was created from.
+type fakeMonitor struct {
+	config string
+}
+
+func (f *fakeMonitor) Start() (<-chan *types.Status, error) { return nil, nil }
+
+func (f *fakeMonitor) Stop() {}
+
+func TestConfigReloader(t *testing.T) {
+	defer func() {
+		handlers = make(map[types.ProblemDaemonType]types.ProblemDaemonHandler)
+	}()
+	readConfig := func(configPath string) (types.Monitor, error) {
+		content, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if string(content) == "invalid" {
+			return nil, errors.New("invalid config")
+		}
+		return &fakeMonitor{config: string(content)}, nil
+	}
+	Register("foo", types.ProblemDaemonHandler{
+		CreateProblemDaemonOrDie: func(configPath string) types.Monitor {
+			m, err := readConfig(configPath)
+			if err != nil {
+				t.Fatalf("failed to create monitor: %v", err)
+			}
+			return m
+		},
+		CreateProblemDaemon: readConfig,
+	})
+
+	configPath := filepath.Join(t.TempDir(), "foo.json")
+	writeConfig := func(content string) {
+		if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+	writeConfig("v1")
+
+	configPaths := types.ProblemDaemonConfigPathMap{"foo": &[]string{configPath}}
+	problemDaemons := NewProblemDaemonMap(configPaths)
+	var replaced []types.Monitor
+	reloader := NewConfigReloader(configPaths, problemDaemons, func(name string, m types.Monitor) error {
+		assert.Equal(t, configPath, name)
+		replaced = append(replaced, m)
+		return nil
+	})
+
+	reloader.Reload()
+	assert.Empty(t, replaced, "unchanged config should not be reloaded")
+
+	writeConfig("invalid")
+	reloader.Reload()
+	assert.Empty(t, replaced, "invalid config should keep the old monitor")
+
+	writeConfig("v2")
+	reloader.Reload()
+	if assert.Len(t, replaced, 1) {
+		assert.Equal(t, &fakeMonitor{config: "v2"}, replaced[0])
+	}
+
+	writeConfig("v3")
+	reloader.Reload()
+	if assert.Len(t, replaced, 2) {
+		assert.Equal(t, &fakeMonitor{config: "v3"}, replaced[1])
+	}
+}
@@ -18,6 +18,8 @@ package problemdaemon
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 
 	"k8s.io/klog/v2"
 
@@ -51,6 +53,16 @@ func GetProblemDaemonHandlerOrDie(problemDaemonType types.ProblemDaemonType) typ
 
 // NewProblemDaemons creates all problem daemons based on the configurations provided.
 func NewProblemDaemons(monitorConfigPaths types.ProblemDaemonConfigPathMap) []types.Monitor {
+	problemDaemons := []types.Monitor{}
+	for _, problemDaemon := range NewProblemDaemonMap(monitorConfigPaths) {
+		problemDaemons = append(problemDaemons, problemDaemon)
+	}
+	return problemDaemons
+}
+
+// NewProblemDaemonMap creates all problem daemons based on the configurations provided,
+// keyed by their configuration file paths.
+func NewProblemDaemonMap(monitorConfigPaths types.ProblemDaemonConfigPathMap) map[string]types.Monitor {
 	problemDaemonMap := make(map[string]types.Monitor)
 	for problemDaemonType, configs := range monitorConfigPaths {
 		for _, config := range *configs {
@@ -62,10 +74,42 @@ func NewProblemDaemons(monitorConfigPaths types.ProblemDaemonConfigPathMap) []ty
 			problemDaemonMap[config] = handlers[problemDaemonType].CreateProblemDaemonOrDie(config)
 		}
 	}
+	return problemDaemonMap
+}
 
-	problemDaemons := []types.Monitor{}
-	for _, problemDaemon := range problemDaemonMap {
-		problemDaemons = append(problemDaemons, problemDaemon)
+// ValidateConfigs validates the configurations of the problem daemons without
+// creating them. Every error is prefixed with the configuration file path.
+// Problem daemons which do not support validation are skipped.
+func ValidateConfigs(monitorConfigPaths types.ProblemDaemonConfigPathMap) []error {
+	var errs []error
+	for _, problemDaemonType := range slices.Sorted(maps.Keys(monitorConfigPaths)) {
+		validate := handlers[problemDaemonType].ValidateConfig
+		if validate == nil {
+			continue
+		}
+		for _, config := range *monitorConfigPaths[problemDaemonType] {
+			for _, err := range validate(config) {
+				errs = append(errs, fmt.Errorf("%s: %w", config, err))
+			}
+		}
 	}
-	return problemDaemons
+	return errs
+}
+
+// ProblemMetricLabels returns the sorted extra labels the problems of the
+// problem daemons are counted with, which must be declared before the problem
+// daemons are created.
+func ProblemMetricLabels(monitorConfigPaths types.ProblemDaemonConfigPathMap) []string {
+	var labels []string
+	for problemDaemonType, configs := range monitorConfigPaths {
+		problemMetricLabels := handlers[problemDaemonType].ProblemMetricLabels
+		if problemMetricLabels == nil {
+			continue
+		}
+		for _, config := range *configs {
+			labels = append(labels, problemMetricLabels(config)...)
+		}
+	}
+	slices.Sort(labels)
+	return slices.Compact(labels)
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package problemdaemon
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -70,3 +71,47 @@ func TestGetProblemDaemonHandlerOrDie(t *testing.T) {
 
 	handlers = make(map[types.ProblemDaemonType]types.ProblemDaemonHandler)
 }
+
+func TestValidateConfigs(t *testing.T) {
+	Register("foo", types.ProblemDaemonHandler{
+		ValidateConfig: func(configPath string) []error {
+			if configPath == "invalid.json" {
+				return []error{errors.New("first"), errors.New("second")}
+			}
+			return nil
+		},
+	})
+	Register("bar", types.ProblemDaemonHandler{})
+
+	errs := ValidateConfigs(types.ProblemDaemonConfigPathMap{
+		"foo": &[]string{"valid.json", "invalid.json"},
+		"bar": &[]string{"invalid.json"},
+	})
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{"invalid.json: first", "invalid.json: second"}, messages)
+
+	handlers = make(map[types.ProblemDaemonType]types.ProblemDaemonHandler)
+}
+
+func TestProblemMetricLabels(t *testing.T) {
+	Register("foo", types.ProblemDaemonHandler{
+		ProblemMetricLabels: func(configPath string) []string {
+			if configPath == "oom.json" {
+				return []string{"process"}
+			}
+			return []string{"unit", "process"}
+		},
+	})
+	Register("bar", types.ProblemDaemonHandler{})
+
+	labels := ProblemMetricLabels(types.ProblemDaemonConfigPathMap{
+		"foo": &[]string{"oom.json", "systemd.json"},
+		"bar": &[]string{"bar.json"},
+	})
+	assert.Equal(t, []string{"process", "unit"}, labels)
+
+	handlers = make(map[types.ProblemDaemonType]types.ProblemDaemonHandler)
+}
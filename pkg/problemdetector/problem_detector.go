@@ -19,6 +19,9 @@ package problemdetector
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"k8s.io/klog/v2"
 
@@ -28,59 +31,95 @@ import (
 // ProblemDetector collects statuses from all problem daemons and update the node condition and send node event.
 type ProblemDetector interface {
 	Run(context.Context) error
+	// ReplaceMonitor starts the new monitor and then stops the running monitor with the name.
+	ReplaceMonitor(name string, m types.Monitor) error
+	// GetMonitorHealth returns the health of every monitor, sorted by name.
+	GetMonitorHealth() []MonitorHealth
+}
+
+// MonitorHealth is the health of a monitor run by problem detector.
+type MonitorHealth struct {
+	// Name is the name of the monitor, which is its configuration file path.
+	Name string `json:"name"`
+	// Started indicates whether the monitor is started.
+	Started bool `json:"started"`
+	// Failed indicates whether the monitor failed to start.
+	Failed bool `json:"failed"`
+	// Error is the error the monitor failed to start with.
+	Error string `json:"error,omitempty"`
+	// LastStatusTime is the time when problem detector received the last status from the monitor.
+	LastStatusTime time.Time `json:"lastStatusTime,omitempty"`
 }
 
 type problemDetector struct {
-	monitors  []types.Monitor
 	exporters []types.Exporter
+	statuses  chan *types.Status
+	// mu protects running, monitors and forwarders.
+	mu sync.Mutex
+	// running reports whether the monitors are started.
+	running  bool
+	monitors map[string]types.Monitor
+	// forwarders stop forwarding the statuses of each started monitor when closed.
+	forwarders map[string]chan struct{}
+	// healthMu protects health. It is separate from mu, because the status
+	// forwarders update health while mu is held to stop a monitor.
+	healthMu sync.Mutex
+	health   map[string]*MonitorHealth
 }
 
 // NewProblemDetector creates the problem detector. Currently we just directly passed in the problem daemons, but
 // in the future we may want to let the problem daemons register themselves.
-func NewProblemDetector(monitors []types.Monitor, exporters []types.Exporter) ProblemDetector {
-	return &problemDetector{
-		monitors:  monitors,
-		exporters: exporters,
+// The problem daemons are keyed by their names.
+func NewProblemDetector(monitors map[string]types.Monitor, exporters []types.Exporter) ProblemDetector {
+	p := &problemDetector{
+		monitors:   make(map[string]types.Monitor),
+		exporters:  exporters,
+		statuses:   make(chan *types.Status),
+		forwarders: make(map[string]chan struct{}),
+		health:     make(map[string]*MonitorHealth),
 	}
+	for name, m := range monitors {
+		p.monitors[name] = m
+		p.health[name] = &MonitorHealth{Name: name}
+	}
+	return p
 }
 
 // Run starts the problem detector.
 func (p *problemDetector) Run(ctx context.Context) error {
 	// Start the log monitors one by one.
-	var chans []<-chan *types.Status
+	p.mu.Lock()
 	failureCount := 0
-	for _, m := range p.monitors {
-		ch, err := m.Start()
-		if err != nil {
+	for name, m := range p.monitors {
+		if err := p.startMonitor(name, m); err != nil {
 			// Do not return error and keep on trying the following config files.
-			klog.Errorf("Failed to start problem daemon %v: %v", m, err)
+			klog.Errorf("Failed to start problem daemon %s: %v", name, err)
 			failureCount++
-			continue
-		}
-		if ch != nil {
-			chans = append(chans, ch)
 		}
 	}
-	allMonitors := p.monitors
-
-	if len(allMonitors) == failureCount {
+	if len(p.monitors) == failureCount {
+		p.mu.Unlock()
 		return fmt.Errorf("no problem daemon is successfully setup")
 	}
+	p.running = true
+	p.mu.Unlock()
 
 	defer func() {
-		for _, m := range allMonitors {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.running = false
+		for _, m := range p.monitors {
 			m.Stop()
 		}
 	}()
 
-	ch := groupChannel(chans)
 	klog.Info("Problem detector started")
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case status := <-ch:
+		case status := <-p.statuses:
 			for _, exporter := range p.exporters {
 				exporter.ExportProblems(status)
 			}
@@ -88,14 +127,109 @@ func (p *problemDetector) Run(ctx context.Context) error {
 	}
 }
 
-func groupChannel(chans []<-chan *types.Status) <-chan *types.Status {
-	statuses := make(chan *types.Status)
-	for _, ch := range chans {
-		go func(c <-chan *types.Status) {
-			for status := range c {
-				statuses <- status
-			}
-		}(ch)
+// ReplaceMonitor starts the new monitor with the node conditions of the running
+// monitor with the name, and then stops the old monitor. If the new monitor
+// fails to start, the old monitor keeps running. When the problem detector is
+// not running, the new monitor only takes the place of the old one.
+func (p *problemDetector) ReplaceMonitor(name string, m types.Monitor) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old, ok := p.monitors[name]
+	if !p.running {
+		p.monitors[name] = m
+		p.setHealth(name, MonitorHealth{Name: name})
+		return nil
+	}
+	if !ok {
+		if err := p.startMonitor(name, m); err != nil {
+			return fmt.Errorf("failed to start problem daemon %s: %v", name, err)
+		}
+		p.monitors[name] = m
+		return nil
+	}
+
+	oldConditionMonitor, oldOK := old.(types.ConditionMonitor)
+	newConditionMonitor, newOK := m.(types.ConditionMonitor)
+	if oldOK && newOK {
+		newConditionMonitor.RestoreConditions(oldConditionMonitor.GetConditions())
+	}
+	oldStop, oldForwarding := p.forwarders[name]
+	delete(p.forwarders, name)
+	p.healthMu.Lock()
+	oldHealth := p.health[name]
+	p.healthMu.Unlock()
+	if err := p.startMonitor(name, m); err != nil {
+		// The old monitor keeps running with its forwarder and health.
+		if oldForwarding {
+			p.forwarders[name] = oldStop
+		}
+		p.healthMu.Lock()
+		p.health[name] = oldHealth
+		p.healthMu.Unlock()
+		return fmt.Errorf("failed to start problem daemon %s, keeping the old one: %v", name, err)
+	}
+	old.Stop()
+	if oldForwarding {
+		close(oldStop)
 	}
-	return statuses
+	p.monitors[name] = m
+	return nil
+}
+
+// GetMonitorHealth returns the health of every monitor, sorted by name.
+func (p *problemDetector) GetMonitorHealth() []MonitorHealth {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	health := make([]MonitorHealth, 0, len(p.health))
+	for _, h := range p.health {
+		health = append(health, *h)
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Name < health[j].Name })
+	return health
+}
+
+// setHealth replaces the health of the monitor with the name.
+func (p *problemDetector) setHealth(name string, health MonitorHealth) *MonitorHealth {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	h := &health
+	p.health[name] = h
+	return h
+}
+
+// startMonitor starts the monitor and forwards its statuses to the exporters.
+// p.mu must be held.
+func (p *problemDetector) startMonitor(name string, m types.Monitor) error {
+	ch, err := m.Start()
+	if err != nil {
+		p.setHealth(name, MonitorHealth{Name: name, Failed: true, Error: err.Error()})
+		return err
+	}
+	health := p.setHealth(name, MonitorHealth{Name: name, Started: true})
+	if ch == nil {
+		return nil
+	}
+	stop := make(chan struct{})
+	p.forwarders[name] = stop
+	go func() {
+		for {
+			select {
+			case status, ok := <-ch:
+				if !ok {
+					return
+				}
+				p.healthMu.Lock()
+				health.LastStatusTime = time.Now()
+				p.healthMu.Unlock()
+				select {
+				case p.statuses <- status:
+				case <-stop:
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return nil
 }
@@ -18,14 +18,189 @@ package problemdetector
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"k8s.io/node-problem-detector/pkg/types"
 )
 
+// fakeMonitor is a types.ConditionMonitor that reports the statuses sent to it.
+type fakeMonitor struct {
+	statuses   chan *types.Status
+	conditions []types.Condition
+	started    bool
+	stopped    bool
+}
+
+func newFakeMonitor(conditions ...types.Condition) *fakeMonitor {
+	return &fakeMonitor{statuses: make(chan *types.Status, 10), conditions: conditions}
+}
+
+func (f *fakeMonitor) Start() (<-chan *types.Status, error) {
+	f.started = true
+	return f.statuses, nil
+}
+
+func (f *fakeMonitor) Stop() {
+	f.stopped = true
+}
+
+func (f *fakeMonitor) GetConditions() []types.Condition {
+	return f.conditions
+}
+
+func (f *fakeMonitor) RestoreConditions(conditions []types.Condition) {
+	f.conditions = conditions
+}
+
+// fakeExporter forwards the exported statuses to a channel.
+type fakeExporter struct {
+	statuses chan *types.Status
+}
+
+func (f *fakeExporter) ExportProblems(status *types.Status) {
+	f.statuses <- status
+}
+
 func TestEmpty(t *testing.T) {
-	pd := NewProblemDetector([]types.Monitor{}, []types.Exporter{})
+	pd := NewProblemDetector(map[string]types.Monitor{}, []types.Exporter{})
 	if err := pd.Run(context.Background()); err == nil {
 		t.Error("expected error when running an empty problem detector")
 	}
 }
+
+func TestReplaceMonitor(t *testing.T) {
+	old := newFakeMonitor(types.Condition{Type: "A", Status: types.True})
+	exporter := &fakeExporter{statuses: make(chan *types.Status, 10)}
+	pd := NewProblemDetector(map[string]types.Monitor{"foo": old}, []types.Exporter{exporter})
+
+	// Replacing before Run only swaps the monitor that Run starts.
+	replaced := newFakeMonitor()
+	if err := pd.ReplaceMonitor("foo", replaced); err != nil {
+		t.Fatalf("unexpected error replacing a monitor before Run: %v", err)
+	}
+	if old.stopped || replaced.started {
+		t.Fatal("expected no monitor to be started or stopped before Run")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- pd.Run(ctx) }()
+
+	replaced.statuses <- &types.Status{Source: "replaced"}
+	waitForStatus(t, exporter, "replaced")
+
+	replaced.conditions = []types.Condition{{Type: "A", Status: types.True}}
+	replacement := newFakeMonitor()
+	if err := pd.ReplaceMonitor("foo", replacement); err != nil {
+		t.Fatalf("unexpected error replacing a running monitor: %v", err)
+	}
+	if !replaced.stopped || !replacement.started {
+		t.Error("expected the old monitor to be stopped and the new monitor to be started")
+	}
+	if len(replacement.conditions) != 1 || replacement.conditions[0].Status != types.True {
+		t.Errorf("expected conditions to be carried over, got %+v", replacement.conditions)
+	}
+
+	replacement.statuses <- &types.Status{Source: "replacement"}
+	waitForStatus(t, exporter, "replacement")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error from Run: %v", err)
+	}
+	if !replacement.stopped {
+		t.Error("expected the replacement monitor to be stopped when Run returns")
+	}
+}
+
+func TestReplaceMonitorKeepsOldMonitorOnFailure(t *testing.T) {
+	old := newFakeMonitor(types.Condition{Type: "A", Status: types.True})
+	exporter := &fakeExporter{statuses: make(chan *types.Status, 10)}
+	pd := NewProblemDetector(map[string]types.Monitor{"foo": old}, []types.Exporter{exporter})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- pd.Run(ctx) }()
+	old.statuses <- &types.Status{Source: "old"}
+	waitForStatus(t, exporter, "old")
+
+	if err := pd.ReplaceMonitor("foo", &failingMonitor{}); err == nil {
+		t.Error("expected an error replacing a monitor with one that fails to start")
+	}
+	if old.stopped {
+		t.Error("expected the old monitor to keep running")
+	}
+	if health := pd.GetMonitorHealth(); len(health) != 1 || !health[0].Started || health[0].Failed {
+		t.Errorf("expected the old monitor to stay healthy, got %+v", health)
+	}
+	old.statuses <- &types.Status{Source: "old"}
+	waitForStatus(t, exporter, "old")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error from Run: %v", err)
+	}
+	if !old.stopped {
+		t.Error("expected the old monitor to be stopped when Run returns")
+	}
+}
+
+func waitForStatus(t *testing.T, exporter *fakeExporter, source string) {
+	t.Helper()
+	select {
+	case status := <-exporter.statuses:
+		if status.Source != source {
+			t.Errorf("expected status from %q, got %+v", source, status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for status from %q", source)
+	}
+}
+
+func TestGetMonitorHealth(t *testing.T) {
+	healthy := newFakeMonitor()
+	exporter := &fakeExporter{statuses: make(chan *types.Status, 10)}
+	pd := NewProblemDetector(map[string]types.Monitor{
+		"healthy": healthy,
+		"broken":  &failingMonitor{},
+	}, []types.Exporter{exporter})
+
+	health := pd.GetMonitorHealth()
+	if len(health) != 2 || health[0].Started || health[1].Started {
+		t.Fatalf("expected no monitor to be started before Run, got %+v", health)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- pd.Run(ctx) }()
+
+	healthy.statuses <- &types.Status{Source: "healthy"}
+	waitForStatus(t, exporter, "healthy")
+
+	health = pd.GetMonitorHealth()
+	if len(health) != 2 {
+		t.Fatalf("expected health of 2 monitors, got %+v", health)
+	}
+	if broken := health[0]; broken.Name != "broken" || broken.Started || !broken.Failed || broken.Error == "" {
+		t.Errorf("expected the broken monitor to fail, got %+v", broken)
+	}
+	if h := health[1]; h.Name != "healthy" || !h.Started || h.Failed || h.LastStatusTime.IsZero() {
+		t.Errorf("expected the healthy monitor to be started with a status, got %+v", h)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error from Run: %v", err)
+	}
+}
+
+// failingMonitor is a types.Monitor that fails to start.
+type failingMonitor struct{}
+
+func (f *failingMonitor) Start() (<-chan *types.Status, error) {
+	return nil, errors.New("failed to start")
+}
+
+func (f *failingMonitor) Stop() {}
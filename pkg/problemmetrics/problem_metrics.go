@@ -19,6 +19,7 @@ package problemmetrics
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
 	"k8s.io/klog/v2"
@@ -40,8 +41,14 @@ func init() {
 type ProblemMetricsManager struct {
 	problemCounter           metrics.Int64MetricInterface
 	problemGauge             metrics.Int64MetricInterface
-	problemTypeToReason      map[string]string
-	problemTypeToReasonMutex sync.Mutex
+	problemTypeToLabels      map[string]map[string]string
+	problemTypeToLabelsMutex sync.Mutex
+	// problemLabelCounter counts the problems with extra labels. It is nil
+	// until the extra labels are declared.
+	problemLabelCounter    metrics.Int64MetricInterface
+	problemLabels          []string
+	problemLabelsMutex     sync.RWMutex
+	newProblemLabelCounter func(tagNames []string) (metrics.Int64MetricInterface, error)
 }
 
 func NewProblemMetricsManagerOrDie() *ProblemMetricsManager {
@@ -54,7 +61,7 @@ func NewProblemMetricsManagerOrDie() *ProblemMetricsManager {
 		"Number of times a specific type of problem have occurred.",
 		"1",
 		metrics.Sum,
-		[]string{"reason"})
+		[]string{"reason", "severity"})
 	if err != nil {
 		klog.Fatalf("Failed to create problem_counter metric: %v", err)
 	}
@@ -65,12 +72,21 @@ func NewProblemMetricsManagerOrDie() *ProblemMetricsManager {
 		"Whether a specific type of problem is affecting the node or not.",
 		"1",
 		metrics.LastValue,
-		[]string{"type", "reason"})
+		[]string{"type", "reason", "severity"})
 	if err != nil {
 		klog.Fatalf("Failed to create problem_gauge metric: %v", err)
 	}
 
-	pmm.problemTypeToReason = make(map[string]string)
+	pmm.problemTypeToLabels = make(map[string]map[string]string)
+	pmm.newProblemLabelCounter = func(tagNames []string) (metrics.Int64MetricInterface, error) {
+		return metrics.NewInt64Metric(
+			metrics.ProblemLabelCounterID,
+			string(metrics.ProblemLabelCounterID),
+			"Number of times a specific type of problem have occurred, with the extra labels of the problem.",
+			"1",
+			metrics.Sum,
+			tagNames)
+	}
 
 	return &pmm
 }
@@ -84,33 +100,102 @@ func (pmm *ProblemMetricsManager) IncrementProblemCounter(reason string, count i
 	return pmm.problemCounter.Record(map[string]string{"reason": reason}, count)
 }
 
+// IncrementProblemCounterWithLabels increments the value of a problem counter
+// with extra labels. The "severity" label is recorded in the problem counter,
+// the other labels in the problem label counter, and must have been declared
+// by DeclareProblemLabels.
+func (pmm *ProblemMetricsManager) IncrementProblemCounterWithLabels(reason string, labels map[string]string, count int64) error {
+	if pmm.problemCounter == nil {
+		return errors.New("problem counter is being incremented before initialized")
+	}
+
+	tags := map[string]string{"reason": reason}
+	extraTags := map[string]string{"reason": reason}
+	for name, value := range labels {
+		if name == "severity" {
+			tags[name] = value
+		} else {
+			extraTags[name] = value
+		}
+	}
+	if err := pmm.problemCounter.Record(tags, count); err != nil {
+		return err
+	}
+	if len(extraTags) == 1 {
+		return nil
+	}
+
+	pmm.problemLabelsMutex.RLock()
+	defer pmm.problemLabelsMutex.RUnlock()
+
+	for name := range extraTags {
+		if name != "reason" && !slices.Contains(pmm.problemLabels, name) {
+			return fmt.Errorf("problem label %q was not declared when node problem detector started", name)
+		}
+	}
+	return pmm.problemLabelCounter.Record(extraTags, count)
+}
+
+// DeclareProblemLabels creates the problem label counter with the extra labels
+// problems can be counted with. The labels can only be declared once, before
+// any problem is counted with them, because the labels of a registered metric
+// can't change.
+func (pmm *ProblemMetricsManager) DeclareProblemLabels(labels []string) error {
+	pmm.problemLabelsMutex.Lock()
+	defer pmm.problemLabelsMutex.Unlock()
+
+	if pmm.problemLabelCounter != nil {
+		return fmt.Errorf("problem labels are already declared as %q", pmm.problemLabels)
+	}
+	if pmm.newProblemLabelCounter == nil {
+		return errors.New("problem labels are being declared before initialized")
+	}
+	counter, err := pmm.newProblemLabelCounter(append([]string{"reason"}, labels...))
+	if err != nil {
+		return err
+	}
+	pmm.problemLabelCounter = counter
+	pmm.problemLabels = slices.Clone(labels)
+	return nil
+}
+
 // SetProblemGauge sets the value of a problem gauge.
 func (pmm *ProblemMetricsManager) SetProblemGauge(problemType string, reason string, value bool) error {
+	return pmm.SetProblemGaugeWithSeverity(problemType, reason, "", value)
+}
+
+// SetProblemGaugeWithSeverity sets the value of a problem gauge with a
+// severity label. An empty severity is not added as label.
+func (pmm *ProblemMetricsManager) SetProblemGaugeWithSeverity(problemType, reason, severity string, value bool) error {
 	if pmm.problemGauge == nil {
 		return errors.New("problem gauge is being set before initialized")
 	}
 
-	pmm.problemTypeToReasonMutex.Lock()
-	defer pmm.problemTypeToReasonMutex.Unlock()
+	pmm.problemTypeToLabelsMutex.Lock()
+	defer pmm.problemTypeToLabelsMutex.Unlock()
 
 	// We clear the last reason, because the expected behavior is that at any point of time,
 	// for each type of permanent problem, there should be at most one reason got set to 1.
 	// This behavior is consistent with the behavior of node condition in Kubernetes.
 	// However, problemGauges with different "type" and "reason" are considered as different
 	// metrics in Prometheus. So we need to clear the previous metrics explicitly.
-	if lastReason, ok := pmm.problemTypeToReason[problemType]; ok {
-		err := pmm.problemGauge.Record(map[string]string{"type": problemType, "reason": lastReason}, 0)
+	if lastLabels, ok := pmm.problemTypeToLabels[problemType]; ok {
+		err := pmm.problemGauge.Record(lastLabels, 0)
 		if err != nil {
 			return fmt.Errorf("failed to clear previous reason %q for type %q: %v",
-				problemType, lastReason, err)
+				lastLabels["reason"], problemType, err)
 		}
 	}
 
-	pmm.problemTypeToReason[problemType] = reason
+	labels := map[string]string{"type": problemType, "reason": reason}
+	if severity != "" {
+		labels["severity"] = severity
+	}
+	pmm.problemTypeToLabels[problemType] = labels
 
 	var valueInt int64
 	if value {
 		valueInt = 1
 	}
-	return pmm.problemGauge.Record(map[string]string{"type": problemType, "reason": reason}, valueInt)
+	return pmm.problemGauge.Record(labels, valueInt)
 }
@@ -23,13 +23,25 @@ import (
 // NewProblemMetricsManagerStub creates a ProblemMetricsManager stubbed by fake metrics.
 // The stubbed ProblemMetricsManager and fake metrics are returned.
 func NewProblemMetricsManagerStub() (*ProblemMetricsManager, *metrics.FakeInt64Metric, *metrics.FakeInt64Metric) {
-	fakeProblemCounter := metrics.NewFakeInt64Metric("problem_counter", metrics.Sum, []string{"reason"})
-	fakeProblemGauge := metrics.NewFakeInt64Metric("problem_gauge", metrics.LastValue, []string{"type", "reason"})
+	fakeProblemCounter := metrics.NewFakeInt64Metric("problem_counter", metrics.Sum, []string{"reason", "severity"})
+	fakeProblemGauge := metrics.NewFakeInt64Metric("problem_gauge", metrics.LastValue, []string{"type", "reason", "severity"})
 
 	pmm := ProblemMetricsManager{}
 	pmm.problemCounter = metrics.Int64MetricInterface(fakeProblemCounter)
 	pmm.problemGauge = metrics.Int64MetricInterface(fakeProblemGauge)
-	pmm.problemTypeToReason = make(map[string]string)
+	pmm.problemTypeToLabels = make(map[string]map[string]string)
+	pmm.newProblemLabelCounter = func(tagNames []string) (metrics.Int64MetricInterface, error) {
+		return metrics.NewFakeInt64Metric("problem_label_counter", metrics.Sum, tagNames), nil
+	}
 
 	return &pmm, fakeProblemCounter, fakeProblemGauge
 }
+
+// FakeProblemLabelCounter returns the fake problem label counter of a stubbed
+// ProblemMetricsManager, which is nil until the problem labels are declared.
+func (pmm *ProblemMetricsManager) FakeProblemLabelCounter() *metrics.FakeInt64Metric {
+	pmm.problemLabelsMutex.RLock()
+	defer pmm.problemLabelsMutex.RUnlock()
+	fake, _ := pmm.problemLabelCounter.(*metrics.FakeInt64Metric)
+	return fake
+}
@@ -130,6 +130,27 @@ func TestNewProblem(t *testing.T) {
 	}
 }
 
+func TestIncrementProblemCounterWithLabels(t *testing.T) {
+	pmm, fakeProblemCounter, _ := NewProblemMetricsManagerStub()
+
+	assert.Error(t, pmm.IncrementProblemCounterWithLabels("foo", map[string]string{"process": "nginx"}, 1),
+		"labels must be declared before they are used")
+	assert.NoError(t, pmm.DeclareProblemLabels([]string{"process"}))
+	assert.Error(t, pmm.DeclareProblemLabels([]string{"unit"}), "labels can only be declared once")
+	assert.NoError(t, pmm.IncrementProblemCounterWithLabels("foo", map[string]string{"severity": "error"}, 0))
+	assert.NoError(t, pmm.IncrementProblemCounterWithLabels("foo", map[string]string{"severity": "error", "process": "nginx"}, 1))
+	assert.NoError(t, pmm.IncrementProblemCounterWithLabels("foo", map[string]string{"severity": "error", "process": "nginx"}, 1))
+	assert.Error(t, pmm.IncrementProblemCounterWithLabels("foo", map[string]string{"unit": "kubelet"}, 1))
+
+	assert.ElementsMatch(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_counter", Labels: map[string]string{"reason": "foo"}, Value: 2},
+		{Name: "problem_counter", Labels: map[string]string{"reason": "foo", "severity": "error"}, Value: 2},
+	}, fakeProblemCounter.ListMetrics())
+	assert.ElementsMatch(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_label_counter", Labels: map[string]string{"reason": "foo", "process": "nginx"}, Value: 2},
+	}, pmm.FakeProblemLabelCounter().ListMetrics())
+}
+
 func TestSetProblemGauge(t *testing.T) {
 	type argumentType struct {
 		problemType string
@@ -279,3 +300,15 @@ func TestSetProblemGauge(t *testing.T) {
 		})
 	}
 }
+
+func TestSetProblemGaugeWithSeverity(t *testing.T) {
+	pmm, _, fakeProblemGauge := NewProblemMetricsManagerStub()
+
+	assert.NoError(t, pmm.SetProblemGaugeWithSeverity("ProblemTypeA", "ReasonFoo", "critical", true))
+	assert.NoError(t, pmm.SetProblemGaugeWithSeverity("ProblemTypeA", "ReasonBar", "", false))
+
+	assert.ElementsMatch(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_gauge", Labels: map[string]string{"type": "ProblemTypeA", "reason": "ReasonFoo", "severity": "critical"}, Value: 0},
+		{Name: "problem_gauge", Labels: map[string]string{"type": "ProblemTypeA", "reason": "ReasonBar"}, Value: 0},
+	}, fakeProblemGauge.ListMetrics())
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"k8s.io/node-problem-detector/cmd/options"
+	"k8s.io/node-problem-detector/pkg/problemdetector"
+	"k8s.io/node-problem-detector/pkg/types"
+	"k8s.io/node-problem-detector/pkg/util"
+)
+
+// subscriberBufferSize is the number of statuses buffered for each streaming
+// client. A client that falls further behind is disconnected.
+const subscriberBufferSize = 100
+
+// Event is a problem event with the source that reported it.
+type Event struct {
+	// Source is the name of the problem daemon that reported the event.
+	Source string `json:"source"`
+	types.Event
+}
+
+// Server is the local HTTP status API of node problem detector. It is an
+// exporter that keeps what problem detector dispatches in memory, so that
+// the state of node problem detector can be inspected no matter which other
+// exporters are enabled.
+type Server struct {
+	mu sync.Mutex
+	// conditions are the latest conditions reported by each source.
+	conditions map[string][]types.Condition
+	// events is a ring of the most recent events. next is the index the next
+	// event is written to.
+	events    []Event
+	next      int
+	maxEvents int
+	// subscribers receive every status dispatched to the server.
+	subscribers map[chan *types.Status]struct{}
+	// monitorHealth returns the health of the monitors.
+	monitorHealth func() []problemdetector.MonitorHealth
+}
+
+// NewServerOrDie creates the status API server and starts serving it, panics
+// if error occurs. It returns nil if the status API is disabled.
+func NewServerOrDie(npdo *options.NodeProblemDetectorOptions) *Server {
+	if npdo.StatusAPIPort <= 0 {
+		return nil
+	}
+	s := NewServer(npdo.StatusAPIMaxEvents)
+	addr := net.JoinHostPort(npdo.StatusAPIAddress, strconv.Itoa(npdo.StatusAPIPort))
+	go func() {
+		if err := http.ListenAndServe(addr, s.Handler()); err != nil {
+			klog.Fatalf("Failed to start status API server: %v", err)
+		}
+	}()
+	return s
+}
+
+// NewServer creates the status API server that keeps at most maxEvents recent events.
+func NewServer(maxEvents int) *Server {
+	return &Server{
+		conditions:  make(map[string][]types.Condition),
+		maxEvents:   maxEvents,
+		subscribers: make(map[chan *types.Status]struct{}),
+	}
+}
+
+// SetMonitorHealthFunc sets the function the server gets the health of the monitors from.
+func (s *Server) SetMonitorHealthFunc(f func() []problemdetector.MonitorHealth) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.monitorHealth = f
+}
+
+// ExportProblems records the status and sends it to the streaming clients.
+func (s *Server) ExportProblems(status *types.Status) {
+	// The monitor may modify the slices of the status after sending it, while
+	// the streaming clients marshal it later, so they get a copy.
+	status = &types.Status{
+		Source:     status.Source,
+		Events:     slices.Clone(status.Events),
+		Conditions: slices.Clone(status.Conditions),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(status.Conditions) != 0 {
+		s.conditions[status.Source] = status.Conditions
+	}
+	for _, event := range status.Events {
+		s.addEvent(Event{Source: status.Source, Event: event})
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- status:
+		default:
+			klog.Warningf("Status API client is too slow, disconnecting it")
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// addEvent adds the event to the ring of recent events. s.mu must be held.
+func (s *Server) addEvent(event Event) {
+	if s.maxEvents <= 0 {
+		return
+	}
+	if len(s.events) < s.maxEvents {
+		s.events = append(s.events, event)
+	} else {
+		s.events[s.next] = event
+	}
+	s.next = (s.next + 1) % s.maxEvents
+}
+
+// GetConditions returns the latest conditions grouped by source.
+func (s *Server) GetConditions() map[string][]types.Condition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conditions := make(map[string][]types.Condition, len(s.conditions))
+	for source, c := range s.conditions {
+		conditions[source] = append([]types.Condition(nil), c...)
+	}
+	return conditions
+}
+
+// GetEvents returns the recent events from oldest to newest.
+func (s *Server) GetEvents() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]Event, 0, len(s.events))
+	if len(s.events) == s.maxEvents {
+		events = append(events, s.events[s.next:]...)
+		return append(events, s.events[:s.next]...)
+	}
+	return append(events, s.events...)
+}
+
+// GetMonitorHealth returns the health of the monitors.
+func (s *Server) GetMonitorHealth() []problemdetector.MonitorHealth {
+	s.mu.Lock()
+	f := s.monitorHealth
+	s.mu.Unlock()
+	if f == nil {
+		return []problemdetector.MonitorHealth{}
+	}
+	return f()
+}
+
+// Handler returns the HTTP handler of the status API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/conditions", func(w http.ResponseWriter, r *http.Request) {
+		util.ReturnHTTPJson(w, s.GetConditions())
+	})
+	mux.HandleFunc("/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		util.ReturnHTTPJson(w, s.GetEvents())
+	})
+	mux.HandleFunc("/v1/monitors", func(w http.ResponseWriter, r *http.Request) {
+		util.ReturnHTTPJson(w, s.GetMonitorHealth())
+	})
+	mux.HandleFunc("/v1/statuses", s.streamStatuses)
+	return mux
+}
+
+// streamStatuses streams every status dispatched to the server as server-sent events.
+func (s *Server) streamStatuses(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case status, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(status)
+			if err != nil {
+				klog.Errorf("Failed to marshal status %+v: %v", status, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) subscribe() chan *types.Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := make(chan *types.Status, subscriberBufferSize)
+	s.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (s *Server) unsubscribe(ch chan *types.Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The channel is already closed if the subscriber was disconnected.
+	if _, ok := s.subscribers[ch]; ok {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/node-problem-detector/pkg/problemdetector"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+func TestExportProblems(t *testing.T) {
+	s := NewServer(2)
+	s.ExportProblems(&types.Status{
+		Source:     "foo",
+		Events:     []types.Event{{Reason: "A"}, {Reason: "B"}},
+		Conditions: []types.Condition{{Type: "FooProblem", Status: types.False}},
+	})
+	s.ExportProblems(&types.Status{
+		Source: "bar",
+		Events: []types.Event{{Reason: "C"}},
+	})
+	s.ExportProblems(&types.Status{
+		Source:     "foo",
+		Conditions: []types.Condition{{Type: "FooProblem", Status: types.True}},
+	})
+
+	assert.Equal(t, map[string][]types.Condition{
+		"foo": {{Type: "FooProblem", Status: types.True}},
+	}, s.GetConditions())
+	assert.Equal(t, []Event{
+		{Source: "foo", Event: types.Event{Reason: "B"}},
+		{Source: "bar", Event: types.Event{Reason: "C"}},
+	}, s.GetEvents(), "only the most recent events should be kept")
+}
+
+func TestExportProblemsCopiesStatus(t *testing.T) {
+	s := NewServer(10)
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	status := &types.Status{
+		Source:     "foo",
+		Events:     []types.Event{{Reason: "A"}},
+		Conditions: []types.Condition{{Type: "FooProblem", Status: types.False}},
+	}
+	s.ExportProblems(status)
+	// The monitor keeps updating its conditions in place.
+	status.Events[0].Reason = "B"
+	status.Conditions[0].Status = types.True
+
+	got := <-ch
+	assert.Equal(t, []types.Event{{Reason: "A"}}, got.Events)
+	assert.Equal(t, []types.Condition{{Type: "FooProblem", Status: types.False}}, got.Conditions)
+	assert.Equal(t, types.False, s.GetConditions()["foo"][0].Status)
+}
+
+func TestGetEvents(t *testing.T) {
+	testCases := []struct {
+		name      string
+		maxEvents int
+		reasons   []string
+		expected  []string
+	}{
+		{
+			name:      "ring not full",
+			maxEvents: 3,
+			reasons:   []string{"A", "B"},
+			expected:  []string{"A", "B"},
+		},
+		{
+			name:      "ring wrapped",
+			maxEvents: 3,
+			reasons:   []string{"A", "B", "C", "D", "E"},
+			expected:  []string{"C", "D", "E"},
+		},
+		{
+			name:      "events disabled",
+			maxEvents: 0,
+			reasons:   []string{"A"},
+			expected:  []string{},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			s := NewServer(test.maxEvents)
+			for _, reason := range test.reasons {
+				s.ExportProblems(&types.Status{Events: []types.Event{{Reason: reason}}})
+			}
+			reasons := []string{}
+			for _, event := range s.GetEvents() {
+				reasons = append(reasons, event.Reason)
+			}
+			assert.Equal(t, test.expected, reasons)
+		})
+	}
+}
+
+func TestMonitors(t *testing.T) {
+	s := NewServer(10)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	var health []problemdetector.MonitorHealth
+	getJSON(t, server.URL+"/v1/monitors", &health)
+	assert.Empty(t, health)
+
+	expected := []problemdetector.MonitorHealth{{Name: "foo.json", Started: true}}
+	s.SetMonitorHealthFunc(func() []problemdetector.MonitorHealth { return expected })
+	getJSON(t, server.URL+"/v1/monitors", &health)
+	assert.Equal(t, expected, health)
+}
+
+func TestStreamStatuses(t *testing.T) {
+	s := NewServer(10)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/v1/statuses")
+	if err != nil {
+		t.Fatalf("failed to get status stream: %v", err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The headers are flushed after the client is subscribed.
+	status := &types.Status{Source: "foo", Events: []types.Event{{Reason: "A", Timestamp: time.Unix(0, 0).UTC()}}}
+	s.ExportProblems(status)
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read status stream: %v", err)
+		}
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		var got types.Status
+		if err := json.Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("failed to decode status %q: %v", data, err)
+		}
+		assert.Equal(t, *status, got)
+		return
+	}
+}
+
+func getJSON(t *testing.T, url string, object interface{}) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("failed to get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(object); err != nil {
+		t.Fatalf("failed to decode %s: %v", url, err)
+	}
+}
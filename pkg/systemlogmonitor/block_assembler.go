@@ -0,0 +1,226 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+const (
+	defaultBlockMaxLines = 200
+	defaultBlockMaxBytes = 16 * 1024
+	defaultBlockTimeout  = time.Second
+	// blockTruncatedMarker is appended to the message of a block whose lines
+	// were dropped because of MaxBytes.
+	blockTruncatedMarker = "\n..."
+)
+
+// blockPattern is a compiled block configuration.
+type blockPattern struct {
+	start        *regexp.Regexp
+	end          *regexp.Regexp
+	continuation *regexp.Regexp
+	maxLines     int
+	maxBytes     int
+	timeout      time.Duration
+}
+
+// compileBlock compiles the block configuration and applies the default limits.
+func compileBlock(block types.Block) (*blockPattern, error) {
+	if block.Start == "" {
+		return nil, fmt.Errorf("start must be set. Block: %+v", block)
+	}
+	if (block.End == "") == (block.Continuation == "") {
+		return nil, fmt.Errorf("exactly one of end and continuation must be set. Block: %+v", block)
+	}
+	if block.MaxLines < 0 || block.MaxBytes < 0 {
+		return nil, fmt.Errorf("maxLines and maxBytes must not be negative. Block: %+v", block)
+	}
+	p := &blockPattern{
+		maxLines: block.MaxLines,
+		maxBytes: block.MaxBytes,
+		timeout:  defaultBlockTimeout,
+	}
+	if p.maxLines == 0 {
+		p.maxLines = defaultBlockMaxLines
+	}
+	if p.maxBytes == 0 {
+		p.maxBytes = defaultBlockMaxBytes
+	}
+	var err error
+	if p.start, err = regexp.Compile(block.Start); err != nil {
+		return nil, fmt.Errorf("invalid block start %q: %v", block.Start, err)
+	}
+	if block.End != "" {
+		if p.end, err = regexp.Compile(block.End); err != nil {
+			return nil, fmt.Errorf("invalid block end %q: %v", block.End, err)
+		}
+	}
+	if block.Continuation != "" {
+		if p.continuation, err = regexp.Compile(block.Continuation); err != nil {
+			return nil, fmt.Errorf("invalid block continuation %q: %v", block.Continuation, err)
+		}
+	}
+	if block.Timeout != "" {
+		if p.timeout, err = time.ParseDuration(block.Timeout); err != nil {
+			return nil, fmt.Errorf("error in parsing block timeout %q: %v", block.Timeout, err)
+		}
+		if p.timeout <= 0 {
+			return nil, fmt.Errorf("block timeout must be greater than zero: %v", p.timeout)
+		}
+	}
+	return p, nil
+}
+
+// blockAssembler collapses the lines of a block into a single log, whose
+// message is the messages of the lines joined by newlines. The log keeps the
+// timestamp and the fields of the first line. Lines outside of any block pass
+// through unchanged.
+// blockAssembler is not safe for concurrent use.
+type blockAssembler struct {
+	patterns []*blockPattern
+	// current is the pattern of the open block, nil when no block is open.
+	current *blockPattern
+	block   *types.Log
+	message strings.Builder
+	lines   int
+	// truncated reports whether lines were dropped from the open block.
+	truncated bool
+	// lastPush is when the last line was added to the open block.
+	lastPush time.Time
+}
+
+func newBlockAssembler(patterns []*blockPattern) *blockAssembler {
+	return &blockAssembler{patterns: patterns}
+}
+
+// checkPeriod returns the period at which flushIdle should be called.
+func (a *blockAssembler) checkPeriod() time.Duration {
+	period := a.patterns[0].timeout
+	for _, p := range a.patterns[1:] {
+		period = min(period, p.timeout)
+	}
+	return period
+}
+
+// push adds the log to the open block or opens a new one, and returns the logs
+// ready to be matched, oldest first.
+func (a *blockAssembler) push(log *types.Log, now time.Time) []*types.Log {
+	var ready []*types.Log
+	if a.current != nil {
+		switch {
+		case a.current.end != nil && a.startPattern(log) != nil:
+			// A new block starts before the open one ended.
+			ready = append(ready, a.close())
+		case a.current.end != nil:
+			a.add(log, now)
+			if a.current.end.MatchString(log.Message) || a.lines >= a.current.maxLines {
+				ready = append(ready, a.close())
+			}
+			return ready
+		case a.current.continuation.MatchString(log.Message):
+			a.add(log, now)
+			if a.lines >= a.current.maxLines {
+				ready = append(ready, a.close())
+			}
+			return ready
+		default:
+			ready = append(ready, a.close())
+		}
+	}
+	p := a.startPattern(log)
+	if p == nil {
+		return append(ready, log)
+	}
+	a.open(p, log, now)
+	if a.lines >= p.maxLines {
+		ready = append(ready, a.close())
+	}
+	return ready
+}
+
+// flushIdle returns the open block if no line was added to it within its
+// timeout, and nil otherwise.
+func (a *blockAssembler) flushIdle(now time.Time) *types.Log {
+	if a.current == nil || now.Sub(a.lastPush) < a.current.timeout {
+		return nil
+	}
+	return a.close()
+}
+
+// flush returns the open block, or nil when no block is open.
+func (a *blockAssembler) flush() *types.Log {
+	if a.current == nil {
+		return nil
+	}
+	return a.close()
+}
+
+// startPattern returns the first pattern whose start matches the log.
+func (a *blockAssembler) startPattern(log *types.Log) *blockPattern {
+	for _, p := range a.patterns {
+		if p.start.MatchString(log.Message) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (a *blockAssembler) open(p *blockPattern, log *types.Log, now time.Time) {
+	a.current = p
+	a.block = &types.Log{Timestamp: log.Timestamp, Fields: log.Fields}
+	a.message.Reset()
+	a.lines = 0
+	a.truncated = false
+	a.add(log, now)
+}
+
+func (a *blockAssembler) add(log *types.Log, now time.Time) {
+	a.lines++
+	a.lastPush = now
+	if a.truncated {
+		return
+	}
+	size := len(log.Message)
+	if a.lines > 1 {
+		size++
+	}
+	if a.message.Len()+size > a.current.maxBytes {
+		a.truncated = true
+		return
+	}
+	if a.lines > 1 {
+		a.message.WriteByte('\n')
+	}
+	a.message.WriteString(log.Message)
+}
+
+func (a *blockAssembler) close() *types.Log {
+	block := a.block
+	block.Message = a.message.String()
+	if a.truncated {
+		block.Message += blockTruncatedMarker
+	}
+	a.current = nil
+	a.block = nil
+	return block
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+var (
+	oopsBlock = types.Block{
+		Start: `-+\[ cut here \]-+`,
+		End:   `---\[ end trace [0-9a-f]+ \]---`,
+	}
+	stackBlock = types.Block{
+		Start:        `^panic: `,
+		Continuation: `^(\s|goroutine |$)`,
+	}
+)
+
+func TestBlockAssembler(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		blocks []types.Block
+		lines  []string
+		// want are the messages of the logs ready after each line.
+		want [][]string
+		// flushed is the message of the block left open after the last line.
+		flushed string
+	}{
+		{
+			name:   "passes through lines outside of blocks",
+			blocks: []types.Block{oopsBlock},
+			lines:  []string{"a", "b"},
+			want:   [][]string{{"a"}, {"b"}},
+		},
+		{
+			name:   "assembles lines between start and end",
+			blocks: []types.Block{oopsBlock},
+			lines: []string{
+				"before",
+				"------------[ cut here ]------------",
+				"WARNING: CPU: 1 PID: 1 at fs/inode.c:10",
+				"Call Trace:",
+				"---[ end trace 1a2b3c ]---",
+				"after",
+			},
+			want: [][]string{
+				{"before"},
+				nil,
+				nil,
+				nil,
+				{"------------[ cut here ]------------\nWARNING: CPU: 1 PID: 1 at fs/inode.c:10\nCall Trace:\n---[ end trace 1a2b3c ]---"},
+				{"after"},
+			},
+		},
+		{
+			name:   "new start ends the open block",
+			blocks: []types.Block{oopsBlock},
+			lines: []string{
+				"------------[ cut here ]------------",
+				"first",
+				"------------[ cut here ]------------",
+				"second",
+			},
+			want: [][]string{
+				nil,
+				nil,
+				{"------------[ cut here ]------------\nfirst"},
+				nil,
+			},
+			flushed: "------------[ cut here ]------------\nsecond",
+		},
+		{
+			name:   "continuation block ends before the first other line",
+			blocks: []types.Block{oopsBlock, stackBlock},
+			lines: []string{
+				"panic: runtime error",
+				"",
+				"goroutine 1 [running]:",
+				"\tmain.go:10",
+				"next",
+				"panic: again",
+			},
+			want: [][]string{
+				nil,
+				nil,
+				nil,
+				nil,
+				{"panic: runtime error\n\ngoroutine 1 [running]:\n\tmain.go:10", "next"},
+				nil,
+			},
+			flushed: "panic: again",
+		},
+		{
+			name: "ends the block at max lines",
+			blocks: []types.Block{{
+				Start:    "start",
+				End:      "end",
+				MaxLines: 2,
+			}},
+			lines: []string{"start", "a", "b"},
+			want:  [][]string{nil, {"start\na"}, {"b"}},
+		},
+		{
+			name: "drops the lines beyond max bytes",
+			blocks: []types.Block{{
+				Start:    "start",
+				End:      "end",
+				MaxBytes: 8,
+			}},
+			lines: []string{"start", "ab", "cd", "end"},
+			want:  [][]string{nil, nil, nil, {"start\nab\n..."}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var patterns []*blockPattern
+			for _, block := range test.blocks {
+				p, err := compileBlock(block)
+				require.NoError(t, err)
+				patterns = append(patterns, p)
+			}
+			a := newBlockAssembler(patterns)
+			now := time.Unix(1000, 0)
+			for i, line := range test.lines {
+				var got []string
+				for _, log := range a.push(&types.Log{Timestamp: now, Message: line}, now) {
+					got = append(got, log.Message)
+				}
+				assert.Equal(t, test.want[i], got, "line %d: %q", i, line)
+			}
+			flushed := a.flush()
+			if test.flushed == "" {
+				assert.Nil(t, flushed)
+			} else {
+				require.NotNil(t, flushed)
+				assert.Equal(t, test.flushed, flushed.Message)
+			}
+		})
+	}
+}
+
+func TestBlockAssemblerKeepsFirstLine(t *testing.T) {
+	p, err := compileBlock(oopsBlock)
+	require.NoError(t, err)
+	a := newBlockAssembler([]*blockPattern{p})
+	start := time.Unix(1000, 0)
+	fields := map[string]string{"PRIORITY": "4"}
+	assert.Empty(t, a.push(&types.Log{Timestamp: start, Message: "------------[ cut here ]------------", Fields: fields}, start))
+	logs := a.push(&types.Log{Timestamp: start.Add(time.Second), Message: "---[ end trace 1 ]---"}, start)
+	require.Len(t, logs, 1)
+	assert.Equal(t, start, logs[0].Timestamp)
+	assert.Equal(t, fields, logs[0].Fields)
+}
+
+func TestBlockAssemblerFlushIdle(t *testing.T) {
+	p, err := compileBlock(types.Block{Start: "start", Continuation: "^ ", Timeout: "2s"})
+	require.NoError(t, err)
+	a := newBlockAssembler([]*blockPattern{p})
+	assert.Equal(t, 2*time.Second, a.checkPeriod())
+	now := time.Unix(1000, 0)
+	assert.Nil(t, a.flushIdle(now))
+	assert.Empty(t, a.push(&types.Log{Message: "start"}, now))
+	assert.Empty(t, a.push(&types.Log{Message: " line"}, now.Add(time.Second)))
+	assert.Nil(t, a.flushIdle(now.Add(2*time.Second)))
+	block := a.flushIdle(now.Add(3 * time.Second))
+	require.NotNil(t, block)
+	assert.Equal(t, "start\n line", block.Message)
+	assert.Nil(t, a.flushIdle(now.Add(4*time.Second)))
+}
+
+func TestCompileBlock(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		block types.Block
+	}{
+		{name: "missing start", block: types.Block{End: "end"}},
+		{name: "missing end and continuation", block: types.Block{Start: "start"}},
+		{name: "both end and continuation", block: types.Block{Start: "start", End: "end", Continuation: " "}},
+		{name: "invalid start", block: types.Block{Start: "(", End: "end"}},
+		{name: "invalid end", block: types.Block{Start: "start", End: "("}},
+		{name: "invalid continuation", block: types.Block{Start: "start", Continuation: "("}},
+		{name: "negative max lines", block: types.Block{Start: "start", End: "end", MaxLines: -1}},
+		{name: "invalid timeout", block: types.Block{Start: "start", End: "end", Timeout: "soon"}},
+		{name: "non-positive timeout", block: types.Block{Start: "start", End: "end", Timeout: "0s"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := compileBlock(test.block)
+			assert.Error(t, err)
+		})
+	}
+}
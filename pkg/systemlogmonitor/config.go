@@ -17,9 +17,13 @@ limitations under the License.
 package systemlogmonitor
 
 import (
+	"fmt"
+	"time"
+
 	watchertypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
 	systemlogtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
 	"k8s.io/node-problem-detector/pkg/types"
+	"k8s.io/node-problem-detector/pkg/util"
 )
 
 var (
@@ -38,6 +42,9 @@ type MonitorConfig struct {
 	Source string `json:"source"`
 	// DefaultConditions are the default states of all the conditions log monitor should handle.
 	DefaultConditions []types.Condition `json:"conditions"`
+	// Blocks describe the multi-line logs assembled into a single log before
+	// the rules are matched.
+	Blocks []systemlogtypes.Block `json:"blocks,omitempty"`
 	// Rules are the rules log monitor will follow to parse the log file.
 	Rules []systemlogtypes.Rule `json:"rules"`
 	// EnableMetricsReporting describes whether to report problems as metrics or not.
@@ -64,7 +71,199 @@ func (mc MonitorConfig) compileRules() ([]*Pattern, error) {
 		if err != nil {
 			return nil, err
 		}
+		if pattern.fields, err = compileFields(rule.Fields); err != nil {
+			return nil, err
+		}
+		patterns[i] = pattern
+	}
+	return patterns, nil
+}
+
+// compileTemplates compiles the templates of the rules with the compiled
+// patterns of the rules.
+func (mc MonitorConfig) compileTemplates(patterns []*Pattern) ([]*ruleTemplate, error) {
+	templates := make([]*ruleTemplate, len(mc.Rules))
+	for i, rule := range mc.Rules {
+		t, err := compileRuleTemplate(rule, patterns[i])
+		if err != nil {
+			return nil, err
+		}
+		templates[i] = t
+	}
+	return templates, nil
+}
+
+// compileBlocks compiles the block configurations. It returns nil if no block
+// is configured.
+func (mc MonitorConfig) compileBlocks() ([]*blockPattern, error) {
+	if len(mc.Blocks) == 0 {
+		return nil, nil
+	}
+	patterns := make([]*blockPattern, len(mc.Blocks))
+	for i, block := range mc.Blocks {
+		pattern, err := compileBlock(block)
+		if err != nil {
+			return nil, err
+		}
 		patterns[i] = pattern
 	}
 	return patterns, nil
 }
+
+// validateRules verifies the severity of every rule, and that every recovery
+// rule and every rule with clearAfter set refers to a preset default condition.
+func (mc MonitorConfig) validateRules() error {
+	for _, rule := range mc.Rules {
+		if err := mc.validateRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRule verifies the severity of the rule, and that the rule refers to
+// a preset default condition if it is a recovery rule or has clearAfter set.
+func (mc MonitorConfig) validateRule(rule systemlogtypes.Rule) error {
+	if _, err := types.ParseSeverity(rule.Severity); err != nil {
+		return fmt.Errorf("%v. Rule: %+v", err, rule)
+	}
+	if rule.ClearAfter != "" && rule.Type != types.Perm {
+		return fmt.Errorf("clearAfter is only supported by permanent rules. Rule: %+v", rule)
+	}
+	if rule.Type != systemlogtypes.Recovery && rule.ClearAfter == "" {
+		return nil
+	}
+	if !mc.hasDefaultCondition(rule.Condition) {
+		return fmt.Errorf("%s problem %s does not have preset default condition", rule.Type, rule.Condition)
+	}
+	return nil
+}
+
+// hasDefaultCondition reports whether a default condition of the type is configured.
+func (mc MonitorConfig) hasDefaultCondition(conditionType string) bool {
+	for _, condition := range mc.DefaultConditions {
+		if condition.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMatchWindows parses the sliding window of each rule with count set.
+// The window of a rule without count is nil.
+func (mc MonitorConfig) parseMatchWindows() ([]*matchWindow, error) {
+	windows := make([]*matchWindow, len(mc.Rules))
+	for i, rule := range mc.Rules {
+		window, err := parseRuleMatchWindow(rule)
+		if err != nil {
+			return nil, err
+		}
+		windows[i] = window
+	}
+	return windows, nil
+}
+
+// parseRuleMatchWindow parses the sliding window of the rule. It returns nil
+// if the rule has no count.
+func parseRuleMatchWindow(rule systemlogtypes.Rule) (*matchWindow, error) {
+	if rule.Count == 0 {
+		if rule.Window != "" || rule.RevertPattern != "" {
+			return nil, fmt.Errorf("window and revertPattern are only supported with count. Rule: %+v", rule)
+		}
+		return nil, nil
+	}
+	if rule.Count < 0 {
+		return nil, fmt.Errorf("count must not be negative: %d", rule.Count)
+	}
+	if rule.Type == systemlogtypes.Recovery {
+		return nil, fmt.Errorf("count is not supported by recovery rules. Rule: %+v", rule)
+	}
+	window, err := time.ParseDuration(rule.Window)
+	if err != nil {
+		return nil, fmt.Errorf("error in parsing window %q: %v", rule.Window, err)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be greater than zero: %v", window)
+	}
+	var revertPattern *Pattern
+	if rule.RevertPattern != "" {
+		revertPattern, err = CompilePattern(rule.RevertPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid revert pattern %q: %v", rule.RevertPattern, err)
+		}
+	}
+	return newMatchWindow(rule.Count, window, revertPattern), nil
+}
+
+// parseClearAfter parses the clearAfter duration of each rule. A zero duration
+// means the condition set by the rule never expires.
+func (mc MonitorConfig) parseClearAfter() ([]time.Duration, error) {
+	durations := make([]time.Duration, len(mc.Rules))
+	for i, rule := range mc.Rules {
+		d, err := parseRuleClearAfter(rule)
+		if err != nil {
+			return nil, err
+		}
+		durations[i] = d
+	}
+	return durations, nil
+}
+
+// parseRuleClearAfter parses the clearAfter duration of the rule. It returns
+// zero if the rule has no clearAfter.
+func parseRuleClearAfter(rule systemlogtypes.Rule) (time.Duration, error) {
+	if rule.ClearAfter == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(rule.ClearAfter)
+	if err != nil {
+		return 0, fmt.Errorf("error in parsing clearAfter %q: %v", rule.ClearAfter, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("clearAfter must be greater than zero: %v", d)
+	}
+	return d, nil
+}
+
+// parseLimiters creates the event limiters of the rules with cooldown set,
+// indexed like the rules.
+func (mc MonitorConfig) parseLimiters() ([]*util.EventLimiter, error) {
+	limiters := make([]*util.EventLimiter, len(mc.Rules))
+	for i, rule := range mc.Rules {
+		limiter, err := parseRuleLimiter(rule)
+		if err != nil {
+			return nil, err
+		}
+		limiters[i] = limiter
+	}
+	return limiters, nil
+}
+
+// parseRuleLimiter creates the event limiter of the rule. It returns nil if
+// the rule has no cooldown.
+func parseRuleLimiter(rule systemlogtypes.Rule) (*util.EventLimiter, error) {
+	if rule.Cooldown == "" {
+		if rule.MaxEventsPerWindow != 0 {
+			return nil, fmt.Errorf("maxEventsPerWindow is only supported with cooldown. Rule: %+v", rule)
+		}
+		return nil, nil
+	}
+	if rule.Type != types.Temp {
+		return nil, fmt.Errorf("cooldown is only supported by temporary rules. Rule: %+v", rule)
+	}
+	cooldown, err := time.ParseDuration(rule.Cooldown)
+	if err != nil {
+		return nil, fmt.Errorf("error in parsing cooldown %q: %v", rule.Cooldown, err)
+	}
+	if cooldown <= 0 {
+		return nil, fmt.Errorf("cooldown must be greater than zero: %v", cooldown)
+	}
+	maxEvents := rule.MaxEventsPerWindow
+	if maxEvents == 0 {
+		maxEvents = 1
+	}
+	if maxEvents < 0 {
+		return nil, fmt.Errorf("maxEventsPerWindow must not be negative: %d", rule.MaxEventsPerWindow)
+	}
+	return util.NewEventLimiter(cooldown, maxEvents), nil
+}
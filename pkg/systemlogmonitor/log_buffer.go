@@ -17,6 +17,7 @@ limitations under the License.
 package systemlogmonitor
 
 import (
+	"fmt"
 	"regexp"
 	"regexp/syntax"
 	"slices"
@@ -40,6 +41,8 @@ type Pattern struct {
 	// lastLineOnly reports that the rule can match only in the last pushed line.
 	// Match then skips building the joined buffer.
 	lastLineOnly bool
+	// fields are the matchers of the structured fields of the last pushed line.
+	fields map[string]*regexp.Regexp
 }
 
 // logBuffer is not safe for concurrent use.
@@ -94,6 +97,33 @@ func CompilePattern(expr string) (*Pattern, error) {
 	return p, nil
 }
 
+// compileFields compiles the matchers of the structured log fields.
+func compileFields(fields map[string]string) (map[string]*regexp.Regexp, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	compiled := make(map[string]*regexp.Regexp, len(fields))
+	for name, expr := range fields {
+		reg, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher of field %q: %v", name, err)
+		}
+		compiled[name] = reg
+	}
+	return compiled, nil
+}
+
+// matchFields reports whether the structured fields of the log match every field matcher.
+func (p *Pattern) matchFields(log *types.Log) bool {
+	for name, reg := range p.fields {
+		value, ok := log.Fields[name]
+		if !ok || !reg.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
 // isLastLineOnly reports whether the rule accepts no newline and has no start anchor.
 func isLastLineOnly(re *syntax.Regexp) bool {
 	switch re.Op {
@@ -123,6 +153,53 @@ func isLastLineOnly(re *syntax.Regexp) bool {
 	return true
 }
 
+// minLines returns the minimum number of log lines the expression matches,
+// which is one more than the newlines it requires.
+func minLines(expr string) (int, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return 0, err
+	}
+	return minNewlines(re) + 1, nil
+}
+
+// minNewlines returns the minimum number of newlines the expression matches.
+func minNewlines(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		n := 0
+		for _, r := range re.Rune {
+			if r == '\n' {
+				n++
+			}
+		}
+		return n
+	case syntax.OpCharClass:
+		if len(re.Rune) == 2 && re.Rune[0] == '\n' && re.Rune[1] == '\n' {
+			return 1
+		}
+		return 0
+	case syntax.OpCapture, syntax.OpPlus:
+		return minNewlines(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min * minNewlines(re.Sub[0])
+	case syntax.OpConcat:
+		n := 0
+		for _, sub := range re.Sub {
+			n += minNewlines(sub)
+		}
+		return n
+	case syntax.OpAlternate:
+		n := minNewlines(re.Sub[0])
+		for _, sub := range re.Sub[1:] {
+			n = min(n, minNewlines(sub))
+		}
+		return n
+	default:
+		return 0
+	}
+}
+
 func (b *logBuffer) Push(log *types.Log) {
 	b.buffer[b.current%b.max] = log
 	b.msg[b.current%b.max] = log.Message
@@ -132,6 +209,9 @@ func (b *logBuffer) Push(log *types.Log) {
 }
 
 func (b *logBuffer) Match(p *Pattern) []*types.Log {
+	if len(p.fields) != 0 && (b.current == 0 || !p.matchFields(b.buffer[(b.current-1)%b.max])) {
+		return nil
+	}
 	if p.lastLineOnly {
 		return b.matchLastLine(p.regexp)
 	}
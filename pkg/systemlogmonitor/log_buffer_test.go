@@ -118,3 +118,98 @@ func TestMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchFields(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		fields   map[string]string
+		logs     []*types.Log
+		expected bool
+	}{
+		{
+			name:   "fields match",
+			fields: map[string]string{"PRIORITY": "^[0-3]$", "_SYSTEMD_UNIT": "^kubelet"},
+			logs: []*types.Log{
+				{Message: "oops", Fields: map[string]string{"PRIORITY": "3", "_SYSTEMD_UNIT": "kubelet.service"}},
+			},
+			expected: true,
+		},
+		{
+			name:   "field does not match",
+			fields: map[string]string{"PRIORITY": "^[0-3]$"},
+			logs: []*types.Log{
+				{Message: "oops", Fields: map[string]string{"PRIORITY": "6"}},
+			},
+			expected: false,
+		},
+		{
+			name:   "field missing",
+			fields: map[string]string{"PRIORITY": "^[0-3]$"},
+			logs: []*types.Log{
+				{Message: "oops"},
+			},
+			expected: false,
+		},
+		{
+			name:   "only the last line is matched against fields",
+			fields: map[string]string{"PRIORITY": "^[0-3]$"},
+			logs: []*types.Log{
+				{Message: "oops", Fields: map[string]string{"PRIORITY": "3"}},
+				{Message: "oops", Fields: map[string]string{"PRIORITY": "6"}},
+			},
+			expected: false,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := CompilePattern("oops")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.fields, err = compileFields(test.fields); err != nil {
+				t.Fatal(err)
+			}
+			b := NewLogBuffer(2)
+			for _, log := range test.logs {
+				b.Push(log)
+			}
+			if got := len(b.Match(p)) != 0; got != test.expected {
+				t.Errorf("expected match %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompileFieldsRejectsInvalidExpression(t *testing.T) {
+	if _, err := compileFields(map[string]string{"PRIORITY": "["}); err == nil {
+		t.Fatal("expected invalid expression error")
+	}
+}
+
+func TestMinLines(t *testing.T) {
+	for expr, expected := range map[string]int{
+		`abc`:                 1,
+		`a\nb`:                2,
+		`a\nb\nc`:             3,
+		`(a\n)+b`:             2,
+		`(a\n)*b`:             1,
+		`(a\n)?b`:             1,
+		`(a\n){3}b`:           4,
+		`(a\n){2,}b`:          3,
+		`a\n|b`:               1,
+		`a\nb|c\nd\ne`:        2,
+		`[\n]`:                2,
+		`[\na]`:               1,
+		`.*`:                  1,
+		`(?s).*`:              1,
+		`^a\n(?P<name>b\n)c$`: 3,
+	} {
+		lines, err := minLines(expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", expr, err)
+			continue
+		}
+		if lines != expected {
+			t.Errorf("%q: expected %d lines, got %d", expr, expected, lines)
+		}
+	}
+}
@@ -23,7 +23,9 @@ import (
 	"time"
 
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
 	"k8s.io/node-problem-detector/pkg/problemdaemon"
 	"k8s.io/node-problem-detector/pkg/problemmetrics"
 	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers"
@@ -36,11 +38,22 @@ import (
 
 const SystemLogMonitorName = "system-log-monitor"
 
+// expiryCheckPeriod is the period at which log monitor resets the conditions
+// whose clearAfter duration has elapsed.
+const expiryCheckPeriod = 10 * time.Second
+
+// summaryCheckPeriod is the period at which log monitor reports the summaries
+// of the events suppressed in the cooldown windows that elapsed.
+const summaryCheckPeriod = 10 * time.Second
+
 func init() {
 	problemdaemon.Register(
 		SystemLogMonitorName,
 		types.ProblemDaemonHandler{
 			CreateProblemDaemonOrDie: NewLogMonitorOrDie,
+			CreateProblemDaemon:      NewLogMonitor,
+			ValidateConfig:           ValidateConfig,
+			ProblemMetricLabels:      ProblemMetricLabels,
 			CmdOptionDescription:     "Set to config file paths.",
 		})
 }
@@ -49,60 +62,153 @@ type logMonitor struct {
 	configPath string
 	watcher    watchertypes.LogWatcher
 	buffer     LogBuffer
-	config     MonitorConfig
-	patterns   []*Pattern
+	// blocks assembles multi-line logs. It is nil when no block is configured.
+	blocks   *blockAssembler
+	config   MonitorConfig
+	patterns []*Pattern
+	// templates are the templates of the rules, indexed like the rules. The
+	// template of a rule without reasonTemplate, messageTemplate and
+	// metricLabels is nil.
+	templates  []*ruleTemplate
+	clearAfter []time.Duration
+	// windows are the sliding windows of the rules with count set, indexed
+	// like the rules.
+	windows []*matchWindow
+	// limiters are the event limiters of the rules with cooldown set, indexed
+	// like the rules.
+	limiters   []*util.EventLimiter
 	conditions []types.Condition
-	logCh      <-chan *systemlogtypes.Log
-	output     chan *types.Status
-	tomb       *tomb.Tomb
+	// previousConditions are the conditions carried over from the log monitor
+	// this one replaces.
+	previousConditions []types.Condition
+	// expiry is the time on the clock at which each condition set by a rule
+	// with clearAfter is reset to its default.
+	expiry map[string]time.Time
+	clock  clock.WithTicker
+	logCh  <-chan *systemlogtypes.Log
+	output chan *types.Status
+	tomb   *tomb.Tomb
 }
 
 // NewLogMonitorOrDie create a new LogMonitor, panic if error occurs.
 func NewLogMonitorOrDie(configPath string) types.Monitor {
+	l, err := NewLogMonitor(configPath)
+	if err != nil {
+		klog.Fatal(err)
+	}
+	return l
+}
+
+// NewLogMonitor create a new LogMonitor, returns error if the configuration is invalid.
+func NewLogMonitor(configPath string) (types.Monitor, error) {
+	l, err := loadLogMonitor(configPath)
+	if err != nil {
+		return nil, err
+	}
+	l.config.WatcherConfig.Source = l.config.Source
+	l.watcher, err = logwatchers.GetLogWatcher(l.config.WatcherConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log watcher for %s: %v", l.configPath, err)
+	}
+
+	if *l.config.EnableMetricsReporting {
+		initializeProblemMetricsOrDie(l.config.Rules)
+	}
+	return l, nil
+}
+
+// ProblemMetricLabels returns the metricLabels of the rules in the
+// configuration file. It returns nil if the configuration is invalid, which
+// NewLogMonitor reports, or if metrics reporting is disabled.
+func ProblemMetricLabels(configPath string) []string {
+	l, err := loadLogMonitor(configPath)
+	if err != nil || !*l.config.EnableMetricsReporting {
+		return nil
+	}
+	var labels []string
+	for _, rule := range l.config.Rules {
+		labels = append(labels, rule.MetricLabels...)
+	}
+	return labels
+}
+
+// loadLogMonitor creates a log monitor without log watcher from the
+// configuration file, returns error if the configuration is invalid.
+func loadLogMonitor(configPath string) (*logMonitor, error) {
 	l := &logMonitor{
 		configPath: configPath,
+		expiry:     make(map[string]time.Time),
+		clock:      clock.RealClock{},
 		tomb:       tomb.NewTomb(),
 	}
 
 	f, err := os.ReadFile(configPath)
 	if err != nil {
-		klog.Fatalf("Failed to read configuration file %q: %v", configPath, err)
+		return nil, fmt.Errorf("failed to read configuration file %q: %v", configPath, err)
 	}
 	err = json.Unmarshal(f, &l.config)
 	if err != nil {
-		klog.Fatalf("Failed to unmarshal configuration file %q: %v", configPath, err)
+		return nil, fmt.Errorf("failed to unmarshal configuration file %q: %v", configPath, err)
 	}
 	// Apply default configurations
 	(&l.config).ApplyDefaultConfiguration()
 	l.patterns, err = l.config.compileRules()
 	if err != nil {
-		klog.Fatalf("Failed to validate %s matching rules %+v: %v", l.configPath, l.config.Rules, err)
+		return nil, fmt.Errorf("failed to validate %s matching rules %+v: %v", l.configPath, l.config.Rules, err)
+	}
+	l.templates, err = l.config.compileTemplates(l.patterns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate %s rule templates: %v", l.configPath, err)
+	}
+	if err := l.config.validateRules(); err != nil {
+		return nil, fmt.Errorf("failed to validate %s rules: %v", l.configPath, err)
+	}
+	blockPatterns, err := l.config.compileBlocks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate %s blocks: %v", l.configPath, err)
+	}
+	if blockPatterns != nil {
+		l.blocks = newBlockAssembler(blockPatterns)
+	}
+	l.clearAfter, err = l.config.parseClearAfter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate %s rules: %v", l.configPath, err)
+	}
+	l.windows, err = l.config.parseMatchWindows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate %s rules: %v", l.configPath, err)
+	}
+	l.limiters, err = l.config.parseLimiters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate %s rules: %v", l.configPath, err)
 	}
 	klog.Infof("Finish parsing log monitor config file %s: %+v", l.configPath, l.config)
 
-	l.watcher = logwatchers.GetLogWatcherOrDie(l.config.WatcherConfig)
 	l.buffer = NewLogBuffer(l.config.BufferSize)
 	// A 1000 size channel should be big enough.
 	l.output = make(chan *types.Status, 1000)
-
-	if *l.config.EnableMetricsReporting {
-		initializeProblemMetricsOrDie(l.config.Rules)
-	}
-	return l
+	return l, nil
 }
 
 // initializeProblemMetricsOrDie creates problem metrics for all problems and set the value to 0,
 // panic if error occurs.
 func initializeProblemMetricsOrDie(rules []systemlogtypes.Rule) {
 	for _, rule := range rules {
+		if rule.Type == systemlogtypes.Recovery {
+			// Recovery rules only reset conditions, they are not problems.
+			continue
+		}
+		// The severity was validated with the rules.
+		severity, _ := types.ParseSeverity(rule.Severity)
+		p := problem{reason: rule.Reason, severity: severity}
 		if rule.Type == types.Perm {
-			err := problemmetrics.GlobalProblemMetricsManager.SetProblemGauge(rule.Condition, rule.Reason, false)
+			err := problemmetrics.GlobalProblemMetricsManager.SetProblemGaugeWithSeverity(rule.Condition, rule.Reason, string(p.severity), false)
 			if err != nil {
 				klog.Fatalf("Failed to initialize problem gauge metrics for problem %q, reason %q: %v",
 					rule.Condition, rule.Reason, err)
 			}
 		}
-		err := problemmetrics.GlobalProblemMetricsManager.IncrementProblemCounter(rule.Reason, 0)
+		err := problemmetrics.GlobalProblemMetricsManager.IncrementProblemCounterWithLabels(rule.Reason, p.counterLabels(), 0)
 		if err != nil {
 			klog.Fatalf("Failed to initialize problem counter metrics for %q: %v", rule.Reason, err)
 		}
@@ -125,6 +231,14 @@ func (l *logMonitor) Stop() {
 	l.tomb.Stop()
 }
 
+func (l *logMonitor) GetConditions() []types.Condition {
+	return append([]types.Condition{}, l.conditions...)
+}
+
+func (l *logMonitor) RestoreConditions(conditions []types.Condition) {
+	l.previousConditions = conditions
+}
+
 // monitorLoop is the main loop of log monitor.
 func (l *logMonitor) monitorLoop() {
 	defer func() {
@@ -132,14 +246,57 @@ func (l *logMonitor) monitorLoop() {
 		l.tomb.Done()
 	}()
 	l.initializeStatus()
+	var expiryCh <-chan time.Time
+	for _, d := range l.clearAfter {
+		if d > 0 {
+			ticker := l.clock.NewTicker(expiryCheckPeriod)
+			defer ticker.Stop()
+			expiryCh = ticker.C()
+			break
+		}
+	}
+	var summaryCh <-chan time.Time
+	for _, limiter := range l.limiters {
+		if limiter != nil {
+			ticker := l.clock.NewTicker(summaryCheckPeriod)
+			defer ticker.Stop()
+			summaryCh = ticker.C()
+			break
+		}
+	}
+	var blockCh <-chan time.Time
+	if l.blocks != nil {
+		ticker := l.clock.NewTicker(l.blocks.checkPeriod())
+		defer ticker.Stop()
+		blockCh = ticker.C()
+	}
 	for {
 		select {
 		case log, ok := <-l.logCh:
 			if !ok {
+				if l.blocks != nil {
+					if block := l.blocks.flush(); block != nil {
+						l.parseLog(block)
+					}
+				}
 				klog.Errorf("Log channel closed: %s", l.configPath)
 				return
 			}
-			l.parseLog(log)
+			l.processLog(log)
+		case <-blockCh:
+			if block := l.blocks.flushIdle(l.clock.Now()); block != nil {
+				l.parseLog(block)
+			}
+		case <-expiryCh:
+			if status := l.expireConditions(); status != nil {
+				klog.Infof("New status generated: %+v", status)
+				l.sendStatus(status)
+			}
+		case <-summaryCh:
+			if status := l.summarizeSuppressedEvents(l.clock.Now()); status != nil {
+				klog.Infof("New status generated: %+v", status)
+				l.sendStatus(status)
+			}
 		case <-l.tomb.Stopping():
 			l.watcher.Stop()
 			klog.Infof("Log monitor stopped: %s", l.configPath)
@@ -148,6 +305,18 @@ func (l *logMonitor) monitorLoop() {
 	}
 }
 
+// processLog assembles the log into a block if blocks are configured, and
+// parses the logs that are ready.
+func (l *logMonitor) processLog(log *systemlogtypes.Log) {
+	if l.blocks == nil {
+		l.parseLog(log)
+		return
+	}
+	for _, ready := range l.blocks.push(log, l.clock.Now()) {
+		l.parseLog(ready)
+	}
+}
+
 // parseLog parses one log line.
 func (l *logMonitor) parseLog(log *systemlogtypes.Log) {
 	// Once there is new log, log monitor will push it into the log buffer and try
@@ -155,30 +324,119 @@ func (l *logMonitor) parseLog(log *systemlogtypes.Log) {
 	l.buffer.Push(log)
 	for i, rule := range l.config.Rules {
 		matched := l.buffer.Match(l.patterns[i])
+		if len(matched) != 0 {
+			if err := monitormetrics.GlobalMonitorMetricsManager.IncrementRuleMatches(l.config.Source, rule.Reason, 1); err != nil {
+				klog.Errorf("Failed to update rule match metrics for %q: %v", rule.Reason, err)
+			}
+		}
+		if i < len(l.windows) && l.windows[i] != nil {
+			// Only fire when the rule matched count times within the window.
+			matched = l.windows[i].observe(l.buffer, matched)
+		}
 		if len(matched) == 0 {
 			continue
 		}
-		status := l.generateStatus(matched, rule)
+		if i < len(l.clearAfter) && l.clearAfter[i] > 0 {
+			// The deadline follows the clock expireConditions compares it
+			// with, so that old logs read from the lookback do not expire
+			// the condition right away.
+			l.expiry[rule.Condition] = l.clock.Now().Add(l.clearAfter[i])
+		} else if rule.Type == types.Perm {
+			delete(l.expiry, rule.Condition)
+		}
+		p := defaultProblem(matched, rule)
+		if i < len(l.templates) && l.templates[i] != nil {
+			rendered, err := l.templates[i].render(matched, p)
+			if err != nil {
+				klog.Errorf("Failed to render the templates of rule %q: %v", rule.Reason, err)
+			} else {
+				p = rendered
+			}
+		}
+		status := l.generateStatus(matched, rule, p)
+		if i < len(l.limiters) && l.limiters[i] != nil && !l.limitEvents(l.limiters[i], status) {
+			klog.V(2).Infof("Event suppressed by the cooldown of rule %q: %+v", rule.Reason, status.Events)
+			continue
+		}
 		klog.Infof("New status generated: %+v", status)
-		l.output <- status
+		l.sendStatus(status)
 	}
 }
 
-// generateStatus generates status from the logs.
-func (l *logMonitor) generateStatus(logs []*systemlogtypes.Log, rule systemlogtypes.Rule) *types.Status {
+// limitEvents sends the summary of the events the limiter suppressed in its
+// window, if the window elapsed, and reports whether the event of the status
+// of a temporary rule is emitted. The windows follow the clock the summary
+// ticker uses rather than the log timestamps, so that old logs read from the
+// lookback don't open windows the ticker closes right away.
+func (l *logMonitor) limitEvents(limiter *util.EventLimiter, status *types.Status) bool {
+	now := l.clock.Now()
+	if summary, ok := limiter.Summary(now); ok {
+		l.sendStatus(&types.Status{
+			Source:     l.config.Source,
+			Events:     []types.Event{summary},
+			Conditions: l.conditions,
+		})
+	}
+	return limiter.Allow(status.Events[0], now)
+}
+
+// summarizeSuppressedEvents returns the status with the summaries of the
+// events suppressed in the cooldown windows elapsed at now. It returns nil if
+// there is no summary.
+func (l *logMonitor) summarizeSuppressedEvents(now time.Time) *types.Status {
+	var events []types.Event
+	for _, limiter := range l.limiters {
+		if limiter == nil {
+			continue
+		}
+		if summary, ok := limiter.Summary(now); ok {
+			events = append(events, summary)
+		}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return &types.Status{
+		Source:     l.config.Source,
+		Events:     events,
+		Conditions: l.conditions,
+	}
+}
+
+// sendStatus sends the status to the problem detector and records how long it
+// had to wait. The conditions of the status are copied, because they are the
+// live conditions of the log monitor, which the next logs change.
+func (l *logMonitor) sendStatus(status *types.Status) {
+	status.Conditions = append([]types.Condition(nil), status.Conditions...)
+	start := time.Now()
+	l.output <- status
+	if err := monitormetrics.GlobalMonitorMetricsManager.ObserveStatusSendLatency(l.config.Source, time.Since(start)); err != nil {
+		klog.Errorf("Failed to update status send latency metrics for %q: %v", l.config.Source, err)
+	}
+}
+
+// generateStatus generates status of the problem the rule matched in the logs.
+func (l *logMonitor) generateStatus(logs []*systemlogtypes.Log, rule systemlogtypes.Rule, p problem) *types.Status {
 	// We use the timestamp of the first log line as the timestamp of the status.
 	timestamp := logs[0].Timestamp
-	message := generateMessage(logs, rule.PatternGeneratedMessageSuffix)
+	message := p.message
 	var events []types.Event
+	var inactiveProblemEvents []types.Event
 	var changedConditions []*types.Condition
 	if rule.Type == types.Temp {
 		// For temporary error only generate event
 		events = append(events, types.Event{
-			Severity:  types.Warn,
+			Severity:  p.eventSeverity(),
 			Timestamp: timestamp,
-			Reason:    rule.Reason,
+			Reason:    p.reason,
 			Message:   message,
 		})
+	} else if rule.Type == systemlogtypes.Recovery {
+		// For recovery reset the condition back to its default
+		if condition, event := l.resetCondition(rule.Condition, timestamp); condition != nil {
+			inactiveProblemEvents = append(inactiveProblemEvents, *event)
+			changedConditions = append(changedConditions, condition)
+		}
 	} else {
 		// For permanent error changes the condition
 		for i := range l.conditions {
@@ -187,68 +445,148 @@ func (l *logMonitor) generateStatus(logs []*systemlogtypes.Log, rule systemlogty
 				// Update transition timestamp and message when the condition
 				// changes. Condition is considered to be changed only when
 				// status or reason changes.
-				if condition.Status == types.False || condition.Reason != rule.Reason {
+				if condition.Status == types.False || condition.Reason != p.reason {
 					condition.Transition = timestamp
 					condition.Message = message
-					events = append(events, util.GenerateConditionChangeEvent(
+					event := util.GenerateConditionChangeEvent(
 						condition.Type,
 						types.True,
-						rule.Reason,
+						p.reason,
 						message,
 						timestamp,
-					))
+					)
+					event.Severity = p.eventSeverity()
+					events = append(events, event)
 				}
 				condition.Status = types.True
-				condition.Reason = rule.Reason
+				condition.Reason = p.reason
+				condition.Severity = p.severity
 				changedConditions = append(changedConditions, condition)
 				break
 			}
 		}
 	}
 
-	if *l.config.EnableMetricsReporting {
-		for _, event := range events {
-			err := problemmetrics.GlobalProblemMetricsManager.IncrementProblemCounter(event.Reason, 1)
-			if err != nil {
-				klog.Errorf("Failed to update problem counter metrics for %q: %v", event.Reason, err)
-			}
-		}
-		for _, condition := range changedConditions {
-			err := problemmetrics.GlobalProblemMetricsManager.SetProblemGauge(
-				condition.Type, condition.Reason, condition.Status == types.True)
-			if err != nil {
-				klog.Errorf("Failed to update problem gauge metrics for problem %q, reason %q: %v",
-					condition.Type, condition.Reason, err)
-			}
-		}
-	}
+	l.reportMetrics(events, changedConditions, p.counterLabels())
 
 	return &types.Status{
 		Source: l.config.Source,
 		// TODO(random-liu): Aggregate events and conditions and then do periodically report.
+		Events:     append(events, inactiveProblemEvents...),
+		Conditions: l.conditions,
+	}
+}
+
+// expireConditions resets the conditions whose clearAfter duration has elapsed
+// since the rule that set them last matched. It returns nil if no condition changed.
+func (l *logMonitor) expireConditions() *types.Status {
+	now := l.clock.Now()
+	var events []types.Event
+	var changedConditions []*types.Condition
+	for conditionType, deadline := range l.expiry {
+		if now.Before(deadline) {
+			continue
+		}
+		delete(l.expiry, conditionType)
+		if condition, event := l.resetCondition(conditionType, now); condition != nil {
+			events = append(events, *event)
+			changedConditions = append(changedConditions, condition)
+		}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	l.reportMetrics(nil, changedConditions, nil)
+	return &types.Status{
+		Source:     l.config.Source,
 		Events:     events,
 		Conditions: l.conditions,
 	}
 }
 
+// resetCondition sets a true condition back to its default reason and message,
+// and returns the changed condition with its condition change event. It returns
+// nil if the condition is not true.
+func (l *logMonitor) resetCondition(conditionType string, timestamp time.Time) (*types.Condition, *types.Event) {
+	delete(l.expiry, conditionType)
+	for i := range l.conditions {
+		condition := &l.conditions[i]
+		if condition.Type != conditionType {
+			continue
+		}
+		if condition.Status != types.True {
+			return nil, nil
+		}
+		for _, defaultCondition := range l.config.DefaultConditions {
+			if defaultCondition.Type == conditionType {
+				condition.Reason = defaultCondition.Reason
+				condition.Message = defaultCondition.Message
+				break
+			}
+		}
+		condition.Status = types.False
+		condition.Severity = ""
+		condition.Transition = timestamp
+		event := util.GenerateConditionChangeEvent(
+			condition.Type,
+			types.False,
+			condition.Reason,
+			condition.Message,
+			timestamp,
+		)
+		return condition, &event
+	}
+	return nil, nil
+}
+
+// reportMetrics increments the problem counter with the extra labels for each
+// active problem event and updates the problem gauge for each changed condition.
+func (l *logMonitor) reportMetrics(activeProblemEvents []types.Event, changedConditions []*types.Condition, labels map[string]string) {
+	if !*l.config.EnableMetricsReporting {
+		return
+	}
+	for _, event := range activeProblemEvents {
+		err := problemmetrics.GlobalProblemMetricsManager.IncrementProblemCounterWithLabels(event.Reason, labels, 1)
+		if err != nil {
+			klog.Errorf("Failed to update problem counter metrics for %q: %v", event.Reason, err)
+		}
+	}
+	for _, condition := range changedConditions {
+		err := problemmetrics.GlobalProblemMetricsManager.SetProblemGaugeWithSeverity(
+			condition.Type, condition.Reason, string(condition.Severity), condition.Status == types.True)
+		if err != nil {
+			klog.Errorf("Failed to update problem gauge metrics for problem %q, reason %q: %v",
+				condition.Type, condition.Reason, err)
+		}
+	}
+}
+
 // initializeStatus initializes the internal condition and also reports it to the node problem detector.
 func (l *logMonitor) initializeStatus() {
 	// Initialize the default node conditions
-	l.conditions = initialConditions(l.config.DefaultConditions)
+	l.conditions = initialConditions(l.config.DefaultConditions, l.clock.Now())
+	if len(l.previousConditions) != 0 {
+		l.conditions = util.CarryOverConditions(l.conditions, l.previousConditions)
+		var restored []*types.Condition
+		for i := range l.conditions {
+			restored = append(restored, &l.conditions[i])
+		}
+		l.reportMetrics(nil, restored, nil)
+	}
 	klog.Infof("Initialize condition generated: %+v", l.conditions)
 	// Update the initial status
-	l.output <- &types.Status{
+	l.sendStatus(&types.Status{
 		Source:     l.config.Source,
 		Conditions: l.conditions,
-	}
+	})
 }
 
-func initialConditions(defaults []types.Condition) []types.Condition {
+func initialConditions(defaults []types.Condition, now time.Time) []types.Condition {
 	conditions := make([]types.Condition, len(defaults))
 	copy(conditions, defaults)
 	for i := range conditions {
 		conditions[i].Status = types.False
-		conditions[i].Transition = time.Now()
+		conditions[i].Transition = now
 	}
 	return conditions
 }
@@ -258,9 +596,13 @@ func generateMessage(logs []*systemlogtypes.Log, patternGeneratedMessageSuffix s
 	for _, log := range logs {
 		messages = append(messages, log.Message)
 	}
-	logMessage := concatLogs(messages)
+	return appendMessageSuffix(concatLogs(messages), patternGeneratedMessageSuffix)
+}
+
+// appendMessageSuffix appends the patternGeneratedMessageSuffix of a rule to the message.
+func appendMessageSuffix(message, patternGeneratedMessageSuffix string) string {
 	if patternGeneratedMessageSuffix != "" {
-		return fmt.Sprintf("%s; %s", logMessage, patternGeneratedMessageSuffix)
+		return fmt.Sprintf("%s; %s", message, patternGeneratedMessageSuffix)
 	}
-	return logMessage
+	return message
 }
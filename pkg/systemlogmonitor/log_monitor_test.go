@@ -17,12 +17,16 @@ limitations under the License.
 package systemlogmonitor
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	testclock "k8s.io/utils/clock/testing"
 
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
 	"k8s.io/node-problem-detector/pkg/problemdaemon"
 	"k8s.io/node-problem-detector/pkg/problemmetrics"
 	systemlogtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
@@ -145,13 +149,618 @@ func TestGenerateStatusForConditions(t *testing.T) {
 			conditions: append([]types.Condition{}, initConditions...),
 		}
 		(&l.config).ApplyDefaultConfiguration()
-		got := l.generateStatus(logs, test.rule)
+		got := l.generateStatus(logs, test.rule, defaultProblem(logs, test.rule))
 		if !reflect.DeepEqual(&test.expected, got) {
 			t.Errorf("case %d: expected status %+v, got %+v", c+1, test.expected, got)
 		}
 	}
 }
 
+func TestGenerateStatusForRecovery(t *testing.T) {
+	defaultConditions := []types.Condition{
+		{
+			Type:    testConditionA,
+			Reason:  "default reason",
+			Message: "default message",
+		},
+	}
+	logs := []*systemlogtypes.Log{
+		{
+			Timestamp: time.Unix(1000, 1000),
+			Message:   "recovered",
+		},
+	}
+	recovery := systemlogtypes.Rule{
+		Type:      systemlogtypes.Recovery,
+		Condition: testConditionA,
+		Reason:    "recovery reason",
+	}
+	for _, test := range []struct {
+		name      string
+		condition types.Condition
+		expected  types.Status
+	}{
+		{
+			name: "true condition is reset to default",
+			condition: types.Condition{
+				Type:       testConditionA,
+				Status:     types.True,
+				Transition: time.Unix(500, 500),
+				Reason:     "problem reason",
+				Message:    "problem message",
+			},
+			expected: types.Status{
+				Source: testSource,
+				Events: []types.Event{util.GenerateConditionChangeEvent(
+					testConditionA,
+					types.False,
+					"default reason",
+					"default message",
+					time.Unix(1000, 1000),
+				)},
+				Conditions: []types.Condition{
+					{
+						Type:       testConditionA,
+						Status:     types.False,
+						Transition: time.Unix(1000, 1000),
+						Reason:     "default reason",
+						Message:    "default message",
+					},
+				},
+			},
+		},
+		{
+			name: "false condition is not changed",
+			condition: types.Condition{
+				Type:       testConditionA,
+				Status:     types.False,
+				Transition: time.Unix(500, 500),
+				Reason:     "default reason",
+				Message:    "default message",
+			},
+			expected: types.Status{
+				Source: testSource,
+				Conditions: []types.Condition{
+					{
+						Type:       testConditionA,
+						Status:     types.False,
+						Transition: time.Unix(500, 500),
+						Reason:     "default reason",
+						Message:    "default message",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			l := &logMonitor{
+				config: MonitorConfig{
+					Source:            testSource,
+					DefaultConditions: defaultConditions,
+				},
+				conditions: []types.Condition{test.condition},
+				expiry:     map[string]time.Time{testConditionA: time.Unix(2000, 0)},
+			}
+			(&l.config).ApplyDefaultConfiguration()
+			got := l.generateStatus(logs, recovery, defaultProblem(logs, recovery))
+			assert.Equal(t, &test.expected, got)
+			assert.Empty(t, l.expiry, "recovery should cancel the pending expiry")
+		})
+	}
+}
+
+func TestExpireConditions(t *testing.T) {
+	fakeClock := testclock.NewFakeClock(time.Unix(1000, 0))
+	l := &logMonitor{
+		config: MonitorConfig{
+			Source: testSource,
+			DefaultConditions: []types.Condition{
+				{
+					Type:    testConditionA,
+					Reason:  "default reason A",
+					Message: "default message A",
+				},
+				{
+					Type:    testConditionB,
+					Reason:  "default reason B",
+					Message: "default message B",
+				},
+			},
+		},
+		conditions: []types.Condition{
+			{
+				Type:       testConditionA,
+				Status:     types.True,
+				Transition: time.Unix(500, 0),
+				Reason:     "problem reason A",
+			},
+			{
+				Type:       testConditionB,
+				Status:     types.True,
+				Transition: time.Unix(500, 0),
+				Reason:     "problem reason B",
+			},
+		},
+		expiry: map[string]time.Time{
+			testConditionA: time.Unix(1100, 0),
+			testConditionB: time.Unix(1200, 0),
+		},
+		clock: fakeClock,
+	}
+	(&l.config).ApplyDefaultConfiguration()
+
+	assert.Nil(t, l.expireConditions(), "no condition should expire before its deadline")
+
+	fakeClock.SetTime(time.Unix(1100, 0))
+	got := l.expireConditions()
+	expected := &types.Status{
+		Source: testSource,
+		Events: []types.Event{util.GenerateConditionChangeEvent(
+			testConditionA,
+			types.False,
+			"default reason A",
+			"default message A",
+			time.Unix(1100, 0),
+		)},
+		Conditions: []types.Condition{
+			{
+				Type:       testConditionA,
+				Status:     types.False,
+				Transition: time.Unix(1100, 0),
+				Reason:     "default reason A",
+				Message:    "default message A",
+			},
+			{
+				Type:       testConditionB,
+				Status:     types.True,
+				Transition: time.Unix(500, 0),
+				Reason:     "problem reason B",
+			},
+		},
+	}
+	assert.Equal(t, expected, got)
+	assert.Equal(t, map[string]time.Time{testConditionB: time.Unix(1200, 0)}, l.expiry)
+}
+
+func TestParseLogSetsExpiry(t *testing.T) {
+	rules := []systemlogtypes.Rule{
+		{
+			Type:       types.Perm,
+			Condition:  testConditionA,
+			Reason:     "expiring reason",
+			Pattern:    "expiring problem",
+			ClearAfter: "10m",
+		},
+		{
+			Type:      types.Perm,
+			Condition: testConditionA,
+			Reason:    "sticky reason",
+			Pattern:   "sticky problem",
+		},
+	}
+	l := &logMonitor{
+		config: MonitorConfig{
+			Source:            testSource,
+			DefaultConditions: []types.Condition{{Type: testConditionA}},
+			Rules:             rules,
+		},
+		buffer: NewLogBuffer(1),
+		expiry: make(map[string]time.Time),
+		output: make(chan *types.Status, 10),
+		clock:  testclock.NewFakeClock(time.Unix(5000, 0)),
+	}
+	(&l.config).ApplyDefaultConfiguration()
+	var err error
+	l.patterns, err = l.config.compileRules()
+	assert.NoError(t, err)
+	l.clearAfter, err = l.config.parseClearAfter()
+	assert.NoError(t, err)
+	l.conditions = initialConditions(l.config.DefaultConditions, time.Now())
+
+	// The log is older than clearAfter, e.g. read from the lookback, and the
+	// condition still expires clearAfter from now.
+	l.parseLog(&systemlogtypes.Log{Timestamp: time.Unix(1000, 0), Message: "expiring problem"})
+	assert.Equal(t, map[string]time.Time{testConditionA: time.Unix(5600, 0)}, l.expiry)
+	assert.Nil(t, l.expireConditions())
+
+	l.parseLog(&systemlogtypes.Log{Timestamp: time.Unix(1100, 0), Message: "sticky problem"})
+	assert.Empty(t, l.expiry, "a rule without clearAfter should make the condition sticky")
+}
+
+func TestParseLogRecordsMonitorMetrics(t *testing.T) {
+	original := monitormetrics.GlobalMonitorMetricsManager
+	t.Cleanup(func() { monitormetrics.GlobalMonitorMetricsManager = original })
+	mmm, stub := monitormetrics.NewMonitorMetricsManagerStub()
+	monitormetrics.GlobalMonitorMetricsManager = mmm
+
+	l := &logMonitor{
+		config: MonitorConfig{
+			Source: testSource,
+			Rules: []systemlogtypes.Rule{
+				{Type: types.Temp, Reason: "frequent reason", Pattern: "frequent problem", Count: 2, Window: "1m"},
+			},
+		},
+		buffer: NewLogBuffer(1),
+		expiry: make(map[string]time.Time),
+		output: make(chan *types.Status, 10),
+	}
+	(&l.config).ApplyDefaultConfiguration()
+	var err error
+	l.patterns, err = l.config.compileRules()
+	assert.NoError(t, err)
+	l.windows, err = l.config.parseMatchWindows()
+	assert.NoError(t, err)
+
+	l.parseLog(&systemlogtypes.Log{Timestamp: time.Unix(1000, 0), Message: "frequent problem"})
+	l.parseLog(&systemlogtypes.Log{Timestamp: time.Unix(1001, 0), Message: "unrelated"})
+	l.parseLog(&systemlogtypes.Log{Timestamp: time.Unix(1002, 0), Message: "frequent problem"})
+
+	// Every match is counted, not only the one that fires the rule.
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: string(metrics.RuleMatchesID), Labels: map[string]string{"source": testSource, "reason": "frequent reason"}, Value: 2},
+	}, stub.RuleMatches.ListMetrics())
+	assert.Len(t, l.output, 1)
+	gotLatency := stub.StatusSendLatency.ListMetrics()
+	assert.Len(t, gotLatency, 1)
+	assert.Equal(t, map[string]string{"source": testSource}, gotLatency[0].Labels)
+}
+
+func TestParseLogLimitsEvents(t *testing.T) {
+	original := problemmetrics.GlobalProblemMetricsManager
+	t.Cleanup(func() { problemmetrics.GlobalProblemMetricsManager = original })
+	fakePMM, fakeProblemCounter, _ := problemmetrics.NewProblemMetricsManagerStub()
+	problemmetrics.GlobalProblemMetricsManager = fakePMM
+	fakeClock := testclock.NewFakeClock(time.Unix(1000, 0))
+
+	l := &logMonitor{
+		config: MonitorConfig{
+			Source: testSource,
+			Rules: []systemlogtypes.Rule{
+				{Type: types.Temp, Reason: "OOMKilling", Pattern: "Killed process .*", Cooldown: "5m", MaxEventsPerWindow: 2},
+			},
+		},
+		buffer: NewLogBuffer(1),
+		expiry: make(map[string]time.Time),
+		clock:  fakeClock,
+		output: make(chan *types.Status, 10),
+	}
+	(&l.config).ApplyDefaultConfiguration()
+	var err error
+	l.patterns, err = l.config.compileRules()
+	assert.NoError(t, err)
+	l.limiters, err = l.config.parseLimiters()
+	assert.NoError(t, err)
+
+	for i := range 5 {
+		fakeClock.SetTime(time.Unix(1000+int64(i)*60, 0))
+		l.parseLog(&systemlogtypes.Log{Timestamp: fakeClock.Now(), Message: fmt.Sprintf("Killed process %d", i)})
+	}
+	assert.Len(t, l.output, 2, "only the first two events of the window should be emitted")
+	assert.Nil(t, l.summarizeSuppressedEvents(time.Unix(1299, 0)))
+
+	// The next event after the window elapsed sends the summary first.
+	fakeClock.SetTime(time.Unix(1300, 0))
+	l.parseLog(&systemlogtypes.Log{Timestamp: time.Unix(1300, 0), Message: "Killed process 5"})
+	<-l.output
+	<-l.output
+	summary := <-l.output
+	assert.Equal(t, []types.Event{{
+		Severity:  types.Warn,
+		Timestamp: time.Unix(1300, 0),
+		Reason:    "OOMKilling",
+		Message:   "Killed process 4 (and 2 more in the last 5m0s)",
+	}}, summary.Events)
+	status := <-l.output
+	assert.Equal(t, "Killed process 5", status.Events[0].Message)
+
+	// Every occurrence is counted, including the suppressed ones.
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_counter", Labels: map[string]string{"reason": "OOMKilling"}, Value: 6},
+	}, fakeProblemCounter.ListMetrics())
+}
+
+func TestParseLogLimitsLookbackEvents(t *testing.T) {
+	l := &logMonitor{
+		config: MonitorConfig{
+			Source: testSource,
+			Rules: []systemlogtypes.Rule{
+				{Type: types.Temp, Reason: "OOMKilling", Pattern: "Killed process .*", Cooldown: "5m"},
+			},
+		},
+		buffer: NewLogBuffer(1),
+		expiry: make(map[string]time.Time),
+		clock:  testclock.NewFakeClock(time.Unix(5000, 0)),
+		output: make(chan *types.Status, 10),
+	}
+	(&l.config).ApplyDefaultConfiguration()
+	enableMetricsReporting := false
+	l.config.EnableMetricsReporting = &enableMetricsReporting
+	var err error
+	l.patterns, err = l.config.compileRules()
+	assert.NoError(t, err)
+	l.limiters, err = l.config.parseLimiters()
+	assert.NoError(t, err)
+
+	// The logs read from the lookback are further apart than the cooldown,
+	// but they are all read in the window opened now.
+	for i := range 3 {
+		l.parseLog(&systemlogtypes.Log{Timestamp: time.Unix(1000+int64(i)*600, 0), Message: fmt.Sprintf("Killed process %d", i)})
+	}
+	assert.Len(t, l.output, 1)
+	assert.Nil(t, l.summarizeSuppressedEvents(l.clock.Now()), "the window must not be closed before the cooldown elapsed")
+	status := l.summarizeSuppressedEvents(time.Unix(5300, 0))
+	if assert.NotNil(t, status) {
+		assert.Equal(t, "Killed process 2 (and 1 more in the last 5m0s)", status.Events[0].Message)
+	}
+}
+
+func TestSummarizeSuppressedEvents(t *testing.T) {
+	l := &logMonitor{
+		config: MonitorConfig{Source: testSource},
+		limiters: []*util.EventLimiter{
+			nil,
+			util.NewEventLimiter(time.Minute, 1),
+		},
+	}
+	event := types.Event{Reason: "OOMKilling", Timestamp: time.Unix(1000, 0), Message: "Killed process"}
+	assert.True(t, l.limiters[1].Allow(event, time.Unix(1000, 0)))
+	assert.False(t, l.limiters[1].Allow(event, time.Unix(1000, 0)))
+
+	assert.Nil(t, l.summarizeSuppressedEvents(time.Unix(1059, 0)))
+	status := l.summarizeSuppressedEvents(time.Unix(1060, 0))
+	if !assert.NotNil(t, status) {
+		return
+	}
+	assert.Equal(t, testSource, status.Source)
+	assert.Equal(t, []types.Event{{Reason: "OOMKilling", Timestamp: time.Unix(1060, 0), Message: "Killed process"}}, status.Events)
+	assert.Nil(t, l.summarizeSuppressedEvents(time.Unix(1120, 0)))
+}
+
+func TestParseRuleLimiter(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		rule        systemlogtypes.Rule
+		wantLimiter bool
+		wantErr     bool
+	}{
+		{
+			name: "rule without cooldown",
+			rule: systemlogtypes.Rule{Type: types.Temp},
+		},
+		{
+			name:        "temporary rule with cooldown",
+			rule:        systemlogtypes.Rule{Type: types.Temp, Cooldown: "5m", MaxEventsPerWindow: 3},
+			wantLimiter: true,
+		},
+		{
+			name:    "permanent rule with cooldown",
+			rule:    systemlogtypes.Rule{Type: types.Perm, Condition: testConditionA, Cooldown: "5m"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid cooldown",
+			rule:    systemlogtypes.Rule{Type: types.Temp, Cooldown: "5 minutes"},
+			wantErr: true,
+		},
+		{
+			name:    "zero cooldown",
+			rule:    systemlogtypes.Rule{Type: types.Temp, Cooldown: "0s"},
+			wantErr: true,
+		},
+		{
+			name:    "negative maxEventsPerWindow",
+			rule:    systemlogtypes.Rule{Type: types.Temp, Cooldown: "5m", MaxEventsPerWindow: -1},
+			wantErr: true,
+		},
+		{
+			name:    "maxEventsPerWindow without cooldown",
+			rule:    systemlogtypes.Rule{Type: types.Temp, MaxEventsPerWindow: 3},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			limiter, err := parseRuleLimiter(test.rule)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantLimiter, limiter != nil)
+		})
+	}
+}
+
+func TestProcessLogAssemblesBlocks(t *testing.T) {
+	fakeClock := testclock.NewFakeClock(time.Unix(1000, 0))
+	l := &logMonitor{
+		config: MonitorConfig{
+			Source: testSource,
+			Blocks: []systemlogtypes.Block{{
+				Start: `-+\[ cut here \]-+`,
+				End:   `---\[ end trace [0-9a-f]+ \]---`,
+			}},
+			Rules: []systemlogtypes.Rule{
+				{Type: types.Temp, Reason: "KernelWarning", Pattern: `-+\[ cut here \]-+\nWARNING: [\s\S]*`},
+			},
+		},
+		buffer: NewLogBuffer(1),
+		expiry: make(map[string]time.Time),
+		clock:  fakeClock,
+		output: make(chan *types.Status, 10),
+	}
+	(&l.config).ApplyDefaultConfiguration()
+	var err error
+	l.patterns, err = l.config.compileRules()
+	assert.NoError(t, err)
+	blockPatterns, err := l.config.compileBlocks()
+	assert.NoError(t, err)
+	l.blocks = newBlockAssembler(blockPatterns)
+
+	lines := []string{
+		"------------[ cut here ]------------",
+		"WARNING: CPU: 1 PID: 1 at fs/inode.c:10",
+		"Call Trace:",
+		" dump_stack+0x10/0x20",
+		"---[ end trace 1a2b3c ]---",
+	}
+	for i, line := range lines {
+		l.processLog(&systemlogtypes.Log{Timestamp: time.Unix(int64(1000+i), 0), Message: line})
+	}
+
+	assert.Len(t, l.output, 1)
+	status := <-l.output
+	assert.Len(t, status.Events, 1)
+	assert.Equal(t, "KernelWarning", status.Events[0].Reason)
+	assert.Equal(t, strings.Join(lines, "\n"), status.Events[0].Message)
+	assert.Equal(t, time.Unix(1000, 0), status.Events[0].Timestamp)
+}
+
+func TestParseLogRendersRuleTemplates(t *testing.T) {
+	original := problemmetrics.GlobalProblemMetricsManager
+	t.Cleanup(func() { problemmetrics.GlobalProblemMetricsManager = original })
+	fakePMM, fakeProblemCounter, _ := problemmetrics.NewProblemMetricsManagerStub()
+	problemmetrics.GlobalProblemMetricsManager = fakePMM
+	assert.NoError(t, fakePMM.DeclareProblemLabels([]string{"process"}))
+
+	l := &logMonitor{
+		config: MonitorConfig{
+			Source: testSource,
+			Rules: []systemlogtypes.Rule{
+				{
+					Type:            types.Temp,
+					Reason:          "OOMKilling",
+					Pattern:         `Killed process (?P<pid>\d+) \((?P<process>.+)\) total-vm:\d+kB, anon-rss:(?P<anonRSS>\d+)kB.*`,
+					MessageTemplate: "Killed process {{.process}} (pid {{.pid}}), anon-rss {{.anonRSS}}kB",
+					MetricLabels:    []string{"process"},
+				},
+			},
+		},
+		buffer: NewLogBuffer(1),
+		expiry: make(map[string]time.Time),
+		output: make(chan *types.Status, 10),
+	}
+	(&l.config).ApplyDefaultConfiguration()
+	var err error
+	l.patterns, err = l.config.compileRules()
+	assert.NoError(t, err)
+	l.templates, err = l.config.compileTemplates(l.patterns)
+	assert.NoError(t, err)
+	initializeProblemMetricsOrDie(l.config.Rules)
+
+	l.parseLog(&systemlogtypes.Log{
+		Timestamp: time.Unix(1000, 0),
+		Message:   "Killed process 1234 (nginx) total-vm:4096kB, anon-rss:2048kB, file-rss:0kB",
+	})
+
+	status := <-l.output
+	assert.Equal(t, []types.Event{{
+		Severity:  types.Warn,
+		Timestamp: time.Unix(1000, 0),
+		Reason:    "OOMKilling",
+		Message:   "Killed process nginx (pid 1234), anon-rss 2048kB",
+	}}, status.Events)
+	assert.ElementsMatch(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_counter", Labels: map[string]string{"reason": "OOMKilling"}, Value: 1},
+	}, fakeProblemCounter.ListMetrics())
+	assert.ElementsMatch(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_label_counter", Labels: map[string]string{"reason": "OOMKilling", "process": "nginx"}, Value: 1},
+	}, fakePMM.FakeProblemLabelCounter().ListMetrics())
+}
+
+func TestParseLogReportsRuleSeverity(t *testing.T) {
+	original := problemmetrics.GlobalProblemMetricsManager
+	t.Cleanup(func() { problemmetrics.GlobalProblemMetricsManager = original })
+	fakePMM, fakeProblemCounter, fakeProblemGauge := problemmetrics.NewProblemMetricsManagerStub()
+	problemmetrics.GlobalProblemMetricsManager = fakePMM
+
+	l := &logMonitor{
+		config: MonitorConfig{
+			Source:            testSource,
+			DefaultConditions: []types.Condition{{Type: testConditionA, Status: types.False, Reason: "Fine"}},
+			Rules: []systemlogtypes.Rule{
+				{
+					Type:      types.Perm,
+					Condition: testConditionA,
+					Reason:    "Deadlock",
+					Severity:  "critical",
+					Pattern:   "task hung",
+				},
+			},
+		},
+		buffer: NewLogBuffer(1),
+		expiry: make(map[string]time.Time),
+		output: make(chan *types.Status, 10),
+	}
+	(&l.config).ApplyDefaultConfiguration()
+	var err error
+	l.patterns, err = l.config.compileRules()
+	assert.NoError(t, err)
+	l.templates, err = l.config.compileTemplates(l.patterns)
+	assert.NoError(t, err)
+	l.conditions = initialConditions(l.config.DefaultConditions, time.Now())
+	initializeProblemMetricsOrDie(l.config.Rules)
+
+	l.parseLog(&systemlogtypes.Log{Timestamp: time.Unix(1000, 0), Message: "task hung"})
+
+	status := <-l.output
+	assert.Len(t, status.Events, 1)
+	assert.Equal(t, types.Critical, status.Events[0].Severity)
+	assert.Equal(t, types.Critical, status.Conditions[0].Severity)
+	assert.ElementsMatch(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_counter", Labels: map[string]string{"reason": "Deadlock", "severity": "critical"}, Value: 1},
+	}, fakeProblemCounter.ListMetrics())
+	assert.ElementsMatch(t, []metrics.Int64MetricRepresentation{
+		{Name: "problem_gauge", Labels: map[string]string{"type": testConditionA, "reason": "Deadlock", "severity": "critical"}, Value: 1},
+	}, fakeProblemGauge.ListMetrics())
+}
+
+func TestValidateRules(t *testing.T) {
+	defaults := []types.Condition{{Type: testConditionA}}
+	for _, test := range []struct {
+		name    string
+		rule    systemlogtypes.Rule
+		wantErr bool
+	}{
+		{
+			name: "recovery rule with default condition",
+			rule: systemlogtypes.Rule{Type: systemlogtypes.Recovery, Condition: testConditionA},
+		},
+		{
+			name:    "recovery rule without default condition",
+			rule:    systemlogtypes.Rule{Type: systemlogtypes.Recovery, Condition: testConditionB},
+			wantErr: true,
+		},
+		{
+			name: "permanent rule with clearAfter",
+			rule: systemlogtypes.Rule{Type: types.Perm, Condition: testConditionA, ClearAfter: "1h"},
+		},
+		{
+			name:    "temporary rule with clearAfter",
+			rule:    systemlogtypes.Rule{Type: types.Temp, ClearAfter: "1h"},
+			wantErr: true,
+		},
+		{
+			name: "rule with severity",
+			rule: systemlogtypes.Rule{Type: types.Temp, Severity: "warning"},
+		},
+		{
+			name:    "rule with unknown severity",
+			rule:    systemlogtypes.Rule{Type: types.Temp, Severity: "fatal"},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			mc := MonitorConfig{DefaultConditions: defaults, Rules: []systemlogtypes.Rule{test.rule}}
+			err := mc.validateRules()
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestGenerateStatusForMetrics(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -380,6 +989,39 @@ func TestGenerateStatusForMetrics(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "one permanent problem observed and then recovered",
+			conditions: []types.Condition{
+				{
+					Type:   "ConditionA",
+					Status: types.False,
+				},
+			},
+			triggeredRules: []systemlogtypes.Rule{
+				{
+					Type:      types.Perm,
+					Condition: "ConditionA",
+					Reason:    "problem reason foo",
+				},
+				{
+					Type:      systemlogtypes.Recovery,
+					Condition: "ConditionA",
+					Reason:    "recovery reason",
+				},
+			},
+			expectedMetrics: []metrics.Int64MetricRepresentation{
+				{
+					Name:   "problem_gauge",
+					Labels: map[string]string{"type": "ConditionA", "reason": "problem reason foo"},
+					Value:  0,
+				},
+				{
+					Name:   "problem_counter",
+					Labels: map[string]string{"reason": "problem reason foo"},
+					Value:  1,
+				},
+			},
+		},
 	}
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
@@ -396,7 +1038,7 @@ func TestGenerateStatusForMetrics(t *testing.T) {
 			problemmetrics.GlobalProblemMetricsManager = fakePMM
 
 			for _, rule := range test.triggeredRules {
-				l.generateStatus([]*systemlogtypes.Log{{}}, rule)
+				l.generateStatus([]*systemlogtypes.Log{{}}, rule, defaultProblem([]*systemlogtypes.Log{{}}, rule))
 			}
 
 			gotMetrics := append(fakeProblemCounter.ListMetrics(), fakeProblemGauge.ListMetrics()...)
@@ -0,0 +1,300 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/syslogparser"
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+// Built-in log line formats supported by the format key of the plugin configuration.
+const (
+	// rfc3164Format is the BSD syslog format written by rsyslog and syslog-ng,
+	// e.g. "Jan  2 15:04:05 host kernel: message". The high precision RFC3339
+	// timestamp of rsyslog is also accepted.
+	rfc3164Format = "rfc3164"
+	// rfc5424Format is the IETF syslog format, e.g.
+	// "<34>1 2006-01-02T15:04:05Z host app 123 ID47 [id key="value"] message".
+	rfc5424Format = "rfc5424"
+	// jsonLinesFormat is one JSON object per line, e.g. the structured logs of
+	// slog, logrus or zap.
+	jsonLinesFormat = "jsonlines"
+	// klogFormat is the header format of klog and glog, e.g.
+	// "I0102 15:04:05.000000    1234 file.go:10] message".
+	klogFormat = "klog"
+	// criFormat is the container log format of CRI runtimes, e.g.
+	// "2006-01-02T15:04:05.999999999Z stdout F message".
+	criFormat = "cri"
+)
+
+const (
+	// jsonTimestampKeyKey is the key of the JSON key holding the timestamp in the plugin configuration.
+	jsonTimestampKeyKey = "jsonTimestampKey"
+	// jsonMessageKeyKey is the key of the JSON key holding the message in the plugin configuration.
+	jsonMessageKeyKey = "jsonMessageKey"
+	// jsonTimestampFormatKey is the key of the format of string JSON timestamps in the plugin configuration.
+	jsonTimestampFormatKey = "jsonTimestampFormat"
+
+	defaultJSONTimestampKey    = "time"
+	defaultJSONMessageKey      = "msg"
+	defaultJSONTimestampFormat = time.RFC3339Nano
+)
+
+// Layouts of the timestamps of the built-in formats.
+const klogTimestampFormat = "0102 15:04:05.000000"
+
+var (
+	klogRegexp = regexp.MustCompile(`^([IWEF])(\d{4} \d{2}:\d{2}:\d{2}\.\d{6})\s+(\d+) ([^:\]\s]+):(\d+)\] ?(.*)$`)
+	criRegexp  = regexp.MustCompile(`^(\S+) (stdout|stderr) ([FP])(?: (.*))?$`)
+)
+
+// klogPriorities maps the klog severities to the syslog priorities.
+var klogPriorities = map[string]string{
+	"I": "6",
+	"W": "4",
+	"E": "3",
+	"F": "2",
+}
+
+// lineParser parses a log line of a built-in format into internal type.
+type lineParser func(line string) (*logtypes.Log, error)
+
+// newLineParser returns the parser of the built-in format.
+func newLineParser(format string, cfg map[string]string, timestamps *syslogparser.TimestampParser) (lineParser, error) {
+	switch format {
+	case rfc3164Format:
+		return func(line string) (*logtypes.Log, error) {
+			return syslogparser.ParseRFC3164(timestamps, line)
+		}, nil
+	case rfc5424Format:
+		return syslogparser.ParseRFC5424, nil
+	case jsonLinesFormat:
+		p := &jsonLinesParser{
+			timestampKey:     defaultJSONTimestampKey,
+			messageKey:       defaultJSONMessageKey,
+			timestampFormats: []string{defaultJSONTimestampFormat},
+			timestamps:       timestamps,
+		}
+		if key := cfg[jsonTimestampKeyKey]; key != "" {
+			p.timestampKey = key
+		}
+		if key := cfg[jsonMessageKeyKey]; key != "" {
+			p.messageKey = key
+		}
+		if format := cfg[jsonTimestampFormatKey]; format != "" {
+			p.timestampFormats = splitTimestampFormats(format)
+		}
+		return p.parse, nil
+	case klogFormat:
+		return func(line string) (*logtypes.Log, error) {
+			return parseKlog(timestamps, line)
+		}, nil
+	case criFormat:
+		return parseCRI, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected one of %q", format,
+			[]string{rfc3164Format, rfc5424Format, jsonLinesFormat, klogFormat, criFormat})
+	}
+}
+
+// parseKlog parses a line with klog header.
+func parseKlog(timestamps *syslogparser.TimestampParser, line string) (*logtypes.Log, error) {
+	matches := klogRegexp.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("line %q is not in %s format", line, klogFormat)
+	}
+	timestamp, err := timestamps.Parse([]string{klogTimestampFormat}, matches[2])
+	if err != nil {
+		return nil, err
+	}
+	return &logtypes.Log{
+		Timestamp: timestamp,
+		Message:   matches[6],
+		Fields: map[string]string{
+			"PRIORITY":  klogPriorities[matches[1]],
+			"TID":       matches[3],
+			"CODE_FILE": matches[4],
+			"CODE_LINE": matches[5],
+		},
+	}, nil
+}
+
+// parseCRI parses a line of CRI container log. Partial lines are returned as
+// they are with the LOGTAG field set to "P", and joined by a criJoiner.
+func parseCRI(line string) (*logtypes.Log, error) {
+	matches := criRegexp.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("line %q is not in %s format", line, criFormat)
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, matches[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp %q: %v", matches[1], err)
+	}
+	return &logtypes.Log{
+		Timestamp: timestamp,
+		Message:   matches[4],
+		Fields: map[string]string{
+			"STREAM": matches[2],
+			"LOGTAG": matches[3],
+		},
+	}, nil
+}
+
+// criMaxMessageSize caps the size of the message joined from the partial
+// lines of a CRI container log. The rest of a longer message is dropped.
+const criMaxMessageSize = 64 * 1024
+
+// criJoiner joins the partial lines of a CRI container log, which the runtime
+// writes for the long lines it splits, with the full line ending them. The
+// lines of stdout and stderr are joined separately.
+type criJoiner struct {
+	// partials are the logs of the streams waiting for their full line.
+	partials map[string]*logtypes.Log
+}
+
+func newCRIJoiner() *criJoiner {
+	return &criJoiner{partials: make(map[string]*logtypes.Log)}
+}
+
+// join returns the log of a full line, with the messages of the partial lines
+// of its stream before it, or nil for a partial line. The logs of the lines
+// not in CRI format are returned as they are, also by a nil joiner.
+func (j *criJoiner) join(log *logtypes.Log) *logtypes.Log {
+	if j == nil || log.Fields["LOGTAG"] == "" {
+		return log
+	}
+	stream, tag := log.Fields["STREAM"], log.Fields["LOGTAG"]
+	partial, ok := j.partials[stream]
+	if !ok {
+		if tag != "P" {
+			return log
+		}
+		j.partials[stream] = log
+		return nil
+	}
+	if len(partial.Message) < criMaxMessageSize {
+		partial.Message += log.Message
+		partial.Message = partial.Message[:min(len(partial.Message), criMaxMessageSize)]
+	}
+	if tag == "P" {
+		return nil
+	}
+	delete(j.partials, stream)
+	partial.Fields["LOGTAG"] = tag
+	return partial
+}
+
+// jsonLinesParser parses lines of JSON objects. The keys other than the
+// timestamp and message keys are added as fields.
+type jsonLinesParser struct {
+	timestampKey     string
+	messageKey       string
+	timestampFormats []string
+	timestamps       *syslogparser.TimestampParser
+}
+
+func (p *jsonLinesParser) parse(line string) (*logtypes.Log, error) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON line %q: %v", line, err)
+	}
+	if object == nil {
+		return nil, fmt.Errorf("line %q is not a JSON object", line)
+	}
+	value, ok := object[p.timestampKey]
+	if !ok {
+		return nil, fmt.Errorf("no timestamp key %q found in line %q", p.timestampKey, line)
+	}
+	timestamp, err := p.parseTimestamp(value)
+	if err != nil {
+		return nil, err
+	}
+	value, ok = object[p.messageKey]
+	if !ok {
+		return nil, fmt.Errorf("no message key %q found in line %q", p.messageKey, line)
+	}
+	message, err := jsonString(value)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	for key, value := range object {
+		if key == p.timestampKey || key == p.messageKey || value == nil {
+			continue
+		}
+		if fields[key], err = jsonString(value); err != nil {
+			return nil, err
+		}
+	}
+	return &logtypes.Log{
+		Timestamp: timestamp,
+		Message:   message,
+		Fields:    fields,
+	}, nil
+}
+
+// parseTimestamp parses a string timestamp with the configured formats, or a
+// number timestamp as seconds since the Unix epoch.
+func (p *jsonLinesParser) parseTimestamp(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		return p.timestamps.Parse(p.timestampFormats, v)
+	case json.Number:
+		seconds, err := v.Float64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse timestamp %q: %v", v, err)
+		}
+		integer, fraction := math.Modf(seconds)
+		return time.Unix(int64(integer), int64(fraction*1e9)), nil
+	default:
+		return time.Time{}, fmt.Errorf("unexpected timestamp %v of type %T", value, value)
+	}
+}
+
+// jsonString converts a decoded JSON value to string. Objects and arrays are
+// encoded back to JSON.
+func jsonString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	default:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	}
+}
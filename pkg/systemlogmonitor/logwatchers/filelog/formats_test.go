@@ -0,0 +1,321 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testclock "k8s.io/utils/clock/testing"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/syslogparser"
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+func TestLineParsers(t *testing.T) {
+	year := 2020
+	timestamps, err := syslogparser.NewTimestampParser(nil)
+	require.NoError(t, err)
+	timestamps.SetClock(testclock.NewFakeClock(time.Date(year, time.December, 31, 0, 0, 0, 0, time.Local)))
+	testCases := map[string]struct {
+		format string
+		config map[string]string
+		input  string
+		err    bool
+		log    *logtypes.Log
+	}{
+		"rfc3164 without priority": {
+			format: rfc3164Format,
+			input:  "May  1 12:23:45 hostname kernel: [0.000000] component: log message",
+			log: &logtypes.Log{
+				Timestamp: time.Date(year, time.May, 1, 12, 23, 45, 0, time.Local),
+				Message:   "[0.000000] component: log message",
+				Fields: map[string]string{
+					"_HOSTNAME":         "hostname",
+					"SYSLOG_IDENTIFIER": "kernel",
+				},
+			},
+		},
+		"rfc3164 with priority and pid": {
+			format: rfc3164Format,
+			input:  "<30>Oct 11 22:14:15 hostname kubelet[1234]: log message",
+			log: &logtypes.Log{
+				Timestamp: time.Date(year, time.October, 11, 22, 14, 15, 0, time.Local),
+				Message:   "log message",
+				Fields: map[string]string{
+					"PRIORITY":          "6",
+					"SYSLOG_FACILITY":   "3",
+					"_HOSTNAME":         "hostname",
+					"SYSLOG_IDENTIFIER": "kubelet",
+					"_PID":              "1234",
+				},
+			},
+		},
+		"rfc3164 with high precision timestamp": {
+			format: rfc3164Format,
+			input:  "2017-02-01T17:58:34.123456-08:00 hostname containerd: log message",
+			log: &logtypes.Log{
+				Timestamp: time.Date(2017, 2, 1, 17, 58, 34, 123456000, time.FixedZone("", -8*3600)),
+				Message:   "log message",
+				Fields: map[string]string{
+					"_HOSTNAME":         "hostname",
+					"SYSLOG_IDENTIFIER": "containerd",
+				},
+			},
+		},
+		"rfc3164 without tag": {
+			format: rfc3164Format,
+			input:  "May  1 12:23:45 hostname log message",
+			log: &logtypes.Log{
+				Timestamp: time.Date(year, time.May, 1, 12, 23, 45, 0, time.Local),
+				Message:   "log message",
+				Fields:    map[string]string{"_HOSTNAME": "hostname"},
+			},
+		},
+		"rfc3164 with invalid priority": {
+			format: rfc3164Format,
+			input:  "<999>May  1 12:23:45 hostname kernel: log message",
+			err:    true,
+		},
+		"not rfc3164": {
+			format: rfc3164Format,
+			input:  "log message",
+			err:    true,
+		},
+		"rfc5424": {
+			format: rfc5424Format,
+			input:  `<165>1 2003-10-11T22:14:15.003Z mymachine evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventID="1011"][origin ip="192.0.2.1"] log message`,
+			log: &logtypes.Log{
+				Timestamp: time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+				Message:   "log message",
+				Fields: map[string]string{
+					"PRIORITY":                  "5",
+					"SYSLOG_FACILITY":           "20",
+					"_HOSTNAME":                 "mymachine",
+					"SYSLOG_IDENTIFIER":         "evntslog",
+					"_PID":                      "1234",
+					"MSGID":                     "ID47",
+					"exampleSDID@32473.iut":     "3",
+					"exampleSDID@32473.eventID": "1011",
+					"origin.ip":                 "192.0.2.1",
+				},
+			},
+		},
+		"rfc5424 with nil values and bom": {
+			format: rfc5424Format,
+			input:  "<34>1 2003-10-11T22:14:15Z - - - - - \ufefflog message",
+			log: &logtypes.Log{
+				Timestamp: time.Date(2003, 10, 11, 22, 14, 15, 0, time.UTC),
+				Message:   "log message",
+				Fields: map[string]string{
+					"PRIORITY":        "2",
+					"SYSLOG_FACILITY": "4",
+				},
+			},
+		},
+		"rfc5424 with escaped parameter value and no message": {
+			format: rfc5424Format,
+			input:  `1 2003-10-11T22:14:15Z host app - - [id key="a \"b\" \] c\d"]`,
+			log: &logtypes.Log{
+				Timestamp: time.Date(2003, 10, 11, 22, 14, 15, 0, time.UTC),
+				Fields: map[string]string{
+					"_HOSTNAME":         "host",
+					"SYSLOG_IDENTIFIER": "app",
+					"id.key":            `a "b" ] c\d`,
+				},
+			},
+		},
+		"rfc5424 with nil timestamp": {
+			format: rfc5424Format,
+			input:  "<34>1 - host app - - - log message",
+			err:    true,
+		},
+		"rfc5424 with unterminated structured data": {
+			format: rfc5424Format,
+			input:  `<34>1 2003-10-11T22:14:15Z host app - - [id key="value`,
+			err:    true,
+		},
+		"not rfc5424": {
+			format: rfc5424Format,
+			input:  "May  1 12:23:45 hostname kernel: log message",
+			err:    true,
+		},
+		"jsonlines with default keys": {
+			format: jsonLinesFormat,
+			input:  `{"time":"2017-02-01T17:58:34.5Z","level":"ERROR","msg":"log message","attempt":3,"retry":true,"err":null,"obj":{"a":["<b>"]}}`,
+			log: &logtypes.Log{
+				Timestamp: time.Date(2017, 2, 1, 17, 58, 34, 500000000, time.UTC),
+				Message:   "log message",
+				Fields: map[string]string{
+					"level":   "ERROR",
+					"attempt": "3",
+					"retry":   "true",
+					"obj":     `{"a":["<b>"]}`,
+				},
+			},
+		},
+		"jsonlines with configured keys and numeric timestamp": {
+			format: jsonLinesFormat,
+			config: map[string]string{
+				jsonTimestampKeyKey: "ts",
+				jsonMessageKeyKey:   "message",
+			},
+			input: `{"ts":1485971914.25,"message":"log message"}`,
+			log: &logtypes.Log{
+				Timestamp: time.Unix(1485971914, 250000000),
+				Message:   "log message",
+				Fields:    map[string]string{},
+			},
+		},
+		"jsonlines with configured timestamp format": {
+			format: jsonLinesFormat,
+			config: map[string]string{
+				jsonTimestampFormatKey: "2006-01-02 15:04:05",
+			},
+			input: `{"time":"2017-02-01 17:58:34","msg":"log message"}`,
+			log: &logtypes.Log{
+				Timestamp: time.Date(2017, 2, 1, 17, 58, 34, 0, time.Local),
+				Message:   "log message",
+				Fields:    map[string]string{},
+			},
+		},
+		"jsonlines without message": {
+			format: jsonLinesFormat,
+			input:  `{"time":"2017-02-01T17:58:34.5Z"}`,
+			err:    true,
+		},
+		"jsonlines without timestamp": {
+			format: jsonLinesFormat,
+			input:  `{"msg":"log message"}`,
+			err:    true,
+		},
+		"not jsonlines": {
+			format: jsonLinesFormat,
+			input:  "log message",
+			err:    true,
+		},
+		"klog": {
+			format: klogFormat,
+			input:  "E0201 17:58:34.123456    1234 kubelet.go:2345] log message",
+			log: &logtypes.Log{
+				Timestamp: time.Date(year, time.February, 1, 17, 58, 34, 123456000, time.Local),
+				Message:   "log message",
+				Fields: map[string]string{
+					"PRIORITY":  "3",
+					"TID":       "1234",
+					"CODE_FILE": "kubelet.go",
+					"CODE_LINE": "2345",
+				},
+			},
+		},
+		"not klog": {
+			format: klogFormat,
+			input:  "May  1 12:23:45 hostname kernel: log message",
+			err:    true,
+		},
+		"cri": {
+			format: criFormat,
+			input:  "2016-10-06T00:17:09.669794202Z stderr F log message",
+			log: &logtypes.Log{
+				Timestamp: time.Date(2016, 10, 6, 0, 17, 9, 669794202, time.UTC),
+				Message:   "log message",
+				Fields: map[string]string{
+					"STREAM": "stderr",
+					"LOGTAG": "F",
+				},
+			},
+		},
+		"cri partial empty line": {
+			format: criFormat,
+			input:  "2016-10-06T00:17:09.669794202Z stdout P",
+			log: &logtypes.Log{
+				Timestamp: time.Date(2016, 10, 6, 0, 17, 9, 669794202, time.UTC),
+				Fields: map[string]string{
+					"STREAM": "stdout",
+					"LOGTAG": "P",
+				},
+			},
+		},
+		"not cri": {
+			format: criFormat,
+			input:  "2016-10-06T00:17:09.669794202Z stdin F log message",
+			err:    true,
+		},
+	}
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			parser, err := newLineParser(test.format, test.config, timestamps)
+			require.NoError(t, err)
+			log, err := parser(test.input)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, test.log.Timestamp.Equal(log.Timestamp), "want timestamp %v, got %v", test.log.Timestamp, log.Timestamp)
+			assert.Equal(t, test.log.Message, log.Message)
+			assert.Equal(t, test.log.Fields, log.Fields)
+		})
+	}
+}
+
+func TestNewLineParserUnknownFormat(t *testing.T) {
+	_, err := newLineParser("unknown", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestCRIJoiner(t *testing.T) {
+	parse := func(line string) *logtypes.Log {
+		log, err := parseCRI(line)
+		require.NoError(t, err)
+		return log
+	}
+	j := newCRIJoiner()
+	assert.Nil(t, j.join(parse("2016-10-06T00:17:09.000000001Z stdout P first ")))
+	assert.Nil(t, j.join(parse("2016-10-06T00:17:09.000000002Z stderr P other ")))
+	assert.Nil(t, j.join(parse("2016-10-06T00:17:09.000000003Z stdout P second ")))
+	assert.Equal(t, &logtypes.Log{
+		Timestamp: time.Date(2016, 10, 6, 0, 17, 9, 1, time.UTC),
+		Message:   "first second third",
+		Fields:    map[string]string{"STREAM": "stdout", "LOGTAG": "F"},
+	}, j.join(parse("2016-10-06T00:17:09.000000004Z stdout F third")))
+	assert.Equal(t, &logtypes.Log{
+		Timestamp: time.Date(2016, 10, 6, 0, 17, 9, 2, time.UTC),
+		Message:   "other stream",
+		Fields:    map[string]string{"STREAM": "stderr", "LOGTAG": "F"},
+	}, j.join(parse("2016-10-06T00:17:09.000000005Z stderr F stream")))
+
+	// Full lines without partial lines and the lines of other formats are
+	// returned as they are.
+	full := parse("2016-10-06T00:17:09.000000006Z stdout F full")
+	assert.Same(t, full, j.join(full))
+	other := &logtypes.Log{Message: "not cri"}
+	assert.Same(t, other, j.join(other))
+	assert.Same(t, other, (*criJoiner)(nil).join(other))
+
+	// The joined message is capped.
+	chunk := strings.Repeat("x", criMaxMessageSize/2+1)
+	for range 3 {
+		assert.Nil(t, j.join(parse("2016-10-06T00:17:09Z stdout P "+chunk)))
+	}
+	log := j.join(parse("2016-10-06T00:17:09Z stdout F end"))
+	require.NotNil(t, log)
+	assert.Equal(t, strings.Repeat("x", criMaxMessageSize), log.Message)
+}
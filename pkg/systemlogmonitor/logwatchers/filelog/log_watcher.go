@@ -19,26 +19,57 @@ package filelog
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/klog/v2"
 
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
 	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
 	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
 	"k8s.io/node-problem-detector/pkg/util"
 	"k8s.io/node-problem-detector/pkg/util/tomb"
 )
 
+const (
+	// watchPollInterval is the interval filelog log watcher will
+	// poll for pod change after reading to the end.
+	watchPollInterval = 500 * time.Millisecond
+	// reopenInterval is the interval at which filelog log watcher tries to
+	// reopen a log file it failed to read, e.g. because it disappeared.
+	reopenInterval = 1 * time.Second
+	// rescanInterval is the interval at which filelog log watcher looks for
+	// files added to or removed from a log path glob.
+	rescanInterval = 5 * time.Second
+)
+
 type filelogWatcher struct {
 	cfg        types.WatcherConfig
-	reader     *bufio.Reader
-	closer     io.Closer
 	translator *translator
 	logCh      chan *logtypes.Log
 	startTime  time.Time
 	tomb       *tomb.Tomb
+	// openLog opens a log file for reading. Overridable in tests; defaults
+	// to getLogReader.
+	openLog func(path string) (io.ReadCloser, error)
+	// rescanInterval is the interval at which a log path glob is expanded.
+	rescanInterval time.Duration
+	// followers are the log files being read, by path. They are only accessed
+	// by the watch loop.
+	followers map[string]*follower
+	wg        sync.WaitGroup
+}
+
+// follower reads one log file.
+type follower struct {
+	path string
+	stop chan struct{}
+	// joiner joins the partial lines of the file and its rotated siblings.
+	joiner *criJoiner
 }
 
 // NewSyslogWatcherOrDie creates a new log watcher. The function panics
@@ -54,10 +85,13 @@ func NewSyslogWatcherOrDie(cfg types.WatcherConfig) types.LogWatcher {
 	}
 
 	return &filelogWatcher{
-		cfg:        cfg,
-		translator: newTranslatorOrDie(cfg.PluginConfig),
-		startTime:  startTime,
-		tomb:       tomb.NewTomb(),
+		cfg:            cfg,
+		translator:     newTranslatorOrDie(cfg.PluginConfig),
+		startTime:      startTime,
+		tomb:           tomb.NewTomb(),
+		openLog:        getLogReader,
+		rescanInterval: rescanInterval,
+		followers:      make(map[string]*follower),
 		// A capacity 1000 buffer should be enough
 		logCh: make(chan *logtypes.Log, 1000),
 	}
@@ -66,14 +100,21 @@ func NewSyslogWatcherOrDie(cfg types.WatcherConfig) types.LogWatcher {
 // Make sure NewSyslogWatcher is types.WatcherCreateFunc.
 var _ types.WatcherCreateFunc = NewSyslogWatcherOrDie
 
-// Watch starts the filelog watcher.
+// Watch starts the filelog watcher. The log path can be a glob, in which case
+// files matching it are followed as they are added and removed.
 func (s *filelogWatcher) Watch() (<-chan *logtypes.Log, error) {
-	r, err := getLogReader(s.cfg.LogPath)
-	if err != nil {
-		return nil, err
+	if isGlob(s.cfg.LogPath) {
+		if _, err := filepath.Match(s.cfg.LogPath, ""); err != nil {
+			return nil, fmt.Errorf("invalid log path pattern %q: %v", s.cfg.LogPath, err)
+		}
+		s.rescan(true)
+	} else {
+		r, err := s.openLog(s.cfg.LogPath)
+		if err != nil {
+			return nil, err
+		}
+		s.follow(s.cfg.LogPath, r, true)
 	}
-	s.reader = bufio.NewReader(r)
-	s.closer = r
 	klog.Info("Start watching filelog")
 	go s.watchLoop()
 	return s.logCh, nil
@@ -84,62 +125,202 @@ func (s *filelogWatcher) Stop() {
 	s.tomb.Stop()
 }
 
-// watchPollInterval is the interval filelog log watcher will
-// poll for pod change after reading to the end.
-const watchPollInterval = 500 * time.Millisecond
-
-// watchLoop is the main watch loop of filelog watcher.
+// watchLoop is the main watch loop of filelog watcher. It expands the log
+// path glob periodically, and stops the followers when the watcher stops.
 func (s *filelogWatcher) watchLoop() {
 	defer func() {
-		if err := s.closer.Close(); err != nil {
-			klog.Errorf("Failed to close log file: %v", err)
+		for _, f := range s.followers {
+			close(f.stop)
 		}
+		s.wg.Wait()
 		close(s.logCh)
 		s.tomb.Done()
 	}()
-	var buffer bytes.Buffer
+	var rescanCh <-chan time.Time
+	if isGlob(s.cfg.LogPath) {
+		ticker := time.NewTicker(s.rescanInterval)
+		defer ticker.Stop()
+		rescanCh = ticker.C
+	}
 	for {
 		select {
 		case <-s.tomb.Stopping():
 			klog.Infof("Stop watching filelog")
 			return
+		case <-rescanCh:
+			s.rescan(false)
+		}
+	}
+}
+
+// rescan follows the files newly matching the log path glob, and stops
+// following the files which no longer match it.
+func (s *filelogWatcher) rescan(initial bool) {
+	paths, err := filepath.Glob(s.cfg.LogPath)
+	if err != nil {
+		klog.Errorf("Failed to expand log path %q: %v", s.cfg.LogPath, err)
+		return
+	}
+	matched := make(map[string]bool)
+	for _, path := range paths {
+		matched[path] = true
+		if _, ok := s.followers[path]; ok {
+			continue
+		}
+		r, err := s.openLog(path)
+		if err != nil {
+			klog.Warningf("Failed to open log file %q: %v", path, err)
+			continue
+		}
+		klog.Infof("Start following log file %q", path)
+		s.follow(path, r, initial)
+	}
+	for path, f := range s.followers {
+		if !matched[path] {
+			klog.Infof("Stop following removed log file %q", path)
+			close(f.stop)
+			delete(s.followers, path)
+		}
+	}
+}
+
+// follow starts reading the log file. When lookback is set, the rotated
+// siblings of the file are read first.
+func (s *filelogWatcher) follow(path string, r io.ReadCloser, lookback bool) {
+	f := &follower{path: path, stop: make(chan struct{}), joiner: s.translator.newJoiner()}
+	s.followers[path] = f
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if lookback && !s.readRotatedLogs(f) {
+			if err := r.Close(); err != nil {
+				klog.Errorf("Failed to close log file %q: %v", path, err)
+			}
+			return
+		}
+		s.followLoop(f, r)
+	}()
+}
+
+// followLoop reads the log file line by line until the follower stops. When
+// reading fails, e.g. because the file disappeared during rotation for longer
+// than the reader waits for it, the file is reopened once it comes back.
+func (s *filelogWatcher) followLoop(f *follower, r io.ReadCloser) {
+	defer func() {
+		// r is nil when the follower stopped while reopening the file.
+		if r != nil {
+			if err := r.Close(); err != nil {
+				klog.Errorf("Failed to close log file %q: %v", f.path, err)
+			}
+		}
+	}()
+	reader := bufio.NewReader(r)
+	var buffer bytes.Buffer
+	for {
+		select {
+		case <-f.stop:
+			return
 		default:
 		}
 
-		line, err := s.reader.ReadString('\n')
+		line, err := reader.ReadString('\n')
 		if err != nil && err != io.EOF {
-			klog.Errorf("Exiting filelog watch with error: %v", err)
-			return
+			klog.Errorf("Failed to read log file %q, reopening it: %v", f.path, err)
+			if err := r.Close(); err != nil {
+				klog.Errorf("Failed to close log file %q: %v", f.path, err)
+			}
+			if err := monitormetrics.GlobalMonitorMetricsManager.IncrementWatcherRestarts(s.cfg.Source, 1); err != nil {
+				klog.Errorf("Failed to update watcher restart metrics for %q: %v", s.cfg.Source, err)
+			}
+			if r = s.reopen(f); r == nil {
+				return
+			}
+			reader = bufio.NewReader(r)
+			buffer.Reset()
+			continue
 		}
 		buffer.WriteString(line)
 		if err == io.EOF {
-			time.Sleep(watchPollInterval)
+			select {
+			case <-f.stop:
+				return
+			case <-time.After(watchPollInterval):
+			}
 			continue
 		}
 		line = buffer.String()
 		buffer.Reset()
-		if s.filterSkipList(line) {
-			continue
+		if !s.processLine(line, f) {
+			return
 		}
-		log, err := s.translator.translate(strings.TrimSuffix(line, "\n"))
-		if err != nil {
-			klog.Warningf("Unable to parse line: %q, %v", line, err)
-			continue
+	}
+}
+
+// reopen opens the log file again, retrying until it succeeds. It returns nil
+// when the follower stops first.
+func (s *filelogWatcher) reopen(f *follower) io.ReadCloser {
+	for {
+		select {
+		case <-f.stop:
+			return nil
+		case <-time.After(reopenInterval):
 		}
-		// Discard messages before start time.
-		if log.Timestamp.Before(s.startTime) {
-			klog.V(5).Infof("Throwing away msg %q before start time: %v < %v", log.Message, log.Timestamp, s.startTime)
-			continue
+		r, err := s.openLog(f.path)
+		if err == nil {
+			klog.Infof("Reopened log file %q", f.path)
+			return r
 		}
-		s.logCh <- log
+		klog.V(4).Infof("Failed to reopen log file %q: %v", f.path, err)
+	}
+}
+
+// processLine translates the log line of the follower and sends it to the log
+// channel. It returns false when the follower stopped while sending.
+func (s *filelogWatcher) processLine(line string, f *follower) bool {
+	monitormetrics.RecordLogLines(s.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesRead)
+	if s.filterSkipList(line) {
+		return true
+	}
+	log, err := s.translator.translate(strings.TrimSuffix(line, "\n"))
+	if err != nil {
+		klog.Warningf("Unable to parse line: %q, %v", line, err)
+		monitormetrics.RecordLogLines(s.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesFailed)
+		return true
+	}
+	monitormetrics.RecordLogLines(s.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesParsed)
+	if log = f.joiner.join(log); log == nil {
+		return true
+	}
+	// Discard messages before start time.
+	if log.Timestamp.Before(s.startTime) {
+		klog.V(5).Infof("Throwing away msg %q before start time: %v < %v", log.Message, log.Timestamp, s.startTime)
+		return true
+	}
+	// The consumer stops draining logCh before calling Stop(), so a plain
+	// send on a full channel could block forever and deadlock Stop().
+	select {
+	case s.logCh <- log:
+		return true
+	case <-f.stop:
+		return false
 	}
 }
 
 func (s *filelogWatcher) filterSkipList(line string) bool {
-	for _, skipItem := range s.cfg.SkipList {
+	return inSkipList(s.cfg.SkipList, line)
+}
+
+// inSkipList returns whether the line contains an item of the skip list.
+func inSkipList(skipList []string, line string) bool {
+	for _, skipItem := range skipList {
 		if strings.Contains(line, skipItem) {
 			return true
 		}
 	}
 	return false
 }
+
+// isGlob returns whether the log path is a glob pattern.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
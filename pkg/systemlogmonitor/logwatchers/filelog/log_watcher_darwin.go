@@ -23,7 +23,7 @@ import (
 )
 
 // getLogReader returns log reader for filelog log. Note that getLogReader doesn't look back
-// to the rolled out logs.
+// to the rolled out logs, the watcher reads them itself.
 func getLogReader(path string) (io.ReadCloser, error) {
 	return tail.OpenFile(path)
 }
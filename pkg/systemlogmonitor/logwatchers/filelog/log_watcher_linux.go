@@ -25,17 +25,16 @@ import (
 )
 
 // getLogReader returns log reader for filelog log. Note that getLogReader doesn't look back
-// to the rolled out logs.
+// to the rolled out logs, the watcher reads them itself.
 func getLogReader(path string) (io.ReadCloser, error) {
 	if path == "" {
 		return nil, fmt.Errorf("unexpected empty log path")
 	}
 	// To handle log rotation, tail will not report error immediately if
 	// the file doesn't exist. So we check file existence first.
-	// This could go wrong during mid-rotation. It should recover after
-	// several restart when the log file is created again. The chance
-	// is slim but we should still fix this in the future.
-	// TODO(random-liu): Handle log missing during rotation.
+	// When the file stays missing mid-rotation for longer than tail
+	// retries, reading fails and the watcher reopens the file once it
+	// is created again.
 	_, err := os.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to stat the file %q: %v", path, err)
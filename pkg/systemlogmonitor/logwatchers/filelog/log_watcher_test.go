@@ -17,16 +17,25 @@ limitations under the License.
 package filelog
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	testclock "k8s.io/utils/clock/testing"
 
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
 	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
 	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
 	"k8s.io/node-problem-detector/pkg/util"
+	"k8s.io/node-problem-detector/pkg/util/metrics"
 )
 
 // getTestPluginConfig returns a plugin config for test. Use configuration for
@@ -157,6 +166,7 @@ Jan  2 03:04:05 kernel: [2.000000] 3
 			LogPath:      f.Name(),
 			Lookback:     test.lookback,
 		})
+		w.(*filelogWatcher).translator.timestamps.SetClock(fakeClock)
 		// Set the startTime.
 		w.(*filelogWatcher).startTime, _ = util.GetStartTime(fakeClock.Now(), test.uptime, test.lookback, test.delay)
 		logCh, err := w.Watch()
@@ -212,3 +222,149 @@ func TestFilterSkipList(t *testing.T) {
 		}
 	}
 }
+
+// receiveMessages receives n logs from the channel and returns their messages.
+func receiveMessages(t *testing.T, logCh <-chan *logtypes.Log, n int) []string {
+	t.Helper()
+	var messages []string
+	for range n {
+		select {
+		case log := <-logCh:
+			messages = append(messages, log.Message)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout waiting for log, got %v", messages)
+		}
+	}
+	return messages
+}
+
+// newTestWatcher creates a watcher of the log path which reads every line.
+func newTestWatcher(logPath string) *filelogWatcher {
+	w := NewSyslogWatcherOrDie(types.WatcherConfig{
+		Plugin:       "filelog",
+		PluginConfig: getTestPluginConfig(),
+		LogPath:      logPath,
+	}).(*filelogWatcher)
+	w.startTime = time.Time{}
+	w.rescanInterval = 10 * time.Millisecond
+	return w
+}
+
+func TestWatchGlob(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.log"), []byte("Jan  2 03:04:05 kernel: [0.000000] a\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("Jan  2 03:04:05 kernel: [0.000000] ignored\n"), 0644))
+
+	w := newTestWatcher(filepath.Join(dir, "*.log"))
+	logCh, err := w.Watch()
+	assert.NoError(t, err)
+	defer w.Stop()
+	assert.Equal(t, []string{"a"}, receiveMessages(t, logCh, 1))
+
+	// A file added later is followed from its beginning.
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.log"), []byte("Jan  2 03:04:06 kernel: [1.000000] b\n"), 0644))
+	assert.Equal(t, []string{"b"}, receiveMessages(t, logCh, 1))
+}
+
+func TestWatchCountsFailedLines(t *testing.T) {
+	original := monitormetrics.GlobalMonitorMetricsManager
+	t.Cleanup(func() { monitormetrics.GlobalMonitorMetricsManager = original })
+	mmm, stub := monitormetrics.NewMonitorMetricsManagerStub()
+	monitormetrics.GlobalMonitorMetricsManager = mmm
+
+	logPath := filepath.Join(t.TempDir(), "kern.log")
+	assert.NoError(t, os.WriteFile(logPath, []byte(`Jan  2 03:04:05 kernel: [0.000000] 1
+unparsable line
+Jan  2 03:04:06 kernel: [1.000000] 2
+`), 0644))
+	w := newTestWatcher(logPath)
+	w.cfg.Source = "kernel-monitor"
+	logCh, err := w.Watch()
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	assert.Equal(t, []string{"1", "2"}, receiveMessages(t, logCh, 2))
+	labels := map[string]string{"source": "kernel-monitor"}
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: string(metrics.LogLinesFailedID), Labels: labels, Value: 1},
+	}, stub.LogLinesFailed.ListMetrics())
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: string(metrics.LogLinesParsedID), Labels: labels, Value: 2},
+	}, stub.LogLinesParsed.ListMetrics())
+}
+
+func TestWatchInvalidGlob(t *testing.T) {
+	w := newTestWatcher("/var/log/[.log")
+	_, err := w.Watch()
+	assert.Error(t, err)
+}
+
+func TestWatchReadsRotatedLogs(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "kern.log")
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write([]byte("Jan  2 03:04:01 kernel: [0.000000] oldest\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, os.WriteFile(logPath+".2.gz", compressed.Bytes(), 0644))
+	assert.NoError(t, os.WriteFile(logPath+".1", []byte("Jan  2 03:04:02 kernel: [1.000000] older\n"), 0644))
+	assert.NoError(t, os.WriteFile(logPath, []byte("Jan  2 03:04:03 kernel: [2.000000] current\n"), 0644))
+	// Files which are not rotated siblings are not read.
+	assert.NoError(t, os.WriteFile(logPath+".bak", []byte("Jan  2 03:04:00 kernel: [0.000000] backup\n"), 0644))
+
+	w := newTestWatcher(logPath)
+	logCh, err := w.Watch()
+	assert.NoError(t, err)
+	defer w.Stop()
+	assert.Equal(t, []string{"oldest", "older", "current"}, receiveMessages(t, logCh, 3))
+}
+
+func TestWatchSkipsRotatedLogsBeforeStartTime(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "kern.log")
+	assert.NoError(t, os.WriteFile(logPath+".1", []byte("Jan  2 03:04:02 kernel: [1.000000] older\n"), 0644))
+	old := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(logPath+".1", old, old))
+
+	w := newTestWatcher(logPath)
+	w.startTime = time.Now().Add(-time.Minute)
+	assert.Empty(t, w.rotatedLogs(logPath))
+}
+
+// failingReader returns its content, and then fails.
+type failingReader struct {
+	content *strings.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.content.Len() == 0 {
+		return 0, errors.New("log file is gone")
+	}
+	return r.content.Read(p)
+}
+
+func (r *failingReader) Close() error {
+	return nil
+}
+
+func TestWatchReopensLogFile(t *testing.T) {
+	w := newTestWatcher("/var/log/kern.log")
+	var opens atomic.Int32
+	w.openLog = func(path string) (io.ReadCloser, error) {
+		switch opens.Add(1) {
+		case 1:
+			return &failingReader{strings.NewReader("Jan  2 03:04:05 kernel: [0.000000] before\n")}, nil
+		case 2:
+			return nil, errors.New("log file does not exist")
+		default:
+			return io.NopCloser(strings.NewReader("Jan  2 03:04:06 kernel: [1.000000] after\n")), nil
+		}
+	}
+	logCh, err := w.Watch()
+	assert.NoError(t, err)
+	defer w.Stop()
+	assert.Equal(t, []string{"before", "after"}, receiveMessages(t, logCh, 2))
+	assert.Equal(t, int32(3), opens.Load())
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filelog
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+// ReadLogs reads all the log lines of the reader and translates them like a
+// filelog watcher with the configuration, whose log path and lookback are
+// ignored. The lines in the skip list and the lines failing to translate are
+// skipped. The year of the timestamps without year is inferred relative to
+// the clock.
+func ReadLogs(cfg types.WatcherConfig, r io.Reader, clock clock.Clock) ([]*logtypes.Log, error) {
+	t, err := newTranslator(cfg.PluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	t.timestamps.SetClock(clock)
+
+	joiner := t.newJoiner()
+	var logs []*logtypes.Log
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" && !inSkipList(cfg.SkipList, line) {
+			log, translateErr := t.translate(strings.TrimSuffix(line, "\n"))
+			if translateErr != nil {
+				klog.Warningf("Unable to parse line: %q, %v", line, translateErr)
+			} else if log = joiner.join(log); log != nil {
+				logs = append(logs, log)
+			}
+		}
+		if err == io.EOF {
+			return logs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testclock "k8s.io/utils/clock/testing"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/syslogparser"
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
+)
+
+func TestReadLogs(t *testing.T) {
+	cfg := types.WatcherConfig{
+		Plugin:       "filelog",
+		PluginConfig: map[string]string{formatKey: rfc3164Format, syslogparser.TimezoneKey: "UTC"},
+		SkipList:     []string{"audit"},
+	}
+	fakeClock := testclock.NewFakeClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))
+	input := strings.Join([]string{
+		"Dec 31 23:59:59 node kernel: first",
+		"Dec 31 23:59:59 node audit: skipped",
+		"not a syslog line",
+		"Jan  1 00:00:01 node kernel: last",
+	}, "\n")
+
+	logs, err := ReadLogs(cfg, strings.NewReader(input), fakeClock)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, []string{"first", "last"}, []string{logs[0].Message, logs[1].Message})
+	assert.Equal(t, time.Date(2020, time.December, 31, 23, 59, 59, 0, time.UTC), logs[0].Timestamp)
+	assert.Equal(t, time.Date(2021, time.January, 1, 0, 0, 1, 0, time.UTC), logs[1].Timestamp)
+}
+
+func TestReadLogsRejectsInvalidConfig(t *testing.T) {
+	_, err := ReadLogs(types.WatcherConfig{PluginConfig: map[string]string{formatKey: "unknown"}},
+		strings.NewReader(""), testclock.NewFakeClock(time.Now()))
+	assert.Error(t, err)
+}
+
+func TestReadLogsJoinsCRIPartialLines(t *testing.T) {
+	cfg := types.WatcherConfig{
+		Plugin:       "filelog",
+		PluginConfig: map[string]string{formatKey: criFormat},
+	}
+	input := strings.Join([]string{
+		"2016-10-06T00:17:09.669794202Z stdout P first ",
+		"2016-10-06T00:17:09.669794203Z stdout F line",
+		"2016-10-06T00:17:10.669794202Z stdout F second line",
+	}, "\n")
+
+	logs, err := ReadLogs(cfg, strings.NewReader(input), testclock.NewFakeClock(time.Now()))
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, []string{"first line", "second line"}, []string{logs[0].Message, logs[1].Message})
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filelog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// rotatedLog is a rotated sibling of a log file, e.g. syslog.1 or syslog.2.gz.
+type rotatedLog struct {
+	path string
+	// index is the rotation index. A higher index is an older file.
+	index int
+	// compressed is whether the file is gzip compressed.
+	compressed bool
+}
+
+// rotatedLogs returns the rotated siblings of the log file which were
+// modified after the start time, oldest first.
+func (s *filelogWatcher) rotatedLogs(path string) []rotatedLog {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		klog.Warningf("Failed to list rotated logs of %q: %v", path, err)
+		return nil
+	}
+	var logs []rotatedLog
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), base+".")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		suffix, compressed := strings.CutSuffix(suffix, ".gz")
+		index, err := strconv.Atoi(suffix)
+		if err != nil || index <= 0 {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		// A file last modified before the start time only has older lines.
+		if info.ModTime().Before(s.startTime) {
+			continue
+		}
+		logs = append(logs, rotatedLog{
+			path:       filepath.Join(dir, entry.Name()),
+			index:      index,
+			compressed: compressed,
+		})
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].index > logs[j].index })
+	return logs
+}
+
+// readRotatedLogs reads the rotated siblings of the followed log file, so
+// that the lookback covers the lines rolled over before the watcher started.
+// It returns false when the follower stopped while reading.
+func (s *filelogWatcher) readRotatedLogs(f *follower) bool {
+	for _, log := range s.rotatedLogs(f.path) {
+		klog.Infof("Reading rotated log file %q", log.path)
+		if !s.readRotatedLog(log, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// readRotatedLog reads a rotated log file of the follower to the end. It
+// returns false when the follower stopped while reading.
+func (s *filelogWatcher) readRotatedLog(log rotatedLog, f *follower) bool {
+	file, err := os.Open(log.path)
+	if err != nil {
+		klog.Warningf("Failed to open rotated log file %q: %v", log.path, err)
+		return true
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if log.compressed {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			klog.Warningf("Failed to decompress rotated log file %q: %v", log.path, err)
+			return true
+		}
+		defer gz.Close()
+		r = gz
+	}
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" && !s.processLine(line, f) {
+			return false
+		}
+		if err != nil {
+			if err != io.EOF {
+				klog.Warningf("Failed to read rotated log file %q: %v", log.path, err)
+			}
+			return true
+		}
+	}
+}
@@ -0,0 +1,30 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filelog
+
+import (
+	"strings"
+)
+
+// timestampFormatSeparator separates the timestamp formats, which are tried
+// in order.
+const timestampFormatSeparator = "|"
+
+// splitTimestampFormats splits the timestamp formats of the plugin configuration.
+func splitTimestampFormats(formats string) []string {
+	return strings.Split(formats, timestampFormatSeparator)
+}
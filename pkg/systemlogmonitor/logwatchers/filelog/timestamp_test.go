@@ -0,0 +1,46 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testclock "k8s.io/utils/clock/testing"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/syslogparser"
+)
+
+func TestTimestampParserFormats(t *testing.T) {
+	p, err := syslogparser.NewTimestampParser(map[string]string{syslogparser.TimezoneKey: "UTC"})
+	require.NoError(t, err)
+	p.SetClock(testclock.NewFakeClock(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)))
+	layouts := splitTimestampFormats("2006-01-02 15:04:05|Jan _2 15:04:05")
+
+	got, err := p.Parse(layouts, "2021-05-01 12:23:45")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.May, 1, 12, 23, 45, 0, time.UTC), got)
+
+	got, err = p.Parse(layouts, "May  1 12:23:45")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.May, 1, 12, 23, 45, 0, time.UTC), got)
+
+	_, err = p.Parse(layouts, "2021/05/01 12:23:45")
+	assert.Error(t, err)
+}
@@ -18,22 +18,29 @@ package filelog
 import (
 	"fmt"
 	"regexp"
-	"time"
 
 	"k8s.io/klog/v2"
 
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/syslogparser"
 	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
 )
 
-// translator translates log line into internal log type based on user defined
-// regular expression.
+// translator translates log line into internal log type based on a built-in
+// format or user defined regular expression.
 type translator struct {
-	timestampRegexp *regexp.Regexp
-	messageRegexp   *regexp.Regexp
-	timestampFormat string
+	// parser parses the log line with the built-in format. It is nil when no
+	// format is configured.
+	parser           lineParser
+	format           string
+	timestamps       *syslogparser.TimestampParser
+	timestampRegexp  *regexp.Regexp
+	messageRegexp    *regexp.Regexp
+	timestampFormats []string
 }
 
 const (
+	// formatKey is the key of the built-in log line format in the plugin configuration.
+	formatKey = "format"
 	// NOTE that we support submatch for both timestamp and message regular expressions. When
 	// there are multiple matches returned by submatch, only **the last** is used.
 	// timestampKey is the key of timestamp regular expression in the plugin configuration.
@@ -41,33 +48,92 @@ const (
 	// messageKey is the key of message regular expression in the plugin configuration.
 	messageKey = "message"
 	// timestampFormatKey is the key of timestamp format string in the plugin configuration.
+	// Multiple formats separated by "|" are tried in order.
 	timestampFormatKey = "timestampFormat"
 )
 
+// newTranslatorOrDie creates a new translator. The function panics on invalid
+// plugin configuration.
 func newTranslatorOrDie(pluginConfig map[string]string) *translator {
+	t, err := newTranslator(pluginConfig)
+	if err != nil {
+		klog.Fatalf("Failed to validate plugin configuration %+v: %v", pluginConfig, err)
+	}
+	return t
+}
+
+// newTranslator creates a new translator. When both a format and the regular
+// expressions are configured, the regular expressions are used for the lines
+// not in the format.
+func newTranslator(pluginConfig map[string]string) (*translator, error) {
 	if err := validatePluginConfig(pluginConfig); err != nil {
-		klog.Errorf("Failed to validate plugin configuration %+v: %v", pluginConfig, err)
+		return nil, err
+	}
+	timestamps, err := syslogparser.NewTimestampParser(pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	t := &translator{timestamps: timestamps}
+	if format := pluginConfig[formatKey]; format != "" {
+		parser, err := newLineParser(format, pluginConfig, timestamps)
+		if err != nil {
+			return nil, err
+		}
+		t.parser = parser
+		t.format = format
+	}
+	if pluginConfig[timestampKey] == "" {
+		return t, nil
 	}
-	return &translator{
-		timestampRegexp: regexp.MustCompile(pluginConfig[timestampKey]),
-		messageRegexp:   regexp.MustCompile(pluginConfig[messageKey]),
-		timestampFormat: pluginConfig[timestampFormatKey],
+	if t.timestampRegexp, err = regexp.Compile(pluginConfig[timestampKey]); err != nil {
+		return nil, fmt.Errorf("invalid timestamp regular expression: %v", err)
 	}
+	if t.messageRegexp, err = regexp.Compile(pluginConfig[messageKey]); err != nil {
+		return nil, fmt.Errorf("invalid message regular expression: %v", err)
+	}
+	t.timestampFormats = splitTimestampFormats(pluginConfig[timestampFormatKey])
+	return t, nil
+}
+
+// ValidatePluginConfig validates the filelog plugin configuration, including
+// the regular expressions, format and timestamp configuration.
+func ValidatePluginConfig(pluginConfig map[string]string) error {
+	_, err := newTranslator(pluginConfig)
+	return err
 }
 
 // translate translates the log line into internal type.
 func (t *translator) translate(line string) (*logtypes.Log, error) {
+	if t.parser == nil {
+		return t.translateWithRegexp(line)
+	}
+	log, err := t.parser(line)
+	if err != nil && t.timestampRegexp != nil {
+		return t.translateWithRegexp(line)
+	}
+	return log, err
+}
+
+// newJoiner returns the joiner of the partial lines of a log file, or nil if
+// the format has no partial lines.
+func (t *translator) newJoiner() *criJoiner {
+	if t.format != criFormat {
+		return nil
+	}
+	return newCRIJoiner()
+}
+
+// translateWithRegexp translates the log line with the user defined regular expressions.
+func (t *translator) translateWithRegexp(line string) (*logtypes.Log, error) {
 	// Parse timestamp.
 	matches := t.timestampRegexp.FindStringSubmatch(line)
 	if len(matches) == 0 {
 		return nil, fmt.Errorf("no timestamp found in line %q with regular expression %v", line, t.timestampRegexp)
 	}
-	timestamp, err := time.ParseInLocation(t.timestampFormat, matches[len(matches)-1], time.Local)
+	timestamp, err := t.timestamps.Parse(t.timestampFormats, matches[len(matches)-1])
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse timestamp %q: %v", matches[len(matches)-1], err)
+		return nil, err
 	}
-	// Formalize the timestamp.
-	timestamp = formalizeTimestamp(timestamp)
 	// Parse message.
 	matches = t.messageRegexp.FindStringSubmatch(line)
 	if len(matches) == 0 {
@@ -80,8 +146,13 @@ func (t *translator) translate(line string) (*logtypes.Log, error) {
 	}, nil
 }
 
-// validatePluginConfig validates whether the plugin configuration.
+// validatePluginConfig validates whether the plugin configuration. The regular
+// expressions are optional when a format is configured, but must then be
+// configured all together.
 func validatePluginConfig(cfg map[string]string) error {
+	if cfg[formatKey] != "" && cfg[timestampKey] == "" && cfg[messageKey] == "" && cfg[timestampFormatKey] == "" {
+		return nil
+	}
 	if cfg[timestampKey] == "" {
 		return fmt.Errorf("unexpected empty timestamp regular expression")
 	}
@@ -93,12 +164,3 @@ func validatePluginConfig(cfg map[string]string) error {
 	}
 	return nil
 }
-
-// formalizeTimestamp formalizes the timestamp. We need this because some log doesn't contain full
-// timestamp, e.g. filelog.
-func formalizeTimestamp(t time.Time) time.Time {
-	if t.Year() == 0 {
-		t = t.AddDate(time.Now().Year(), 0, 0)
-	}
-	return t
-}
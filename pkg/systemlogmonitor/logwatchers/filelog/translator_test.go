@@ -22,12 +22,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	testclock "k8s.io/utils/clock/testing"
 
 	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
 )
 
 func TestTranslate(t *testing.T) {
-	year := time.Now().Year()
+	year := 2020
+	fakeClock := testclock.NewFakeClock(time.Date(year, time.December, 31, 0, 0, 0, 0, time.Local))
 	testCases := []struct {
 		config map[string]string
 		input  string
@@ -74,11 +76,45 @@ func TestTranslate(t *testing.T) {
 				Message:   `test log line1\n test log line2`,
 			},
 		},
+		{
+			// built-in format
+			config: map[string]string{
+				"format": "klog",
+			},
+			input: "I0201 17:58:34.123456    1234 kubelet.go:2345] log message",
+			log: &logtypes.Log{
+				Timestamp: time.Date(year, time.February, 1, 17, 58, 34, 123456000, time.Local),
+				Message:   "log message",
+			},
+		},
+		{
+			// line not in the built-in format
+			config: map[string]string{
+				"format": "klog",
+			},
+			input: "May  1 12:23:45 hostname kernel: [0.000000] component: log message",
+			err:   true,
+		},
+		{
+			// line not in the built-in format falls back to regular expressions
+			config: map[string]string{
+				"format":          "klog",
+				"timestamp":       "^.{15}",
+				"message":         "kernel: \\[.*\\] (.*)",
+				"timestampFormat": "Jan _2 15:04:05",
+			},
+			input: "May  1 12:23:45 hostname kernel: [0.000000] component: log message",
+			log: &logtypes.Log{
+				Timestamp: time.Date(year, time.May, 1, 12, 23, 45, 0, time.Local),
+				Message:   "component: log message",
+			},
+		},
 	}
 
 	for c, test := range testCases {
 		t.Logf("TestCase #%d: %#v", c+1, test)
 		trans := newTranslatorOrDie(test.config)
+		trans.timestamps.SetClock(fakeClock)
 		log, err := trans.translate(test.input)
 		if !test.err {
 			require.NoError(t, err)
@@ -90,3 +126,35 @@ func TestTranslate(t *testing.T) {
 		}
 	}
 }
+
+func TestNewTranslatorInvalidConfig(t *testing.T) {
+	testCases := map[string]map[string]string{
+		"empty config": {},
+		"missing message regular expression": {
+			"timestamp":       "^.{15}",
+			"timestampFormat": "Jan _2 15:04:05",
+		},
+		"invalid regular expression": {
+			"timestamp":       "^.{15}",
+			"message":         "(",
+			"timestampFormat": "Jan _2 15:04:05",
+		},
+		"invalid timezone": {
+			"format":   "klog",
+			"timezone": "Nowhere/Invalid",
+		},
+		"unknown format": {
+			"format": "unknown",
+		},
+		"format with incomplete regular expressions": {
+			"format":    "rfc3164",
+			"timestamp": "^.{15}",
+		},
+	}
+	for name, config := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := newTranslator(config)
+			assert.Error(t, err)
+		})
+	}
+}
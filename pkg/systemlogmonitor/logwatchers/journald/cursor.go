@@ -0,0 +1,69 @@
+//go:build journald
+// +build journald
+
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package journald
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configCursorFileKey is the key of the file the cursor of the last read
+// journal entry is persisted to, so that the watcher resumes from it after
+// a restart instead of from the lookback window.
+const configCursorFileKey = "cursorFile"
+
+// readCursor reads the persisted journal cursor. It returns an empty cursor
+// if the cursor file does not exist.
+func readCursor(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read cursor file %q: %v", path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeCursor atomically persists the journal cursor.
+func writeCursor(path, cursor string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cursor directory %q: %v", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary cursor file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(cursor); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary cursor file %q: %v", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary cursor file %q: %v", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename cursor file %q: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+//go:build journald
+// +build journald
+
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package journald
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "cursor")
+
+	cursor, err := readCursor(path)
+	assert.NoError(t, err)
+	assert.Empty(t, cursor, "missing cursor file should return an empty cursor")
+
+	assert.NoError(t, writeCursor(path, "s=1;i=2"))
+	assert.NoError(t, writeCursor(path, "s=1;i=3"))
+	cursor, err = readCursor(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "s=1;i=3", cursor)
+
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{path}, matches, "temporary cursor files should be removed")
+}
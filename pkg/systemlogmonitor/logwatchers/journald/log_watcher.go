@@ -28,6 +28,7 @@ import (
 	"github.com/coreos/go-systemd/v22/sdjournal"
 	"k8s.io/klog/v2"
 
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
 	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
 	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
 	"k8s.io/node-problem-detector/pkg/util"
@@ -46,6 +47,18 @@ type journaldWatcher struct {
 	startTime time.Time
 	logCh     chan *logtypes.Log
 	tomb      *tomb.Tomb
+	// resumed reports whether the journal is resumed from the persisted
+	// cursor, in which case the entries before startTime are not discarded.
+	resumed bool
+	// cursorFile is the file the cursor is persisted to. Empty disables it.
+	cursorFile string
+	// cursor is the cursor of the last journal entry sent to logCh.
+	cursor string
+	// savedCursor is the cursor last persisted to cursorFile.
+	savedCursor string
+	// unsaved is the number of entries sent to logCh since the cursor was
+	// last persisted.
+	unsaved int
 }
 
 // NewJournaldWatcher is the create function of journald watcher.
@@ -60,9 +73,10 @@ func NewJournaldWatcher(cfg types.WatcherConfig) types.LogWatcher {
 	}
 
 	return &journaldWatcher{
-		cfg:       cfg,
-		startTime: startTime,
-		tomb:      tomb.NewTomb(),
+		cfg:        cfg,
+		startTime:  startTime,
+		tomb:       tomb.NewTomb(),
+		cursorFile: cfg.PluginConfig[configCursorFileKey],
 		// A capacity 1000 buffer should be enough
 		logCh: make(chan *logtypes.Log, 1000),
 	}
@@ -73,10 +87,24 @@ var _ types.WatcherCreateFunc = NewJournaldWatcher
 
 // Watch starts the journal watcher.
 func (j *journaldWatcher) Watch() (<-chan *logtypes.Log, error) {
-	journal, err := getJournal(j.cfg, j.startTime)
+	journal, err := getJournal(j.cfg)
 	if err != nil {
 		return nil, err
 	}
+	cursor := ""
+	if j.cursorFile != "" {
+		if cursor, err = readCursor(j.cursorFile); err != nil {
+			klog.Errorf("Failed to read the persisted journal cursor, seeking by lookback instead: %v", err)
+		}
+	}
+	if j.resumed, err = seekJournal(journal, cursor, j.startTime); err != nil {
+		if closeErr := journal.Close(); closeErr != nil {
+			klog.Errorf("Failed to close journal client: %v", closeErr)
+		}
+		return nil, err
+	}
+	j.cursor = cursor
+	j.savedCursor = cursor
 	j.journal = journal
 	go j.watchLoop()
 	return j.logCh, nil
@@ -90,10 +118,19 @@ func (j *journaldWatcher) Stop() {
 // waitLogTimeout is the timeout waiting for new log.
 const waitLogTimeout = 5 * time.Second
 
+// cursorSaveBatchSize is the max number of entries sent to logCh between
+// persisting the cursor while the journal keeps having new entries. It bounds
+// the entries replayed after a crash.
+const cursorSaveBatchSize = 100
+
 // watchLoop is the main watch loop of journald watcher.
 func (j *journaldWatcher) watchLoop() {
 	startTimestamp := timeToJournalTimestamp(j.startTime)
+	if j.resumed {
+		startTimestamp = 0
+	}
 	defer func() {
+		j.saveCursor()
 		if err := j.journal.Close(); err != nil {
 			klog.Errorf("Failed to close journal client: %v", err)
 		}
@@ -112,17 +149,21 @@ func (j *journaldWatcher) watchLoop() {
 			klog.Errorf("Failed to get next journal entry: %v", err)
 			continue
 		}
-		// If next reaches the end, wait for waitLogTimeout.
+		// If next reaches the end, persist the cursor and wait for waitLogTimeout.
 		if n == 0 {
+			j.saveCursor()
 			j.journal.Wait(waitLogTimeout)
 			continue
 		}
 
+		monitormetrics.RecordLogLines(j.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesRead)
 		entry, err := j.journal.GetEntry()
 		if err != nil {
 			klog.Errorf("failed to get journal entry: %v", err)
+			monitormetrics.RecordLogLines(j.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesFailed)
 			continue
 		}
+		monitormetrics.RecordLogLines(j.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesParsed)
 
 		if entry.RealtimeTimestamp < startTimestamp {
 			klog.V(5).Infof("Throwing away journal entry %q before start time: %v < %v",
@@ -131,18 +172,40 @@ func (j *journaldWatcher) watchLoop() {
 		}
 
 		j.logCh <- translate(entry)
+		// The entry is persisted only once logCh accepted it.
+		j.cursor = entry.Cursor
+		j.unsaved++
+		if j.unsaved >= cursorSaveBatchSize {
+			j.saveCursor()
+		}
 	}
 }
 
+// saveCursor persists the cursor of the last journal entry sent to logCh, if it changed.
+func (j *journaldWatcher) saveCursor() {
+	if j.cursorFile == "" || j.cursor == j.savedCursor {
+		return
+	}
+	if err := writeCursor(j.cursorFile, j.cursor); err != nil {
+		klog.Errorf("Failed to persist journal cursor: %v", err)
+		return
+	}
+	j.savedCursor = j.cursor
+	j.unsaved = 0
+}
+
 const (
 	// configSourceKey is the key of source configuration in the plugin configuration.
 	configSourceKey = "source"
 )
 
-// getJournal returns a journal client.
-func getJournal(cfg types.WatcherConfig, startTime time.Time) (*sdjournal.Journal, error) {
+// getJournal returns a journal client with the configured matches.
+func getJournal(cfg types.WatcherConfig) (*sdjournal.Journal, error) {
+	terms, err := parseMatches(cfg.PluginConfig)
+	if err != nil {
+		return nil, err
+	}
 	var journal *sdjournal.Journal
-	var err error
 	if cfg.LogPath == "" {
 		journal, err = sdjournal.NewJournal()
 		if err != nil {
@@ -162,30 +225,62 @@ func getJournal(cfg types.WatcherConfig, startTime time.Time) (*sdjournal.Journa
 			return nil, fmt.Errorf("failed to create journal client from path %q: %v", cfg.LogPath, err)
 		}
 	}
+	if err := addMatches(journal, terms); err != nil {
+		if closeErr := journal.Close(); closeErr != nil {
+			klog.Errorf("Failed to close journal client: %v", closeErr)
+		}
+		return nil, err
+	}
+	return journal, nil
+}
+
+// seekJournal seeks the journal client right after the entry of the cursor,
+// or to startTime if the cursor is empty or no longer valid. It reports
+// whether the journal is resumed from the cursor.
+func seekJournal(journal *sdjournal.Journal, cursor string, startTime time.Time) (bool, error) {
+	if cursor != "" {
+		err := seekCursor(journal, cursor)
+		if err == nil {
+			klog.Infof("Resuming journal from cursor %q", cursor)
+			return true, nil
+		}
+		klog.Warningf("Failed to resume journal from cursor %q, seeking by lookback instead: %v", cursor, err)
+	}
 	// Seek journal client based on startTime.
 	seekTime := startTime
 	now := time.Now()
 	if now.Before(seekTime) {
 		seekTime = now
 	}
-	err = journal.SeekRealtimeUsec(timeToJournalTimestamp(seekTime))
-	if err != nil {
-		return nil, fmt.Errorf("failed to seek journal at %v (now %v): %v", seekTime, now, err)
+	if err := journal.SeekRealtimeUsec(timeToJournalTimestamp(seekTime)); err != nil {
+		return false, fmt.Errorf("failed to seek journal at %v (now %v): %v", seekTime, now, err)
 	}
-	// Empty source is not allowed and treated as an error.
-	source := cfg.PluginConfig[configSourceKey]
-	if source == "" {
-		return nil, fmt.Errorf("failed to filter journal log, empty source is not allowed")
-	}
-	match := sdjournal.Match{
-		Field: sdjournal.SD_JOURNAL_FIELD_SYSLOG_IDENTIFIER,
-		Value: source,
+	return false, nil
+}
+
+// seekCursor seeks the journal client so that the next entry is the one after
+// the entry of the cursor.
+func seekCursor(journal *sdjournal.Journal, cursor string) error {
+	if err := journal.SeekCursor(cursor); err != nil {
+		return err
 	}
-	err = journal.AddMatch(match.String())
+	// Seeking to a cursor positions the journal before the entry, so move onto
+	// it. The next call to Next then returns the first unread entry.
+	n, err := journal.Next()
 	if err != nil {
-		return nil, fmt.Errorf("failed to add log filter %#v: %v", match, err)
+		return err
 	}
-	return journal, nil
+	if n == 0 {
+		return fmt.Errorf("no journal entry at the cursor")
+	}
+	if err := journal.TestCursor(cursor); err != nil {
+		// The entry of the cursor is gone, e.g. rotated away, and the journal is
+		// on the closest entry, which has not been read yet.
+		if _, err := journal.Previous(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // translate translates journal entry into internal type.
@@ -195,6 +290,7 @@ func translate(entry *sdjournal.JournalEntry) *logtypes.Log {
 	return &logtypes.Log{
 		Timestamp: timestamp,
 		Message:   message,
+		Fields:    entry.Fields,
 	}
 }
 
@@ -45,6 +45,19 @@ func TestTranslate(t *testing.T) {
 			log: &logtypes.Log{
 				Timestamp: time.Unix(0, 123456789*1000),
 				Message:   "log message",
+				Fields:    map[string]string{"MESSAGE": "log message"},
+			},
+		},
+		{
+			// has structured fields
+			entry: &sdjournal.JournalEntry{
+				Fields:            map[string]string{"MESSAGE": "log message", "PRIORITY": "3", "_SYSTEMD_UNIT": "kubelet.service"},
+				RealtimeTimestamp: 123456789,
+			},
+			log: &logtypes.Log{
+				Timestamp: time.Unix(0, 123456789*1000),
+				Message:   "log message",
+				Fields:    map[string]string{"MESSAGE": "log message", "PRIORITY": "3", "_SYSTEMD_UNIT": "kubelet.service"},
 			},
 		},
 		{
@@ -56,6 +69,7 @@ func TestTranslate(t *testing.T) {
 			log: &logtypes.Log{
 				Timestamp: time.Unix(0, 987654321*1000),
 				Message:   "",
+				Fields:    map[string]string{},
 			},
 		},
 	}
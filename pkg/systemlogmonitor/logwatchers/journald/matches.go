@@ -0,0 +1,171 @@
+//go:build journald
+// +build journald
+
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package journald
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/sdjournal"
+)
+
+const (
+	// configIdentifiersKey is the key of the comma separated SYSLOG_IDENTIFIERs to watch.
+	configIdentifiersKey = "identifiers"
+	// configUnitsKey is the key of the comma separated systemd units to watch.
+	configUnitsKey = "units"
+	// configTransportsKey is the key of the comma separated journal transports to watch, e.g. kernel.
+	configTransportsKey = "transports"
+	// configPriorityKey is the key of the syslog priority, or priority range such as 0..3, to watch.
+	configPriorityKey = "priority"
+	// configMatchesKey is the key of the journalctl style match expression, e.g.
+	// "_SYSTEMD_UNIT=kubelet.service + _TRANSPORT=kernel PRIORITY=3".
+	configMatchesKey = "matches"
+
+	// disjunction separates the terms of a match expression.
+	disjunction = "+"
+)
+
+// parseMatches parses the journal matches in the plugin configuration into a
+// disjunction of terms. Like journalctl, the matches of the same field in a
+// term are ORed and the matches of different fields are ANDed. The matches
+// configured by source, identifiers, units, transports and priority are added
+// to every term of the match expression.
+func parseMatches(pluginConfig map[string]string) ([][]sdjournal.Match, error) {
+	var common []sdjournal.Match
+	addList := func(field, key string) {
+		for _, value := range strings.Split(pluginConfig[key], ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				common = append(common, sdjournal.Match{Field: field, Value: value})
+			}
+		}
+	}
+	addList(sdjournal.SD_JOURNAL_FIELD_SYSLOG_IDENTIFIER, configSourceKey)
+	addList(sdjournal.SD_JOURNAL_FIELD_SYSLOG_IDENTIFIER, configIdentifiersKey)
+	addList(sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT, configUnitsKey)
+	addList(sdjournal.SD_JOURNAL_FIELD_TRANSPORT, configTransportsKey)
+	if priority := strings.TrimSpace(pluginConfig[configPriorityKey]); priority != "" {
+		priorities, err := parsePriorityRange(priority)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range priorities {
+			common = append(common, sdjournal.Match{Field: sdjournal.SD_JOURNAL_FIELD_PRIORITY, Value: strconv.Itoa(p)})
+		}
+	}
+
+	terms := [][]sdjournal.Match{nil}
+	if expr := strings.TrimSpace(pluginConfig[configMatchesKey]); expr != "" {
+		var err error
+		if terms, err = parseMatchExpression(expr); err != nil {
+			return nil, err
+		}
+	}
+	for i := range terms {
+		terms[i] = append(append([]sdjournal.Match(nil), common...), terms[i]...)
+		if len(terms[i]) == 0 {
+			// An empty term would match every journal entry.
+			return nil, fmt.Errorf("failed to filter journal log, no match is configured")
+		}
+	}
+	return terms, nil
+}
+
+// ValidatePluginConfig validates the journal matches in the journald plugin
+// configuration.
+func ValidatePluginConfig(pluginConfig map[string]string) error {
+	_, err := parseMatches(pluginConfig)
+	return err
+}
+
+// parseMatchExpression parses a journalctl style match expression: FIELD=value
+// matches separated by whitespace, and terms separated by "+".
+func parseMatchExpression(expr string) ([][]sdjournal.Match, error) {
+	terms := [][]sdjournal.Match{nil}
+	for _, token := range strings.Fields(expr) {
+		if token == disjunction {
+			if len(terms[len(terms)-1]) == 0 {
+				return nil, fmt.Errorf("empty term in journal match expression %q", expr)
+			}
+			terms = append(terms, nil)
+			continue
+		}
+		field, value, ok := strings.Cut(token, "=")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid journal match %q in expression %q, expected FIELD=value", token, expr)
+		}
+		terms[len(terms)-1] = append(terms[len(terms)-1], sdjournal.Match{Field: field, Value: value})
+	}
+	if len(terms[len(terms)-1]) == 0 {
+		return nil, fmt.Errorf("empty term in journal match expression %q", expr)
+	}
+	return terms, nil
+}
+
+// parsePriorityRange parses a syslog priority, or an inclusive range such as 0..3.
+func parsePriorityRange(priority string) ([]int, error) {
+	from, to, isRange := strings.Cut(priority, "..")
+	if !isRange {
+		to = from
+	}
+	low, err := parsePriority(from)
+	if err != nil {
+		return nil, err
+	}
+	high, err := parsePriority(to)
+	if err != nil {
+		return nil, err
+	}
+	if low > high {
+		return nil, fmt.Errorf("invalid priority range %q", priority)
+	}
+	var priorities []int
+	for p := low; p <= high; p++ {
+		priorities = append(priorities, p)
+	}
+	return priorities, nil
+}
+
+// parsePriority parses a syslog priority between 0 (emerg) and 7 (debug).
+func parsePriority(priority string) (int, error) {
+	p, err := strconv.Atoi(strings.TrimSpace(priority))
+	if err != nil || p < 0 || p > 7 {
+		return 0, fmt.Errorf("invalid priority %q, expected a number between 0 and 7", priority)
+	}
+	return p, nil
+}
+
+// addMatches adds the disjunction of terms to the journal.
+func addMatches(journal *sdjournal.Journal, terms [][]sdjournal.Match) error {
+	for i, term := range terms {
+		if i > 0 {
+			if err := journal.AddDisjunction(); err != nil {
+				return fmt.Errorf("failed to add log filter disjunction: %v", err)
+			}
+		}
+		for _, match := range term {
+			if err := journal.AddMatch(match.String()); err != nil {
+				return fmt.Errorf("failed to add log filter %#v: %v", match, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+//go:build journald
+// +build journald
+
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package journald
+
+import (
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/sdjournal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMatches(t *testing.T) {
+	testCases := []struct {
+		name         string
+		pluginConfig map[string]string
+		expected     [][]sdjournal.Match
+		expectErr    bool
+	}{
+		{
+			name:         "source",
+			pluginConfig: map[string]string{"source": "kernel"},
+			expected:     [][]sdjournal.Match{{{Field: "SYSLOG_IDENTIFIER", Value: "kernel"}}},
+		},
+		{
+			name: "identifiers, units, transports and priority range",
+			pluginConfig: map[string]string{
+				"identifiers": "kubelet, containerd",
+				"units":       "kubelet.service",
+				"transports":  "kernel",
+				"priority":    "0..2",
+			},
+			expected: [][]sdjournal.Match{{
+				{Field: "SYSLOG_IDENTIFIER", Value: "kubelet"},
+				{Field: "SYSLOG_IDENTIFIER", Value: "containerd"},
+				{Field: "_SYSTEMD_UNIT", Value: "kubelet.service"},
+				{Field: "_TRANSPORT", Value: "kernel"},
+				{Field: "PRIORITY", Value: "0"},
+				{Field: "PRIORITY", Value: "1"},
+				{Field: "PRIORITY", Value: "2"},
+			}},
+		},
+		{
+			name: "match expression",
+			pluginConfig: map[string]string{
+				"matches": "_SYSTEMD_UNIT=kubelet.service + _TRANSPORT=kernel PRIORITY=3",
+			},
+			expected: [][]sdjournal.Match{
+				{{Field: "_SYSTEMD_UNIT", Value: "kubelet.service"}},
+				{{Field: "_TRANSPORT", Value: "kernel"}, {Field: "PRIORITY", Value: "3"}},
+			},
+		},
+		{
+			name: "common matches are added to every term",
+			pluginConfig: map[string]string{
+				"priority": "3",
+				"matches":  "_SYSTEMD_UNIT=kubelet.service + _TRANSPORT=kernel",
+			},
+			expected: [][]sdjournal.Match{
+				{{Field: "PRIORITY", Value: "3"}, {Field: "_SYSTEMD_UNIT", Value: "kubelet.service"}},
+				{{Field: "PRIORITY", Value: "3"}, {Field: "_TRANSPORT", Value: "kernel"}},
+			},
+		},
+		{
+			name:         "no match",
+			pluginConfig: map[string]string{},
+			expectErr:    true,
+		},
+		{
+			name:         "invalid priority",
+			pluginConfig: map[string]string{"priority": "8"},
+			expectErr:    true,
+		},
+		{
+			name:         "reversed priority range",
+			pluginConfig: map[string]string{"priority": "3..1"},
+			expectErr:    true,
+		},
+		{
+			name:         "invalid match",
+			pluginConfig: map[string]string{"matches": "kubelet"},
+			expectErr:    true,
+		},
+		{
+			name:         "empty term",
+			pluginConfig: map[string]string{"matches": "_TRANSPORT=kernel +"},
+			expectErr:    true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			terms, err := parseMatches(test.pluginConfig)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, terms)
+		})
+	}
+}
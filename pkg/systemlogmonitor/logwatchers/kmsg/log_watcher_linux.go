@@ -18,12 +18,14 @@ package kmsg
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/euank/go-kmsg-parser/kmsgparser"
 	"k8s.io/klog/v2"
 
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
 	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
 	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
 	"k8s.io/node-problem-detector/pkg/util"
@@ -78,6 +80,7 @@ func (k *kernelLogWatcher) Watch() (<-chan *logtypes.Log, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create kmsg parser: %v", err)
 		}
+		parser.SetLogger(parserLogger{source: k.cfg.Source})
 		k.kmsgParser = parser
 	}
 
@@ -112,6 +115,9 @@ func (k *kernelLogWatcher) watchLoop() {
 		case msg, ok := <-kmsgs:
 			if !ok {
 				klog.Error("Kmsg channel closed, attempting to restart kmsg parser")
+				if err := monitormetrics.GlobalMonitorMetricsManager.IncrementWatcherRestarts(k.cfg.Source, 1); err != nil {
+					klog.Errorf("Failed to update watcher restart metrics for %q: %v", k.cfg.Source, err)
+				}
 
 				// Close the old parser and clear the reference so the
 				// deferred cleanup doesn't close it a second time.
@@ -130,9 +136,13 @@ func (k *kernelLogWatcher) watchLoop() {
 				continue
 			}
 			klog.V(5).Infof("got kernel message: %+v", msg)
+			// Records kmsgparser failed to parse never get here, they are
+			// counted by parserLogger.
+			monitormetrics.RecordLogLines(k.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesRead)
 			if msg.Message == "" {
 				continue
 			}
+			monitormetrics.RecordLogLines(k.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesParsed)
 
 			// Discard messages before start time.
 			if msg.Timestamp.Before(k.startTime) {
@@ -143,10 +153,7 @@ func (k *kernelLogWatcher) watchLoop() {
 			// The consumer stops draining logCh before calling Stop(), so a
 			// plain send on a full channel could block forever and deadlock Stop().
 			select {
-			case k.logCh <- &logtypes.Log{
-				Message:   strings.TrimSpace(msg.Message),
-				Timestamp: msg.Timestamp,
-			}:
+			case k.logCh <- translate(msg):
 			case <-k.tomb.Stopping():
 				klog.Infof("Stop watching kernel log")
 				return
@@ -155,6 +162,41 @@ func (k *kernelLogWatcher) watchLoop() {
 	}
 }
 
+// parserLogger logs for kmsgparser. kmsgparser drops the records it fails to
+// read or parse after warning about them, so every warning is counted as a
+// log line that was read but failed to parse.
+type parserLogger struct {
+	source string
+}
+
+func (l parserLogger) Warningf(format string, args ...interface{}) {
+	klog.Warningf(format, args...)
+	monitormetrics.RecordLogLines(l.source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesRead)
+	monitormetrics.RecordLogLines(l.source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesFailed)
+}
+
+func (l parserLogger) Infof(format string, args ...interface{}) {
+	klog.Infof(format, args...)
+}
+
+func (l parserLogger) Errorf(format string, args ...interface{}) {
+	klog.Errorf(format, args...)
+}
+
+// translate translates the kernel message into internal type. The syslog
+// level and facility are named after the journal fields of kernel messages.
+func translate(msg kmsgparser.Message) *logtypes.Log {
+	return &logtypes.Log{
+		Message:   strings.TrimSpace(msg.Message),
+		Timestamp: msg.Timestamp,
+		Fields: map[string]string{
+			"PRIORITY":        strconv.Itoa(msg.Priority & 7),
+			"SYSLOG_FACILITY": strconv.Itoa(msg.Priority >> 3),
+			"SEQNUM":          strconv.Itoa(msg.SequenceNumber),
+		},
+	}
+}
+
 // retryCreateParser attempts to create a new kmsg parser.
 // It tries immediately first, then waits retryDelay between subsequent failures.
 // The first attempt is also delayed if the previous restart was less than
@@ -187,6 +229,7 @@ func (k *kernelLogWatcher) retryCreateParser() (<-chan kmsgparser.Message, bool)
 				klog.Errorf("Failed to close kmsg parser after seek failure: %v", closeErr)
 			}
 		} else {
+			parser.SetLogger(parserLogger{source: k.cfg.Source})
 			k.kmsgParser = parser
 			k.lastRestart = time.Now()
 			klog.Infof("Successfully restarted kmsg parser")
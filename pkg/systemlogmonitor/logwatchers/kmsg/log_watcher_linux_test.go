@@ -26,9 +26,11 @@ import (
 	"github.com/euank/go-kmsg-parser/kmsgparser"
 	"github.com/stretchr/testify/assert"
 
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
 	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
 	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
 	"k8s.io/node-problem-detector/pkg/util"
+	"k8s.io/node-problem-detector/pkg/util/metrics"
 	"k8s.io/node-problem-detector/pkg/util/tomb"
 )
 
@@ -42,6 +44,10 @@ type mockKmsgParser struct {
 	closeClosesChannel bool
 	// seekEndErr, if non-nil, is returned from SeekEnd().
 	seekEndErr error
+	// malformed are records the parser warns about and drops before
+	// sending kmsgs, like the real kmsgparser does.
+	malformed []string
+	logger    kmsgparser.Logger
 
 	mu           sync.Mutex
 	closeCount   int
@@ -51,7 +57,11 @@ type mockKmsgParser struct {
 	doneOnce     sync.Once
 }
 
-func (m *mockKmsgParser) SetLogger(kmsgparser.Logger) {}
+func (m *mockKmsgParser) SetLogger(logger kmsgparser.Logger) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger = logger
+}
 
 func (m *mockKmsgParser) Close() error {
 	m.mu.Lock()
@@ -82,9 +92,15 @@ func (m *mockKmsgParser) Parse() <-chan kmsgparser.Message {
 		m.done = make(chan struct{})
 	}
 	done := m.done
+	logger := m.logger
 	m.mu.Unlock()
 
 	go func() {
+		for _, record := range m.malformed {
+			if logger != nil {
+				logger.Warningf("unable to parse kmsg message %q", record)
+			}
+		}
 		for _, msg := range m.kmsgs {
 			select {
 			case c <- msg:
@@ -143,14 +159,14 @@ func TestWatch(t *testing.T) {
 			lookback: "0",
 			delay:    "0",
 			kmsgs: []kmsgparser.Message{
-				{Message: "1", Timestamp: now.Add(0 * time.Second)},
-				{Message: "2", Timestamp: now.Add(1 * time.Second)},
-				{Message: "3", Timestamp: now.Add(2 * time.Second)},
+				{Message: "1", Timestamp: now.Add(0 * time.Second), Priority: 6, SequenceNumber: 1},
+				{Message: "2", Timestamp: now.Add(1 * time.Second), Priority: 3, SequenceNumber: 2},
+				{Message: "3", Timestamp: now.Add(2 * time.Second), Priority: 30, SequenceNumber: 3},
 			},
 			logs: []logtypes.Log{
-				{Timestamp: now, Message: "1"},
-				{Timestamp: now.Add(time.Second), Message: "2"},
-				{Timestamp: now.Add(2 * time.Second), Message: "3"},
+				{Timestamp: now, Message: "1", Fields: map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "0", "SEQNUM": "1"}},
+				{Timestamp: now.Add(time.Second), Message: "2", Fields: map[string]string{"PRIORITY": "3", "SYSLOG_FACILITY": "0", "SEQNUM": "2"}},
+				{Timestamp: now.Add(2 * time.Second), Message: "3", Fields: map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "3", "SEQNUM": "3"}},
 			},
 		},
 		{
@@ -159,13 +175,13 @@ func TestWatch(t *testing.T) {
 			lookback: "0",
 			delay:    "0",
 			kmsgs: []kmsgparser.Message{
-				{Message: "1", Timestamp: now.Add(-1 * time.Second)},
-				{Message: "2", Timestamp: now.Add(0 * time.Second)},
-				{Message: "3", Timestamp: now.Add(1 * time.Second)},
+				{Message: "1", Timestamp: now.Add(-1 * time.Second), Priority: 6, SequenceNumber: 1},
+				{Message: "2", Timestamp: now.Add(0 * time.Second), Priority: 6, SequenceNumber: 2},
+				{Message: "3", Timestamp: now.Add(1 * time.Second), Priority: 6, SequenceNumber: 3},
 			},
 			logs: []logtypes.Log{
-				{Timestamp: now, Message: "2"},
-				{Timestamp: now.Add(time.Second), Message: "3"},
+				{Timestamp: now, Message: "2", Fields: map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "0", "SEQNUM": "2"}},
+				{Timestamp: now.Add(time.Second), Message: "3", Fields: map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "0", "SEQNUM": "3"}},
 			},
 		},
 		{
@@ -174,13 +190,13 @@ func TestWatch(t *testing.T) {
 			lookback: "1s",
 			delay:    "0",
 			kmsgs: []kmsgparser.Message{
-				{Message: "1", Timestamp: now.Add(-2 * time.Second)},
-				{Message: "2", Timestamp: now.Add(-1 * time.Second)},
-				{Message: "3", Timestamp: now.Add(0 * time.Second)},
+				{Message: "1", Timestamp: now.Add(-2 * time.Second), Priority: 6, SequenceNumber: 1},
+				{Message: "2", Timestamp: now.Add(-1 * time.Second), Priority: 6, SequenceNumber: 2},
+				{Message: "3", Timestamp: now.Add(0 * time.Second), Priority: 6, SequenceNumber: 3},
 			},
 			logs: []logtypes.Log{
-				{Timestamp: now.Add(-time.Second), Message: "2"},
-				{Timestamp: now, Message: "3"},
+				{Timestamp: now.Add(-time.Second), Message: "2", Fields: map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "0", "SEQNUM": "2"}},
+				{Timestamp: now, Message: "3", Fields: map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "0", "SEQNUM": "3"}},
 			},
 		},
 		{
@@ -189,14 +205,14 @@ func TestWatch(t *testing.T) {
 			lookback: "3s",
 			delay:    "0",
 			kmsgs: []kmsgparser.Message{
-				{Message: "1", Timestamp: now.Add(-3 * time.Second)},
-				{Message: "2", Timestamp: now.Add(-2 * time.Second)},
-				{Message: "3", Timestamp: now.Add(-1 * time.Second)},
-				{Message: "4", Timestamp: now.Add(0 * time.Second)},
+				{Message: "1", Timestamp: now.Add(-3 * time.Second), Priority: 6, SequenceNumber: 1},
+				{Message: "2", Timestamp: now.Add(-2 * time.Second), Priority: 6, SequenceNumber: 2},
+				{Message: "3", Timestamp: now.Add(-1 * time.Second), Priority: 6, SequenceNumber: 3},
+				{Message: "4", Timestamp: now.Add(0 * time.Second), Priority: 6, SequenceNumber: 4},
 			},
 			logs: []logtypes.Log{
-				{Timestamp: now.Add(-time.Second), Message: "3"},
-				{Timestamp: now, Message: "4"},
+				{Timestamp: now.Add(-time.Second), Message: "3", Fields: map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "0", "SEQNUM": "3"}},
+				{Timestamp: now, Message: "4", Fields: map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "0", "SEQNUM": "4"}},
 			},
 		},
 	}
@@ -468,6 +484,52 @@ func TestWatcherRateLimitsRestarts(t *testing.T) {
 	}
 }
 
+func TestWatchCountsParsedLines(t *testing.T) {
+	original := monitormetrics.GlobalMonitorMetricsManager
+	t.Cleanup(func() { monitormetrics.GlobalMonitorMetricsManager = original })
+	mmm, stub := monitormetrics.NewMonitorMetricsManagerStub()
+	monitormetrics.GlobalMonitorMetricsManager = mmm
+
+	now := time.Now()
+	mock := &mockKmsgParser{
+		malformed: []string{"garbage"},
+		kmsgs: []kmsgparser.Message{
+			{Message: "1", Timestamp: now},
+			{Message: "", Timestamp: now},
+			{Message: "2", Timestamp: now},
+		},
+	}
+	w := &kernelLogWatcher{
+		cfg:       types.WatcherConfig{Source: "kernel-monitor"},
+		startTime: now.Add(-time.Minute),
+		tomb:      tomb.NewTomb(),
+		logCh:     make(chan *logtypes.Log, 100),
+		newParser: func() (kmsgparser.Parser, error) { return mock, nil },
+	}
+	logCh, err := w.Watch()
+	assert.NoError(t, err)
+	defer w.Stop()
+
+	for _, expected := range []string{"1", "2"} {
+		select {
+		case log := <-logCh:
+			assert.Equal(t, expected, log.Message)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for message %q", expected)
+		}
+	}
+	labels := map[string]string{"source": "kernel-monitor"}
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: string(metrics.LogLinesReadID), Labels: labels, Value: 4},
+	}, stub.LogLinesRead.ListMetrics())
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: string(metrics.LogLinesParsedID), Labels: labels, Value: 2},
+	}, stub.LogLinesParsed.ListMetrics())
+	assert.Equal(t, []metrics.Int64MetricRepresentation{
+		{Name: string(metrics.LogLinesFailedID), Labels: labels, Value: 1},
+	}, stub.LogLinesFailed.ListMetrics())
+}
+
 // TestStopDuringRestartClosesOldParserOnce verifies that when Stop() arrives
 // while the watcher is in the restart path, the already-closed old parser is
 // not closed a second time by watchLoop's deferred cleanup.
@@ -616,3 +678,37 @@ func TestWatcherProcessesMessageContent(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslate(t *testing.T) {
+	now := time.Now()
+	// <3> is kernel facility 0 at err level, <30> is daemon facility 3 at info level.
+	testCases := []struct {
+		name     string
+		msg      kmsgparser.Message
+		expected *logtypes.Log
+	}{
+		{
+			name: "kernel err",
+			msg:  kmsgparser.Message{Priority: 3, SequenceNumber: 42, Timestamp: now, Message: " oops "},
+			expected: &logtypes.Log{
+				Timestamp: now,
+				Message:   "oops",
+				Fields:    map[string]string{"PRIORITY": "3", "SYSLOG_FACILITY": "0", "SEQNUM": "42"},
+			},
+		},
+		{
+			name: "daemon info",
+			msg:  kmsgparser.Message{Priority: 30, SequenceNumber: 7, Timestamp: now, Message: "started"},
+			expected: &logtypes.Log{
+				Timestamp: now,
+				Message:   "started",
+				Fields:    map[string]string{"PRIORITY": "6", "SYSLOG_FACILITY": "3", "SEQNUM": "7"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, translate(tc.msg))
+		})
+	}
+}
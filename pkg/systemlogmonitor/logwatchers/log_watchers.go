@@ -17,9 +17,14 @@ limitations under the License.
 package logwatchers
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"k8s.io/klog/v2"
 
 	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
+	"k8s.io/node-problem-detector/pkg/util"
 )
 
 // createFuncs is a table of createFuncs for all supported log watchers.
@@ -30,13 +35,56 @@ func registerLogWatcher(name string, create types.WatcherCreateFunc) {
 	createFuncs[name] = create
 }
 
+// validateFuncs is a table of plugin configuration validators of the log
+// watchers which have one.
+var validateFuncs = map[string]func(map[string]string) error{}
+
+// registerPluginConfigValidator registers a plugin configuration validator for
+// a log watcher.
+func registerPluginConfigValidator(name string, validate func(map[string]string) error) {
+	validateFuncs[name] = validate
+}
+
+// ValidateConfig validates the log watcher configuration without creating the
+// log watcher. The errors are util.ConfigErrors at the JSON path of the invalid
+// value.
+func ValidateConfig(config types.WatcherConfig) []error {
+	var errs []error
+	if _, ok := createFuncs[config.Plugin]; !ok {
+		errs = append(errs, util.NewConfigError("$.plugin", fmt.Errorf("no create function found for plugin %q", config.Plugin)))
+	} else if validate, ok := validateFuncs[config.Plugin]; ok {
+		if err := validate(config.PluginConfig); err != nil {
+			errs = append(errs, util.NewConfigError("$.pluginConfig", err))
+		}
+	}
+	for _, d := range []struct{ name, value string }{{"lookback", config.Lookback}, {"delay", config.Delay}} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d.value); err != nil {
+			errs = append(errs, util.NewConfigError("$."+d.name, fmt.Errorf("failed to parse %s duration %q: %v", d.name, d.value, err)))
+		}
+	}
+	return errs
+}
+
 // GetLogWatcherOrDie get a log watcher based on the passed in configuration.
 // The function panics when encounters an error.
 func GetLogWatcherOrDie(config types.WatcherConfig) types.LogWatcher {
-	create, ok := createFuncs[config.Plugin]
-	if !ok {
-		klog.Fatalf("No create function found for plugin %q", config.Plugin)
+	watcher, err := GetLogWatcher(config)
+	if err != nil {
+		klog.Fatal(err)
+	}
+	return watcher
+}
+
+// GetLogWatcher get a log watcher based on the passed in configuration. The
+// configuration is validated first, because the create functions panic on an
+// invalid one, e.g. when a configuration reload makes it invalid.
+func GetLogWatcher(config types.WatcherConfig) (types.LogWatcher, error) {
+	if errs := ValidateConfig(config); len(errs) != 0 {
+		return nil, errors.Join(errs...)
 	}
 	klog.Infof("Use log watcher of plugin %q", config.Plugin)
-	return create(config)
+	return createFuncs[config.Plugin](config), nil
 }
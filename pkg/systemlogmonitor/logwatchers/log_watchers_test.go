@@ -0,0 +1,69 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logwatchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
+)
+
+func TestGetLogWatcherInvalidConfig(t *testing.T) {
+	for desc, test := range map[string]struct {
+		config types.WatcherConfig
+		err    string
+	}{
+		"unknown plugin": {
+			config: types.WatcherConfig{Plugin: "unknown"},
+			err:    `$.plugin: no create function found for plugin "unknown"`,
+		},
+		"invalid lookback": {
+			config: types.WatcherConfig{Plugin: filelogPluginName, PluginConfig: map[string]string{"format": "syslog"}, Lookback: "1x"},
+			err:    `$.lookback: failed to parse lookback duration "1x"`,
+		},
+		"invalid filelog format": {
+			config: types.WatcherConfig{Plugin: filelogPluginName, PluginConfig: map[string]string{"format": "unknown"}},
+			err:    "$.pluginConfig: ",
+		},
+		"invalid filelog regular expression": {
+			config: types.WatcherConfig{Plugin: filelogPluginName, PluginConfig: map[string]string{"timestamp": "(", "message": ".*", "timestampFormat": "Jan _2 15:04:05"}},
+			err:    "$.pluginConfig: invalid timestamp regular expression",
+		},
+		"invalid syslog network": {
+			config: types.WatcherConfig{Plugin: syslogPluginName, PluginConfig: map[string]string{"network": "sctp", "address": ":514"}},
+			err:    `$.pluginConfig: unknown network "sctp"`,
+		},
+		"missing syslog address": {
+			config: types.WatcherConfig{Plugin: syslogPluginName, PluginConfig: map[string]string{"network": "udp"}},
+			err:    "$.pluginConfig: unexpected empty address",
+		},
+		"invalid syslog timezone": {
+			config: types.WatcherConfig{Plugin: syslogPluginName, PluginConfig: map[string]string{"address": ":514", "timezone": "Nowhere/Invalid"}},
+			err:    "$.pluginConfig: ",
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			watcher, err := GetLogWatcher(test.config)
+			assert.Nil(t, watcher)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
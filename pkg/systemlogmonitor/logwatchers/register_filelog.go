@@ -25,4 +25,5 @@ const filelogPluginName = "filelog"
 func init() {
 	// Register the filelog plugin.
 	registerLogWatcher(filelogPluginName, filelog.NewSyslogWatcherOrDie)
+	registerPluginConfigValidator(filelogPluginName, filelog.ValidatePluginConfig)
 }
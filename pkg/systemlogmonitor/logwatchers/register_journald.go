@@ -27,4 +27,5 @@ const journaldPluginName = "journald"
 func init() {
 	// Register the journald plugin.
 	registerLogWatcher(journaldPluginName, journald.NewJournaldWatcher)
+	registerPluginConfigValidator(journaldPluginName, journald.ValidatePluginConfig)
 }
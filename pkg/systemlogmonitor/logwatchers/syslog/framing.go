@@ -0,0 +1,82 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxMessageSize is the maximum size of a syslog message. It fits the largest
+// UDP datagram.
+const maxMessageSize = 64 * 1024
+
+// frameReader reads the syslog messages of a stream as framed by RFC6587:
+// either octet-counted, e.g. "11 <13>Jan  2 15:04:05 host app: msg", or
+// terminated by a newline. The framing is detected for every message.
+type frameReader struct {
+	r *bufio.Reader
+}
+
+func newFrameReader(r io.Reader) *frameReader {
+	return &frameReader{r: bufio.NewReaderSize(r, maxMessageSize)}
+}
+
+// next returns the next message. It returns io.EOF at the end of the stream.
+func (f *frameReader) next() ([]byte, error) {
+	b, err := f.r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if b[0] >= '1' && b[0] <= '9' {
+		return f.nextOctetCounted()
+	}
+	line, err := f.r.ReadSlice('\n')
+	switch {
+	case errors.Is(err, bufio.ErrBufferFull):
+		return nil, fmt.Errorf("message longer than %d bytes", maxMessageSize)
+	case errors.Is(err, io.EOF) && len(line) != 0:
+		// The last message of the stream is not terminated.
+	case err != nil:
+		return nil, err
+	}
+	return bytes.Clone(line), nil
+}
+
+// nextOctetCounted returns the next octet-counted message.
+func (f *frameReader) nextOctetCounted() ([]byte, error) {
+	length, err := f.r.ReadSlice(' ')
+	if err != nil {
+		return nil, fmt.Errorf("invalid message length %q: %v", length, err)
+	}
+	n, err := strconv.Atoi(string(length[:len(length)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message length %q: %v", length, err)
+	}
+	if n > maxMessageSize {
+		return nil, fmt.Errorf("message length %d is longer than %d bytes", n, maxMessageSize)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(f.r, msg); err != nil {
+		return nil, fmt.Errorf("failed to read message of %d bytes: %v", n, err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syslog
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameReader(t *testing.T) {
+	for desc, test := range map[string]struct {
+		input    string
+		expected []string
+		err      bool
+	}{
+		"newline terminated": {
+			input:    "<13>a\n<13>b\n",
+			expected: []string{"<13>a\n", "<13>b\n"},
+		},
+		"unterminated last message": {
+			input:    "<13>a\n<13>b",
+			expected: []string{"<13>a\n", "<13>b"},
+		},
+		"octet counted": {
+			input:    "5 <13>a6 <13>\nb",
+			expected: []string{"<13>a", "<13>\nb"},
+		},
+		"mixed framing": {
+			input:    "5 <13>a<13>b\n5 <13>c",
+			expected: []string{"<13>a", "<13>b\n", "<13>c"},
+		},
+		"truncated octet counted message": {
+			input:    "10 <13>a",
+			expected: nil,
+			err:      true,
+		},
+		"too long octet counted message": {
+			input: "99999999 <13>a",
+			err:   true,
+		},
+		"too long line": {
+			input: "<13>" + strings.Repeat("a", maxMessageSize),
+			err:   true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			frames := newFrameReader(strings.NewReader(test.input))
+			var messages []string
+			var err error
+			for {
+				var msg []byte
+				if msg, err = frames.next(); err != nil {
+					break
+				}
+				messages = append(messages, string(msg))
+			}
+			assert.Equal(t, test.expected, messages)
+			if test.err {
+				assert.NotErrorIs(t, err, io.EOF)
+			} else {
+				assert.ErrorIs(t, err, io.EOF)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"k8s.io/node-problem-detector/pkg/monitormetrics"
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/syslogparser"
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+	"k8s.io/node-problem-detector/pkg/util/tomb"
+)
+
+const (
+	// networkKey is the key of the network to listen on in the plugin
+	// configuration: udp, tcp or unixgram.
+	networkKey = "network"
+	// addressKey is the key of the address to listen on in the plugin
+	// configuration, e.g. "127.0.0.1:514", or the path of the unix socket.
+	addressKey = "address"
+
+	udpNetwork      = "udp"
+	tcpNetwork      = "tcp"
+	unixgramNetwork = "unixgram"
+)
+
+type syslogWatcher struct {
+	cfg     types.WatcherConfig
+	network string
+	address string
+	parser  *syslogparser.Parser
+	logCh   chan *logtypes.Log
+	tomb    *tomb.Tomb
+	// addr is the address the watcher listens on, which has the actual port
+	// when the configured port is 0.
+	addr net.Addr
+	// mu protects stopped and closers.
+	mu      sync.Mutex
+	stopped bool
+	// closers are the listener and the connections, closed to unblock their
+	// readers when the watcher stops.
+	closers map[io.Closer]struct{}
+	wg      sync.WaitGroup
+}
+
+// NewSyslogWatcherOrDie creates a new syslog watcher. The function panics
+// when encounters an error.
+func NewSyslogWatcherOrDie(cfg types.WatcherConfig) types.LogWatcher {
+	s, err := newSyslogWatcher(cfg)
+	if err != nil {
+		klog.Fatalf("Failed to validate plugin configuration %+v: %v", cfg.PluginConfig, err)
+	}
+	return s
+}
+
+// Make sure NewSyslogWatcherOrDie is types.WatcherCreateFunc.
+var _ types.WatcherCreateFunc = NewSyslogWatcherOrDie
+
+func newSyslogWatcher(cfg types.WatcherConfig) (*syslogWatcher, error) {
+	network, address, err := parseListenConfig(cfg.PluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	parser, err := syslogparser.NewParser(cfg.PluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogWatcher{
+		cfg:     cfg,
+		network: network,
+		address: address,
+		parser:  parser,
+		tomb:    tomb.NewTomb(),
+		closers: make(map[io.Closer]struct{}),
+		// A capacity 1000 buffer should be enough
+		logCh: make(chan *logtypes.Log, 1000),
+	}, nil
+}
+
+// ValidatePluginConfig validates the syslog plugin configuration.
+func ValidatePluginConfig(pluginConfig map[string]string) error {
+	if _, _, err := parseListenConfig(pluginConfig); err != nil {
+		return err
+	}
+	_, err := syslogparser.NewParser(pluginConfig)
+	return err
+}
+
+// parseListenConfig returns the network and the address to listen on.
+func parseListenConfig(pluginConfig map[string]string) (string, string, error) {
+	network := pluginConfig[networkKey]
+	switch network {
+	case "":
+		network = udpNetwork
+	case udpNetwork, tcpNetwork, unixgramNetwork:
+	default:
+		return "", "", fmt.Errorf("unknown network %q, expected one of %q", network,
+			[]string{udpNetwork, tcpNetwork, unixgramNetwork})
+	}
+	address := pluginConfig[addressKey]
+	if address == "" {
+		return "", "", fmt.Errorf("unexpected empty address")
+	}
+	return network, address, nil
+}
+
+// Watch starts listening for syslog messages.
+func (s *syslogWatcher) Watch() (<-chan *logtypes.Log, error) {
+	if s.network == tcpNetwork {
+		l, err := listenConfig.Listen(context.Background(), s.network, s.address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s %s: %v", s.network, s.address, err)
+		}
+		s.addr = l.Addr()
+		s.track(l)
+		s.wg.Add(1)
+		go s.acceptLoop(l)
+	} else {
+		if s.network == unixgramNetwork {
+			removeStaleSocket(s.address)
+		}
+		conn, err := listenConfig.ListenPacket(context.Background(), s.network, s.address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s %s: %v", s.network, s.address, err)
+		}
+		s.addr = conn.LocalAddr()
+		s.track(conn)
+		s.wg.Add(1)
+		go s.readPackets(conn)
+	}
+	klog.Infof("Start receiving syslog on %s %s", s.network, s.addr)
+	go s.watchLoop()
+	return s.logCh, nil
+}
+
+// Stop stops the syslog watcher.
+func (s *syslogWatcher) Stop() {
+	s.tomb.Stop()
+}
+
+// watchLoop waits for the watcher to stop, then closes the listener and the
+// connections and waits for their readers.
+func (s *syslogWatcher) watchLoop() {
+	defer s.tomb.Done()
+	<-s.tomb.Stopping()
+	s.mu.Lock()
+	s.stopped = true
+	for c := range s.closers {
+		closeConn(c)
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	close(s.logCh)
+	klog.Infof("Stop receiving syslog on %s %s", s.network, s.addr)
+}
+
+// track records the closer to close when the watcher stops. It closes the
+// closer and returns false if the watcher already stopped.
+func (s *syslogWatcher) track(c io.Closer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		closeConn(c)
+		return false
+	}
+	s.closers[c] = struct{}{}
+	return true
+}
+
+// untrack closes the closer and forgets it.
+func (s *syslogWatcher) untrack(c io.Closer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	closeConn(c)
+	delete(s.closers, c)
+}
+
+// readPackets receives one message per datagram.
+func (s *syslogWatcher) readPackets(conn net.PacketConn) {
+	defer s.wg.Done()
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				klog.Errorf("Failed to receive syslog message on %s %s: %v", s.network, s.addr, err)
+			}
+			return
+		}
+		if !s.processMessage(string(buf[:n])) {
+			return
+		}
+	}
+}
+
+// acceptLoop accepts the TCP connections.
+func (s *syslogWatcher) acceptLoop(l net.Listener) {
+	defer s.wg.Done()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				klog.Errorf("Failed to accept syslog connection on %s %s: %v", s.network, s.addr, err)
+			}
+			return
+		}
+		if !s.track(conn) {
+			return
+		}
+		s.wg.Add(1)
+		go s.readStream(conn)
+	}
+}
+
+// readStream receives the framed messages of a TCP connection.
+func (s *syslogWatcher) readStream(conn net.Conn) {
+	defer s.wg.Done()
+	defer s.untrack(conn)
+	frames := newFrameReader(conn)
+	for {
+		msg, err := frames.next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				klog.Warningf("Closing syslog connection from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if !s.processMessage(string(msg)) {
+			return
+		}
+	}
+}
+
+// processMessage parses the message and sends it to the log channel. It
+// returns false when the watcher stopped while sending.
+func (s *syslogWatcher) processMessage(msg string) bool {
+	msg = strings.TrimRight(msg, "\r\n\x00")
+	if msg == "" {
+		return true
+	}
+	monitormetrics.RecordLogLines(s.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesRead)
+	if s.inSkipList(msg) {
+		return true
+	}
+	log, err := s.parser.Parse(msg)
+	if err != nil {
+		klog.Warningf("Unable to parse syslog message: %q, %v", msg, err)
+		monitormetrics.RecordLogLines(s.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesFailed)
+		return true
+	}
+	monitormetrics.RecordLogLines(s.cfg.Source, monitormetrics.GlobalMonitorMetricsManager.IncrementLogLinesParsed)
+	// The consumer stops draining logCh before calling Stop(), so a plain
+	// send on a full channel could block forever and deadlock Stop().
+	select {
+	case s.logCh <- log:
+		return true
+	case <-s.tomb.Stopping():
+		return false
+	}
+}
+
+// inSkipList returns whether the message contains an item of the skip list.
+func (s *syslogWatcher) inSkipList(msg string) bool {
+	for _, skipItem := range s.cfg.SkipList {
+		if strings.Contains(msg, skipItem) {
+			return true
+		}
+	}
+	return false
+}
+
+// closeConn closes the listener or connection. It is closed already when the
+// watcher stopped before its reader returned.
+func closeConn(c io.Closer) {
+	if err := c.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		klog.Errorf("Failed to close syslog connection: %v", err)
+	}
+}
+
+// removeStaleSocket removes the unix socket left at the path by a previous
+// run, which would make listening on it fail.
+func removeStaleSocket(path string) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		klog.Warningf("Failed to remove stale socket %q: %v", path, err)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syslog
+
+import (
+	"net"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+// startTestWatcher starts a syslog watcher listening on the network and
+// address, and stops it when the test ends.
+func startTestWatcher(t *testing.T, network, address string, skipList ...string) (*syslogWatcher, <-chan *logtypes.Log) {
+	s, err := newSyslogWatcher(types.WatcherConfig{
+		Plugin:       "syslog",
+		PluginConfig: map[string]string{networkKey: network, addressKey: address, "timezone": "UTC"},
+		SkipList:     skipList,
+	})
+	require.NoError(t, err)
+	logCh, err := s.Watch()
+	require.NoError(t, err)
+	t.Cleanup(s.Stop)
+	return s, logCh
+}
+
+// receiveMessages receives n logs from the channel, and returns their messages.
+func receiveMessages(t *testing.T, logCh <-chan *logtypes.Log, n int) []string {
+	var messages []string
+	for range n {
+		select {
+		case log := <-logCh:
+			messages = append(messages, log.Message)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for log, got %q", messages)
+		}
+	}
+	return messages
+}
+
+func TestWatchUDP(t *testing.T) {
+	s, logCh := startTestWatcher(t, udpNetwork, "127.0.0.1:0", "ignored")
+	conn, err := net.Dial("udp", s.addr.String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, msg := range []string{
+		"<13>Jan  2 15:04:05 host app[12]: ignored message",
+		"<13>Jan  2 15:04:05 host app[12]: first\n",
+		"not syslog",
+		"<34>1 2020-01-02T15:04:05Z host app 123 ID47 - second",
+	} {
+		_, err := conn.Write([]byte(msg))
+		require.NoError(t, err)
+	}
+
+	select {
+	case log := <-logCh:
+		assert.Equal(t, "first", log.Message)
+		assert.Equal(t, map[string]string{
+			"PRIORITY":          "5",
+			"SYSLOG_FACILITY":   "1",
+			"_HOSTNAME":         "host",
+			"SYSLOG_IDENTIFIER": "app",
+			"_PID":              "12",
+		}, log.Fields)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for log")
+	}
+	assert.Equal(t, []string{"second"}, receiveMessages(t, logCh, 1))
+}
+
+func TestWatchTCP(t *testing.T) {
+	s, logCh := startTestWatcher(t, tcpNetwork, "127.0.0.1:0")
+	conn, err := net.Dial("tcp", s.addr.String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	octetCounted := "<34>1 2020-01-02T15:04:05Z host app - - - multi\nline"
+	_, err = conn.Write([]byte("<13>Jan  2 15:04:05 host app: first\n" +
+		strconv.Itoa(len(octetCounted)) + " " + octetCounted +
+		"<13>Jan  2 15:04:06 host app: third\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "multi\nline", "third"}, receiveMessages(t, logCh, 3))
+}
+
+func TestWatchUnixgram(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unixgram is not supported on windows")
+	}
+	address := filepath.Join(t.TempDir(), "syslog.sock")
+	// A socket left by a previous run is replaced.
+	stale, err := net.ListenPacket("unixgram", address)
+	require.NoError(t, err)
+	require.NoError(t, stale.Close())
+
+	_, logCh := startTestWatcher(t, unixgramNetwork, address)
+	conn, err := net.Dial("unixgram", address)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("<13>Jan  2 15:04:05 host app: hello"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello"}, receiveMessages(t, logCh, 1))
+}
+
+func TestWatchSameAddressAsRunningWatcher(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_REUSEPORT is only set on linux")
+	}
+	for _, network := range []string{udpNetwork, tcpNetwork} {
+		t.Run(network, func(t *testing.T) {
+			old, err := newSyslogWatcher(types.WatcherConfig{
+				PluginConfig: map[string]string{networkKey: network, addressKey: "127.0.0.1:0"},
+			})
+			require.NoError(t, err)
+			_, err = old.Watch()
+			require.NoError(t, err)
+			// The watcher of a reloaded configuration starts before the old
+			// one stops.
+			s, logCh := startTestWatcher(t, network, old.addr.String())
+			old.Stop()
+
+			conn, err := net.Dial(network, s.addr.String())
+			require.NoError(t, err)
+			defer conn.Close()
+			_, err = conn.Write([]byte("<13>Jan  2 15:04:05 host app: hello\n"))
+			require.NoError(t, err)
+			assert.Equal(t, []string{"hello"}, receiveMessages(t, logCh, 1))
+		})
+	}
+}
+
+func TestStopClosesConnections(t *testing.T) {
+	s, err := newSyslogWatcher(types.WatcherConfig{
+		PluginConfig: map[string]string{networkKey: tcpNetwork, addressKey: "127.0.0.1:0"},
+	})
+	require.NoError(t, err)
+	logCh, err := s.Watch()
+	require.NoError(t, err)
+	conn, err := net.Dial("tcp", s.addr.String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("<13>Jan  2 15:04:05 host app: hello\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello"}, receiveMessages(t, logCh, 1))
+
+	s.Stop()
+	_, ok := <-logCh
+	assert.False(t, ok, "log channel should be closed")
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err, "connection should be closed")
+}
+
+func TestValidatePluginConfig(t *testing.T) {
+	for desc, test := range map[string]struct {
+		config map[string]string
+		err    bool
+	}{
+		"default network": {config: map[string]string{addressKey: ":514"}},
+		"tcp":             {config: map[string]string{networkKey: "tcp", addressKey: ":514"}},
+		"unknown network": {config: map[string]string{networkKey: "sctp", addressKey: ":514"}, err: true},
+		"no address":      {config: map[string]string{networkKey: "udp"}, err: true},
+		"invalid timezone": {
+			config: map[string]string{addressKey: ":514", "timezone": "Nowhere/Invalid"},
+			err:    true,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			err := ValidatePluginConfig(test.config)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syslogparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	logtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+// The names of the syslog formats in the parse errors.
+const (
+	rfc3164Format = "rfc3164"
+	rfc5424Format = "rfc5424"
+)
+
+// rfc3164TimestampFormats are the layouts of the RFC3164 timestamps. The high
+// precision RFC3339 timestamp of rsyslog is also accepted.
+var rfc3164TimestampFormats = []string{"Jan _2 15:04:05", time.RFC3339Nano}
+
+var (
+	rfc3164Regexp    = regexp.MustCompile(`^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}|\d{4}-\d{2}-\d{2}T\S+)(?: (\S+))?(?: (.*))?$`)
+	rfc3164TagRegexp = regexp.MustCompile(`^([^\s:\[\]]+)(?:\[(\d+)\])?: ?(.*)$`)
+)
+
+// Parser parses syslog messages in either the RFC3164 or the RFC5424 format.
+type Parser struct {
+	timestamps *TimestampParser
+}
+
+// NewParser creates a syslog parser. The RFC3164 timestamps are parsed in the
+// timezone of the plugin configuration.
+func NewParser(pluginConfig map[string]string) (*Parser, error) {
+	timestamps, err := NewTimestampParser(pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Parser{timestamps: timestamps}, nil
+}
+
+// Parse parses the syslog message. The message is in the RFC5424 format when
+// its PRI is followed by a version, and in the RFC3164 format otherwise.
+func (p *Parser) Parse(msg string) (*logtypes.Log, error) {
+	if isRFC5424(msg) {
+		return ParseRFC5424(msg)
+	}
+	return ParseRFC3164(p.timestamps, msg)
+}
+
+// isRFC5424 reports whether the message starts with a PRI and a version, e.g.
+// "<34>1 ".
+func isRFC5424(msg string) bool {
+	if !strings.HasPrefix(msg, "<") {
+		return false
+	}
+	end := strings.IndexByte(msg, '>')
+	if end < 0 {
+		return false
+	}
+	rest := msg[end+1:]
+	digits := 0
+	for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+		digits++
+	}
+	return digits > 0 && digits < len(rest) && rest[digits] == ' '
+}
+
+// addPriority adds the PRIORITY and SYSLOG_FACILITY fields of a syslog PRI.
+func addPriority(fields map[string]string, pri string) error {
+	p, err := strconv.Atoi(pri)
+	if err != nil || p > 191 {
+		return fmt.Errorf("invalid priority %q", pri)
+	}
+	fields["PRIORITY"] = strconv.Itoa(p & 7)
+	fields["SYSLOG_FACILITY"] = strconv.Itoa(p >> 3)
+	return nil
+}
+
+// ParseRFC3164 parses a BSD syslog line. The PRI is optional because it is
+// not written to log files.
+func ParseRFC3164(timestamps *TimestampParser, line string) (*logtypes.Log, error) {
+	matches := rfc3164Regexp.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("line %q is not in %s format", line, rfc3164Format)
+	}
+	fields := map[string]string{}
+	if matches[1] != "" {
+		if err := addPriority(fields, matches[1]); err != nil {
+			return nil, err
+		}
+	}
+	timestamp, err := timestamps.Parse(rfc3164TimestampFormats, matches[2])
+	if err != nil {
+		return nil, err
+	}
+	if matches[3] != "" {
+		fields["_HOSTNAME"] = matches[3]
+	}
+	message := matches[4]
+	if tag := rfc3164TagRegexp.FindStringSubmatch(message); tag != nil {
+		fields["SYSLOG_IDENTIFIER"] = tag[1]
+		if tag[2] != "" {
+			fields["_PID"] = tag[2]
+		}
+		message = tag[3]
+	}
+	return &logtypes.Log{
+		Timestamp: timestamp,
+		Message:   message,
+		Fields:    fields,
+	}, nil
+}
+
+// ParseRFC5424 parses an IETF syslog line. The parameters of the structured
+// data are added as "<SD-ID>.<PARAM-NAME>" fields.
+func ParseRFC5424(line string) (*logtypes.Log, error) {
+	fields := map[string]string{}
+	rest := line
+	if strings.HasPrefix(rest, "<") {
+		end := strings.IndexByte(rest, '>')
+		if end < 0 {
+			return nil, fmt.Errorf("line %q is not in %s format", line, rfc5424Format)
+		}
+		if err := addPriority(fields, rest[1:end]); err != nil {
+			return nil, err
+		}
+		rest = rest[end+1:]
+	}
+	// VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+	header := strings.SplitN(rest, " ", 7)
+	if len(header) < 7 {
+		return nil, fmt.Errorf("line %q is not in %s format", line, rfc5424Format)
+	}
+	if _, err := strconv.ParseUint(header[0], 10, 8); err != nil {
+		return nil, fmt.Errorf("invalid version %q in line %q", header[0], line)
+	}
+	if header[1] == "-" {
+		return nil, fmt.Errorf("no timestamp found in line %q", line)
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, header[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp %q: %v", header[1], err)
+	}
+	for i, key := range []string{"_HOSTNAME", "SYSLOG_IDENTIFIER", "_PID", "MSGID"} {
+		if value := header[i+2]; value != "-" {
+			fields[key] = value
+		}
+	}
+	message, err := parseStructuredData(header[6], fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse structured data of line %q: %v", line, err)
+	}
+	return &logtypes.Log{
+		Timestamp: timestamp,
+		Message:   strings.TrimPrefix(message, "\ufeff"),
+		Fields:    fields,
+	}, nil
+}
+
+// parseStructuredData adds the parameters of the RFC5424 structured data at
+// the beginning of s to fields, and returns the message following it.
+func parseStructuredData(s string, fields map[string]string) (string, error) {
+	if strings.HasPrefix(s, "-") {
+		s = s[1:]
+	} else {
+		if !strings.HasPrefix(s, "[") {
+			return "", fmt.Errorf("unexpected structured data %q", s)
+		}
+		for strings.HasPrefix(s, "[") {
+			end := strings.IndexAny(s, " ]")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated structured data element %q", s)
+			}
+			id := s[1:end]
+			s = s[end:]
+			for !strings.HasPrefix(s, "]") {
+				// Each parameter is ` PARAM-NAME="PARAM-VALUE"`.
+				eq := strings.Index(s, `="`)
+				if !strings.HasPrefix(s, " ") || eq < 0 {
+					return "", fmt.Errorf("invalid structured data parameter %q", s)
+				}
+				name := s[1:eq]
+				value, n, err := parseParamValue(s[eq+2:])
+				if err != nil {
+					return "", err
+				}
+				fields[id+"."+name] = value
+				s = s[eq+2+n:]
+			}
+			s = s[1:]
+		}
+	}
+	if s == "" {
+		return "", nil
+	}
+	if s[0] != ' ' {
+		return "", fmt.Errorf("missing space before message %q", s)
+	}
+	return s[1:], nil
+}
+
+// parseParamValue unescapes the structured data parameter value at the
+// beginning of s, and returns it with the length consumed including the
+// closing quote.
+func parseParamValue(s string) (string, int, error) {
+	var value strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return value.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0 {
+				i++
+			}
+		}
+		value.WriteByte(s[i])
+	}
+	return "", 0, fmt.Errorf("unterminated structured data parameter value %q", s)
+}
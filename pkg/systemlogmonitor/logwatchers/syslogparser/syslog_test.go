@@ -0,0 +1,64 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syslogparser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testclock "k8s.io/utils/clock/testing"
+)
+
+func TestParser(t *testing.T) {
+	p, err := NewParser(map[string]string{TimezoneKey: "UTC"})
+	require.NoError(t, err)
+	p.timestamps.SetClock(testclock.NewFakeClock(time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)))
+
+	log, err := p.Parse("<34>1 2020-01-02T15:04:05Z host app 123 ID47 - hello")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2020, time.January, 2, 15, 4, 5, 0, time.UTC), log.Timestamp)
+	assert.Equal(t, "hello", log.Message)
+	assert.Equal(t, map[string]string{
+		"PRIORITY":          "2",
+		"SYSLOG_FACILITY":   "4",
+		"_HOSTNAME":         "host",
+		"SYSLOG_IDENTIFIER": "app",
+		"_PID":              "123",
+		"MSGID":             "ID47",
+	}, log.Fields)
+
+	log, err = p.Parse("<13>Jan  2 15:04:05 host kernel: hello")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2020, time.January, 2, 15, 4, 5, 0, time.UTC), log.Timestamp)
+	assert.Equal(t, "hello", log.Message)
+	assert.Equal(t, map[string]string{
+		"PRIORITY":          "5",
+		"SYSLOG_FACILITY":   "1",
+		"_HOSTNAME":         "host",
+		"SYSLOG_IDENTIFIER": "kernel",
+	}, log.Fields)
+
+	_, err = p.Parse("<13>not syslog")
+	assert.Error(t, err)
+}
+
+func TestNewParserInvalidTimezone(t *testing.T) {
+	_, err := NewParser(map[string]string{TimezoneKey: "Nowhere/Invalid"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package syslogparser parses syslog messages in the RFC3164 and RFC5424
+// formats, and the log timestamps without year or time zone. It is shared by
+// the log watchers which read syslog messages.
+package syslogparser
+
+import (
+	"fmt"
+	"time"
+	// Embed the time zone database for the images without one.
+	_ "time/tzdata"
+
+	"k8s.io/utils/clock"
+)
+
+const (
+	// TimezoneKey is the key of the IANA time zone of the timestamps without
+	// time zone in the plugin configuration, e.g. "UTC". The local time zone
+	// is used by default.
+	TimezoneKey = "timezone"
+	// maxFutureSkew is how far in the future a timestamp without year may be
+	// before it is considered to be from an earlier year.
+	maxFutureSkew = 24 * time.Hour
+)
+
+// TimestampParser parses log timestamps in the configured time zone, and
+// infers the year of the timestamps without year relative to now.
+type TimestampParser struct {
+	location *time.Location
+	clock    clock.Clock
+}
+
+// NewTimestampParser creates a timestamp parser in the time zone of the plugin
+// configuration.
+func NewTimestampParser(pluginConfig map[string]string) (*TimestampParser, error) {
+	location := time.Local
+	if timezone := pluginConfig[TimezoneKey]; timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+		}
+	}
+	return &TimestampParser{
+		location: location,
+		clock:    clock.RealClock{},
+	}, nil
+}
+
+// SetClock sets the clock the years of the timestamps without year are
+// inferred from, e.g. a fake clock when replaying logs.
+func (p *TimestampParser) SetClock(clock clock.Clock) {
+	p.clock = clock
+}
+
+// Parse parses the timestamp with the first matching layout.
+func (p *TimestampParser) Parse(layouts []string, value string) (time.Time, error) {
+	for _, layout := range layouts {
+		timestamp, err := time.ParseInLocation(layout, value, p.location)
+		if err == nil {
+			return p.inferYear(timestamp), nil
+		}
+	}
+	if len(layouts) == 1 {
+		return time.Time{}, fmt.Errorf("failed to parse timestamp %q with format %q", value, layouts[0])
+	}
+	return time.Time{}, fmt.Errorf("failed to parse timestamp %q with any of formats %q", value, layouts)
+}
+
+// inferYear sets the year of a timestamp without year, e.g. a syslog
+// timestamp. The latest year that doesn't put the timestamp more than
+// maxFutureSkew after now is used, so that on 1 January the lines of
+// 31 December belong to the previous year.
+func (p *TimestampParser) inferYear(t time.Time) time.Time {
+	if t.Year() != 0 {
+		return t
+	}
+	now := p.clock.Now().In(p.location)
+	for year := now.Year() + 1; ; year-- {
+		candidate := time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), p.location)
+		// 29 February only exists in leap years.
+		if candidate.Month() != t.Month() {
+			continue
+		}
+		if !candidate.After(now.Add(maxFutureSkew)) {
+			return candidate
+		}
+	}
+}
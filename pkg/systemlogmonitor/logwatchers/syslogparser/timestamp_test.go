@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syslogparser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testclock "k8s.io/utils/clock/testing"
+)
+
+func TestTimestampParserInferYear(t *testing.T) {
+	testCases := map[string]struct {
+		now    time.Time
+		layout string
+		value  string
+		want   time.Time
+	}{
+		"same year": {
+			now:    time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC),
+			layout: "Jan _2 15:04:05",
+			value:  "May  1 12:23:45",
+			want:   time.Date(2021, time.May, 1, 12, 23, 45, 0, time.UTC),
+		},
+		"previous year after new year": {
+			now:    time.Date(2021, time.January, 1, 0, 5, 0, 0, time.UTC),
+			layout: "Jan _2 15:04:05",
+			value:  "Dec 31 23:59:59",
+			want:   time.Date(2020, time.December, 31, 23, 59, 59, 0, time.UTC),
+		},
+		"later month of previous year": {
+			now:    time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC),
+			layout: "Jan _2 15:04:05",
+			value:  "Jul  1 00:00:00",
+			want:   time.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"next year before new year": {
+			now:    time.Date(2020, time.December, 31, 23, 59, 0, 0, time.UTC),
+			layout: "Jan _2 15:04:05",
+			value:  "Jan  1 00:00:01",
+			want:   time.Date(2021, time.January, 1, 0, 0, 1, 0, time.UTC),
+		},
+		"slightly in the future": {
+			now:    time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC),
+			layout: "Jan _2 15:04:05",
+			value:  "Jun  1 00:10:00",
+			want:   time.Date(2021, time.June, 1, 0, 10, 0, 0, time.UTC),
+		},
+		"leap day": {
+			now:    time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC),
+			layout: "Jan _2 15:04:05",
+			value:  "Feb 29 12:00:00",
+			want:   time.Date(2020, time.February, 29, 12, 0, 0, 0, time.UTC),
+		},
+		"full timestamp": {
+			now:    time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+			layout: time.RFC3339,
+			value:  "2021-12-31T23:59:59Z",
+			want:   time.Date(2021, time.December, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewTimestampParser(map[string]string{TimezoneKey: "UTC"})
+			require.NoError(t, err)
+			p.SetClock(testclock.NewFakeClock(test.now))
+			got, err := p.Parse([]string{test.layout}, test.value)
+			require.NoError(t, err)
+			assert.True(t, test.want.Equal(got), "want %v, got %v", test.want, got)
+		})
+	}
+}
+
+func TestTimestampParserTimezone(t *testing.T) {
+	p, err := NewTimestampParser(map[string]string{TimezoneKey: "Etc/GMT-9"})
+	require.NoError(t, err)
+	p.SetClock(testclock.NewFakeClock(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)))
+
+	got, err := p.Parse([]string{"Jan _2 15:04:05"}, "May  1 09:00:00")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC).Equal(got), "got %v", got)
+
+	// An explicit offset in the timestamp takes precedence.
+	got, err = p.Parse([]string{time.RFC3339}, "2021-05-01T09:00:00Z")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2021, time.May, 1, 9, 0, 0, 0, time.UTC).Equal(got), "got %v", got)
+
+	_, err = NewTimestampParser(map[string]string{TimezoneKey: "Nowhere/Invalid"})
+	assert.Error(t, err)
+}
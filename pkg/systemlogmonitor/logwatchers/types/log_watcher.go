@@ -31,7 +31,7 @@ type LogWatcher interface {
 // WatcherConfig is the configuration of the log watcher.
 type WatcherConfig struct {
 	// Plugin is the name of plugin which is currently used.
-	// Currently supported: filelog, journald, kmsg.
+	// Currently supported: filelog, journald, kmsg, syslog.
 	Plugin string `json:"plugin,omitempty"`
 	// PluginConfig is a key/value configuration of a plugin. Valid configurations
 	// are defined in different log watcher plugin.
@@ -46,6 +46,9 @@ type WatcherConfig struct {
 	// useful when the log watcher needs to wait for some time until the node
 	// becomes stable.
 	Delay string `json:"delay,omitempty"`
+	// Source is the source of the log monitor the watcher belongs to. It labels
+	// the metrics of the watcher and is set by the log monitor.
+	Source string `json:"-"`
 }
 
 // WatcherCreateFunc is the create function of a log watcher.
@@ -0,0 +1,67 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"time"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+// matchWindow counts the matches of a rule in a sliding window of log time.
+// matchWindow is not safe for concurrent use.
+type matchWindow struct {
+	// count is the number of matches within window that fires the rule.
+	count  int
+	window time.Duration
+	// revertPattern takes back the most recent match when it matches.
+	revertPattern *Pattern
+	// matches are the timestamps of the counted matches, from oldest to newest.
+	matches []time.Time
+}
+
+func newMatchWindow(count int, window time.Duration, revertPattern *Pattern) *matchWindow {
+	return &matchWindow{count: count, window: window, revertPattern: revertPattern}
+}
+
+// observe counts the logs matched by the rule after the latest log is pushed
+// into the buffer, and the match of the revert pattern. It returns the matched
+// logs if the rule matched count times within the window, and nil otherwise.
+// The window restarts counting once the rule fires.
+func (w *matchWindow) observe(buffer LogBuffer, matched []*types.Log) []*types.Log {
+	if len(matched) != 0 {
+		w.matches = append(w.matches, matched[0].Timestamp)
+	}
+	if w.revertPattern != nil && len(w.matches) != 0 && len(buffer.Match(w.revertPattern)) != 0 {
+		w.matches = w.matches[:len(w.matches)-1]
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	// Drop the matches that slid out of the window ending at the latest match.
+	start := matched[0].Timestamp.Add(-w.window)
+	expired := 0
+	for expired < len(w.matches) && !w.matches[expired].After(start) {
+		expired++
+	}
+	w.matches = w.matches[expired:]
+	if len(w.matches) < w.count {
+		return nil
+	}
+	w.matches = nil
+	return matched
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+func TestMatchWindow(t *testing.T) {
+	type line struct {
+		second  int64
+		message string
+		fire    bool
+	}
+	for _, test := range []struct {
+		name          string
+		count         int
+		window        time.Duration
+		revertPattern string
+		lines         []line
+	}{
+		{
+			name:   "fires on count matches within window",
+			count:  3,
+			window: time.Minute,
+			lines: []line{
+				{second: 0, message: "problem"},
+				{second: 10, message: "other"},
+				{second: 20, message: "problem"},
+				{second: 30, message: "problem", fire: true},
+			},
+		},
+		{
+			name:   "restarts counting after firing",
+			count:  2,
+			window: time.Minute,
+			lines: []line{
+				{second: 0, message: "problem"},
+				{second: 1, message: "problem", fire: true},
+				{second: 2, message: "problem"},
+				{second: 3, message: "problem", fire: true},
+			},
+		},
+		{
+			name:   "matches slide out of window",
+			count:  2,
+			window: time.Minute,
+			lines: []line{
+				{second: 0, message: "problem"},
+				{second: 60, message: "problem"},
+				{second: 90, message: "problem", fire: true},
+			},
+		},
+		{
+			name:          "revert pattern takes back a match",
+			count:         2,
+			window:        time.Minute,
+			revertPattern: "revert",
+			lines: []line{
+				{second: 0, message: "problem"},
+				{second: 1, message: "revert"},
+				{second: 2, message: "problem"},
+				{second: 3, message: "revert"},
+				{second: 4, message: "revert"},
+				{second: 5, message: "problem"},
+				{second: 6, message: "problem", fire: true},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pattern, err := CompilePattern("problem")
+			assert.NoError(t, err)
+			var revertPattern *Pattern
+			if test.revertPattern != "" {
+				revertPattern, err = CompilePattern(test.revertPattern)
+				assert.NoError(t, err)
+			}
+			w := newMatchWindow(test.count, test.window, revertPattern)
+			buffer := NewLogBuffer(1)
+			for _, line := range test.lines {
+				log := &types.Log{Timestamp: time.Unix(line.second, 0), Message: line.message}
+				buffer.Push(log)
+				got := w.observe(buffer, buffer.Match(pattern))
+				if line.fire {
+					assert.Equal(t, []*types.Log{log}, got, "line at %ds", line.second)
+				} else {
+					assert.Nil(t, got, "line at %ds", line.second)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMatchWindows(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		rule    types.Rule
+		wantErr bool
+	}{
+		{
+			name: "rule without count",
+			rule: types.Rule{Type: "temporary", Pattern: "problem"},
+		},
+		{
+			name: "count rule",
+			rule: types.Rule{Type: "temporary", Pattern: "problem", Count: 5, Window: "10m", RevertPattern: "revert"},
+		},
+		{
+			name:    "window without count",
+			rule:    types.Rule{Type: "temporary", Pattern: "problem", Window: "10m"},
+			wantErr: true,
+		},
+		{
+			name:    "count without window",
+			rule:    types.Rule{Type: "temporary", Pattern: "problem", Count: 5},
+			wantErr: true,
+		},
+		{
+			name:    "negative count",
+			rule:    types.Rule{Type: "temporary", Pattern: "problem", Count: -1, Window: "10m"},
+			wantErr: true,
+		},
+		{
+			name:    "count on recovery rule",
+			rule:    types.Rule{Type: types.Recovery, Pattern: "problem", Count: 5, Window: "10m"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid revert pattern",
+			rule:    types.Rule{Type: "temporary", Pattern: "problem", Count: 5, Window: "10m", RevertPattern: "("},
+			wantErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			windows, err := MonitorConfig{Rules: []types.Rule{test.rule}}.parseMatchWindows()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.rule.Count != 0, windows[0] != nil)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"time"
+
+	testingclock "k8s.io/utils/clock/testing"
+
+	watchertypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers/types"
+	systemlogtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// Replayer runs recorded logs through a log monitor, as if they were read by
+// its log watcher, and collects the statuses the log monitor reports. The
+// clock of the log monitor is a fake clock following the timestamps of the
+// logs, and no problem metrics are reported.
+type Replayer struct {
+	monitor  *logMonitor
+	clock    *testingclock.FakeClock
+	statuses []*types.Status
+}
+
+// NewReplayer creates a Replayer of the log monitor configured by the
+// configuration file, returns error if the configuration is invalid.
+func NewReplayer(configPath string) (*Replayer, error) {
+	l, err := loadLogMonitor(configPath)
+	if err != nil {
+		return nil, err
+	}
+	disabled := false
+	l.config.EnableMetricsReporting = &disabled
+	clock := testingclock.NewFakeClock(time.Time{})
+	l.clock = clock
+	// The statuses are collected after each step of the replay. A step
+	// parses at most two logs, each reporting at most two statuses per rule:
+	// the summary of the events suppressed by its cooldown, and its own.
+	l.output = make(chan *types.Status, 4*len(l.config.Rules)+1)
+	return &Replayer{monitor: l, clock: clock}, nil
+}
+
+// WatcherConfig returns the log watcher configuration of the log monitor.
+func (r *Replayer) WatcherConfig() watchertypes.WatcherConfig {
+	config := r.monitor.config.WatcherConfig
+	config.Source = r.monitor.config.Source
+	return config
+}
+
+// Replay runs the logs through the log monitor and returns the statuses it
+// reports, starting with the initial status. Conditions whose clearAfter
+// duration elapses between two logs are reset at their deadline, the summaries
+// of suppressed events are reported when their cooldown window elapses, even
+// after the last log, and the open multi-line block is flushed after the last
+// log. Replay can only be called
+// once.
+func (r *Replayer) Replay(logs []*systemlogtypes.Log) []*types.Status {
+	l := r.monitor
+	if len(logs) != 0 {
+		r.clock.SetTime(logs[0].Timestamp)
+	} else {
+		r.clock.SetTime(time.Now())
+	}
+	l.initializeStatus()
+	r.collect()
+	for _, log := range logs {
+		r.advance(log.Timestamp)
+		l.processLog(log)
+		r.collect()
+	}
+	if l.blocks != nil {
+		if block := l.blocks.flush(); block != nil {
+			l.parseLog(block)
+			r.collect()
+		}
+	}
+	r.summarize()
+	return r.statuses
+}
+
+// summarize reports the summaries of the events suppressed in the last
+// cooldown windows at the end of their windows.
+func (r *Replayer) summarize() {
+	for {
+		next, ok := r.nextSummaryDeadline()
+		if !ok {
+			return
+		}
+		if next.After(r.clock.Now()) {
+			r.clock.SetTime(next)
+		}
+		if status := r.monitor.summarizeSuppressedEvents(r.clock.Now()); status != nil {
+			r.monitor.sendStatus(status)
+			r.collect()
+		}
+	}
+}
+
+// collect collects the statuses reported by the log monitor so far.
+func (r *Replayer) collect() {
+	for {
+		select {
+		case status := <-r.monitor.output:
+			r.statuses = append(r.statuses, status)
+		default:
+			return
+		}
+	}
+}
+
+// advance moves the clock forward to the time. The conditions whose clearAfter
+// duration elapses until then are reset at their deadline, the summaries of
+// the cooldown windows elapsing until then are reported at their deadline, and
+// the multi-line block idling until then is flushed.
+func (r *Replayer) advance(to time.Time) {
+	l := r.monitor
+	for {
+		deadline, ok := r.nextDeadline()
+		if !ok || deadline.After(to) {
+			break
+		}
+		if deadline.After(r.clock.Now()) {
+			r.clock.SetTime(deadline)
+		}
+		if status := l.expireConditions(); status != nil {
+			l.sendStatus(status)
+			r.collect()
+		}
+		if status := l.summarizeSuppressedEvents(r.clock.Now()); status != nil {
+			l.sendStatus(status)
+			r.collect()
+		}
+	}
+	if to.After(r.clock.Now()) {
+		r.clock.SetTime(to)
+	}
+	if l.blocks != nil {
+		if block := l.blocks.flushIdle(r.clock.Now()); block != nil {
+			l.parseLog(block)
+			r.collect()
+		}
+	}
+}
+
+// nextDeadline returns the earliest time at which a condition expires or the
+// summary of a cooldown window is due, false if there is none.
+func (r *Replayer) nextDeadline() (time.Time, bool) {
+	next, _ := r.nextSummaryDeadline()
+	for _, deadline := range r.monitor.expiry {
+		if next.IsZero() || deadline.Before(next) {
+			next = deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// nextSummaryDeadline returns the earliest time at which the summary of a
+// cooldown window is due, false if there is none.
+func (r *Replayer) nextSummaryDeadline() (time.Time, bool) {
+	var next time.Time
+	for _, limiter := range r.monitor.limiters {
+		if limiter == nil {
+			continue
+		}
+		if deadline, ok := limiter.Deadline(); ok && (next.IsZero() || deadline.Before(next)) {
+			next = deadline
+		}
+	}
+	return next, !next.IsZero()
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	systemlogtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+const replayTestConfig = `{
+  "plugin": "kmsg",
+  "source": "kernel-monitor",
+  "conditions": [
+    {"type": "KernelDeadlock", "reason": "KernelHasNoDeadlock", "message": "kernel has no deadlock"}
+  ],
+  "rules": [
+    {"type": "temporary", "reason": "OOMKilling", "pattern": "Killed process \\d+ .*"},
+    {
+      "type": "permanent",
+      "condition": "KernelDeadlock",
+      "reason": "DockerHung",
+      "severity": "critical",
+      "pattern": "task docker:\\w+ blocked for more than \\w+ seconds\\.",
+      "clearAfter": "10m"
+    }
+  ]
+}`
+
+func TestReplay(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "kernel-monitor.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(replayTestConfig), 0644))
+	replayer, err := NewReplayer(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "kmsg", replayer.WatcherConfig().Plugin)
+	assert.Equal(t, "kernel-monitor", replayer.WatcherConfig().Source)
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	hung := "task docker:1234 blocked for more than 120 seconds."
+	statuses := replayer.Replay([]*systemlogtypes.Log{
+		{Timestamp: start, Message: "Linux version 6.1"},
+		{Timestamp: start.Add(time.Minute), Message: hung},
+		{Timestamp: start.Add(2 * time.Minute), Message: "Killed process 1234 (nginx)"},
+		{Timestamp: start.Add(time.Hour), Message: "Linux version 6.1"},
+	})
+
+	defaultCondition := types.Condition{
+		Type:       "KernelDeadlock",
+		Status:     types.False,
+		Transition: start,
+		Reason:     "KernelHasNoDeadlock",
+		Message:    "kernel has no deadlock",
+	}
+	hungCondition := types.Condition{
+		Type:       "KernelDeadlock",
+		Status:     types.True,
+		Transition: start.Add(time.Minute),
+		Reason:     "DockerHung",
+		Message:    hung,
+		Severity:   types.Critical,
+	}
+	expiredCondition := defaultCondition
+	expiredCondition.Transition = start.Add(11 * time.Minute)
+	assert.Equal(t, []*types.Status{
+		{
+			Source:     "kernel-monitor",
+			Conditions: []types.Condition{defaultCondition},
+		},
+		{
+			Source: "kernel-monitor",
+			Events: []types.Event{{
+				Severity:  types.Critical,
+				Timestamp: start.Add(time.Minute),
+				Reason:    "DockerHung",
+				Message:   `Node condition KernelDeadlock is now: True, reason: DockerHung, message: "` + hung + `"`,
+			}},
+			Conditions: []types.Condition{hungCondition},
+		},
+		{
+			Source: "kernel-monitor",
+			Events: []types.Event{{
+				Severity:  types.Warn,
+				Timestamp: start.Add(2 * time.Minute),
+				Reason:    "OOMKilling",
+				Message:   "Killed process 1234 (nginx)",
+			}},
+			Conditions: []types.Condition{hungCondition},
+		},
+		{
+			Source: "kernel-monitor",
+			Events: []types.Event{{
+				Severity:  types.Info,
+				Timestamp: start.Add(11 * time.Minute),
+				Reason:    "KernelHasNoDeadlock",
+				Message:   `Node condition KernelDeadlock is now: False, reason: KernelHasNoDeadlock, message: "kernel has no deadlock"`,
+			}},
+			Conditions: []types.Condition{expiredCondition},
+		},
+	}, statuses)
+}
+
+func TestReplaySummarizesSuppressedEvents(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "kernel-monitor.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+  "plugin": "kmsg",
+  "source": "kernel-monitor",
+  "rules": [
+    {"type": "temporary", "reason": "OOMKilling", "pattern": "Killed process \\d+ .*", "cooldown": "5m"}
+  ]
+}`), 0644))
+	replayer, err := NewReplayer(configPath)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var logs []*systemlogtypes.Log
+	for i := range 4 {
+		logs = append(logs, &systemlogtypes.Log{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Message:   fmt.Sprintf("Killed process %d (nginx)", i),
+		})
+	}
+	statuses := replayer.Replay(logs)
+
+	oom := func(timestamp time.Time, message string) *types.Status {
+		return &types.Status{
+			Source: "kernel-monitor",
+			Events: []types.Event{{
+				Severity:  types.Warn,
+				Timestamp: timestamp,
+				Reason:    "OOMKilling",
+				Message:   message,
+			}},
+		}
+	}
+	// The summary of the suppressed events is reported when the window
+	// elapses, even after the last log.
+	assert.Equal(t, []*types.Status{
+		{Source: "kernel-monitor"},
+		oom(start, "Killed process 0 (nginx)"),
+		oom(start.Add(5*time.Minute), "Killed process 3 (nginx) (and 2 more in the last 5m0s)"),
+	}, statuses)
+}
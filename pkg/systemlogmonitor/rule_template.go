@@ -0,0 +1,202 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+	npdtypes "k8s.io/node-problem-detector/pkg/types"
+)
+
+// metricLabelRegexp matches the valid metric label names.
+var metricLabelRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// problem is the reason, message, severity and extra metric labels a matched
+// rule reports.
+type problem struct {
+	reason  string
+	message string
+	// severity is the configured severity of the rule, empty if it has none.
+	severity npdtypes.Severity
+	// labels are the extra labels of the problem counter.
+	labels map[string]string
+}
+
+// defaultProblem returns the problem of a rule without templates.
+func defaultProblem(logs []*types.Log, rule types.Rule) problem {
+	// The severity was validated with the rules.
+	severity, _ := npdtypes.ParseSeverity(rule.Severity)
+	return problem{
+		reason:   rule.Reason,
+		message:  generateMessage(logs, rule.PatternGeneratedMessageSuffix),
+		severity: severity,
+	}
+}
+
+// eventSeverity returns the severity of the events of the problem.
+func (p problem) eventSeverity() npdtypes.Severity {
+	if p.severity == "" {
+		return npdtypes.Warn
+	}
+	return p.severity
+}
+
+// counterLabels returns the extra problem counter labels of the problem.
+func (p problem) counterLabels() map[string]string {
+	if p.severity == "" {
+		return p.labels
+	}
+	labels := map[string]string{"severity": string(p.severity)}
+	for name, value := range p.labels {
+		labels[name] = value
+	}
+	return labels
+}
+
+// ruleTemplate renders the problem of a rule from the named capture groups of
+// its pattern.
+type ruleTemplate struct {
+	pattern *Pattern
+	reason  *template.Template
+	message *template.Template
+	labels  []string
+	suffix  string
+}
+
+// compileRuleTemplate compiles the templates and metric labels of the rule. It
+// returns nil if the rule has none.
+func compileRuleTemplate(rule types.Rule, pattern *Pattern) (*ruleTemplate, error) {
+	if rule.ReasonTemplate == "" && rule.MessageTemplate == "" && len(rule.MetricLabels) == 0 {
+		return nil, nil
+	}
+	t := &ruleTemplate{
+		pattern: pattern,
+		labels:  rule.MetricLabels,
+		suffix:  rule.PatternGeneratedMessageSuffix,
+	}
+	groups := pattern.groupNames()
+	var err error
+	if rule.ReasonTemplate != "" {
+		if t.reason, err = parseRuleTemplate("reason", rule.ReasonTemplate); err != nil {
+			return nil, err
+		}
+	}
+	if rule.MessageTemplate != "" {
+		if t.message, err = parseRuleTemplate("message", rule.MessageTemplate); err != nil {
+			return nil, err
+		}
+	}
+	for _, label := range rule.MetricLabels {
+		if !slices.Contains(groups, label) {
+			return nil, fmt.Errorf("metric label %q is not a named capture group of pattern %q", label, rule.Pattern)
+		}
+		if label == "reason" || label == "severity" || !metricLabelRegexp.MatchString(label) {
+			return nil, fmt.Errorf("invalid metric label %q", label)
+		}
+	}
+	// Render once with empty captures, so that a reference to a group the
+	// pattern doesn't have fails now rather than on the first match.
+	empty := make(map[string]string, len(groups))
+	for _, group := range groups {
+		empty[group] = ""
+	}
+	if _, err := t.renderCaptures(problem{}, empty); err != nil {
+		return nil, fmt.Errorf("invalid template of rule %q: %v", rule.Reason, err)
+	}
+	return t, nil
+}
+
+func parseRuleTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template %q: %v", name, text, err)
+	}
+	return t, nil
+}
+
+// render renders the problem of the rule matched in the logs. The fields
+// without template are taken from the default problem.
+func (t *ruleTemplate) render(logs []*types.Log, p problem) (problem, error) {
+	return t.renderCaptures(p, t.pattern.captures(logs))
+}
+
+func (t *ruleTemplate) renderCaptures(p problem, captures map[string]string) (problem, error) {
+	var err error
+	if t.reason != nil {
+		if p.reason, err = execute(t.reason, captures); err != nil {
+			return p, err
+		}
+	}
+	if t.message != nil {
+		if p.message, err = execute(t.message, captures); err != nil {
+			return p, err
+		}
+		p.message = appendMessageSuffix(p.message, t.suffix)
+	}
+	if len(t.labels) != 0 {
+		p.labels = make(map[string]string, len(t.labels))
+		for _, label := range t.labels {
+			p.labels[label] = captures[label]
+		}
+	}
+	return p, nil
+}
+
+func execute(t *template.Template, captures map[string]string) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, captures); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// groupNames returns the names of the named capture groups of the pattern.
+func (p *Pattern) groupNames() []string {
+	var names []string
+	for _, name := range p.regexp.SubexpNames() {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// captures returns the named capture groups of the pattern in the matched
+// logs. The groups that didn't participate in the match are empty.
+func (p *Pattern) captures(logs []*types.Log) map[string]string {
+	messages := make([]string, len(logs))
+	for i, log := range logs {
+		messages[i] = log.Message
+	}
+	captures := make(map[string]string)
+	match := p.regexp.FindStringSubmatch(concatLogs(messages))
+	for i, name := range p.regexp.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		captures[name] = ""
+		if i < len(match) {
+			captures[name] = match[i]
+		}
+	}
+	return captures
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+)
+
+const oomPattern = `Killed process (?P<pid>\d+) \((?P<process>.+)\) total-vm:\d+kB, anon-rss:(?P<anonRSS>\d+)kB, file-rss:\d+kB.*`
+
+func TestRuleTemplate(t *testing.T) {
+	logs := []*types.Log{
+		{Message: "Out of memory: Kill process 1234 (nginx) score 500"},
+		{Message: "Killed process 1234 (nginx) total-vm:4096kB, anon-rss:2048kB, file-rss:0kB, shmem-rss:0kB"},
+	}
+	for _, test := range []struct {
+		name string
+		rule types.Rule
+		want problem
+	}{
+		{
+			name: "message template",
+			rule: types.Rule{
+				Reason:          "OOMKilling",
+				Pattern:         oomPattern,
+				MessageTemplate: "Killed process {{.process}} (pid {{.pid}}), anon-rss {{.anonRSS}}kB",
+			},
+			want: problem{
+				reason:  "OOMKilling",
+				message: "Killed process nginx (pid 1234), anon-rss 2048kB",
+			},
+		},
+		{
+			name: "reason template with message suffix",
+			rule: types.Rule{
+				Reason:                        "OOMKilling",
+				Pattern:                       oomPattern,
+				ReasonTemplate:                "OOMKilling{{if eq .process \"nginx\"}}Nginx{{end}}",
+				MessageTemplate:               "Killed process {{.process}}",
+				PatternGeneratedMessageSuffix: "See the memory limits.",
+			},
+			want: problem{
+				reason:  "OOMKillingNginx",
+				message: "Killed process nginx; See the memory limits.",
+			},
+		},
+		{
+			name: "metric labels only",
+			rule: types.Rule{
+				Reason:       "OOMKilling",
+				Pattern:      oomPattern,
+				MetricLabels: []string{"process"},
+			},
+			want: problem{
+				reason:  "OOMKilling",
+				message: "Killed process 1234 (nginx) total-vm:4096kB, anon-rss:2048kB, file-rss:0kB, shmem-rss:0kB",
+				labels:  map[string]string{"process": "nginx"},
+			},
+		},
+		{
+			name: "optional capture group",
+			rule: types.Rule{
+				Reason:          "OOMKilling",
+				Pattern:         `Killed process (?P<pid>\d+)(?: \((?P<process>[^)]+)\))?.*`,
+				MessageTemplate: "Killed process [{{.process}}] {{.pid}}",
+			},
+			want: problem{
+				reason:  "OOMKilling",
+				message: "Killed process [nginx] 1234",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pattern, err := CompilePattern(test.rule.Pattern)
+			require.NoError(t, err)
+			tmpl, err := compileRuleTemplate(test.rule, pattern)
+			require.NoError(t, err)
+			matched := NewLogBuffer(10)
+			for _, log := range logs {
+				matched.Push(log)
+			}
+			got, err := tmpl.render(matched.Match(pattern), defaultProblem(logs[1:], test.rule))
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestRuleTemplateEmptyCapture(t *testing.T) {
+	rule := types.Rule{
+		Reason:          "Problem",
+		Pattern:         `problem(?: in (?P<component>\w+))?`,
+		MessageTemplate: "component: {{.component}}",
+		MetricLabels:    []string{"component"},
+	}
+	pattern, err := CompilePattern(rule.Pattern)
+	require.NoError(t, err)
+	tmpl, err := compileRuleTemplate(rule, pattern)
+	require.NoError(t, err)
+	got, err := tmpl.render([]*types.Log{{Message: "problem"}}, problem{reason: "Problem"})
+	require.NoError(t, err)
+	assert.Equal(t, problem{
+		reason:  "Problem",
+		message: "component: ",
+		labels:  map[string]string{"component": ""},
+	}, got)
+}
+
+func TestCompileRuleTemplate(t *testing.T) {
+	pattern, err := CompilePattern(oomPattern)
+	require.NoError(t, err)
+
+	tmpl, err := compileRuleTemplate(types.Rule{Reason: "OOMKilling", Pattern: oomPattern}, pattern)
+	assert.NoError(t, err)
+	assert.Nil(t, tmpl, "a rule without templates and labels has no template")
+
+	for name, rule := range map[string]types.Rule{
+		"invalid reason template":  {ReasonTemplate: "{{.process"},
+		"invalid message template": {MessageTemplate: "{{end}}"},
+		"unknown capture group":    {MessageTemplate: "Killed {{.proc}}"},
+		"unknown metric label":     {MetricLabels: []string{"proc"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rule.Pattern = oomPattern
+			_, err := compileRuleTemplate(rule, pattern)
+			assert.Error(t, err)
+		})
+	}
+
+	reasonPattern, err := CompilePattern(`problem (?P<reason>\w+)`)
+	require.NoError(t, err)
+	_, err = compileRuleTemplate(types.Rule{MetricLabels: []string{"reason"}}, reasonPattern)
+	assert.Error(t, err, "the reason label is reserved")
+}
@@ -27,24 +27,96 @@ import (
 type Log struct {
 	Timestamp time.Time
 	Message   string
+	// Fields are the structured fields of the log, e.g. the journal fields
+	// _SYSTEMD_UNIT and PRIORITY. Log watchers without structured logs leave
+	// it nil.
+	Fields map[string]string
 }
 
+// Recovery is the type of a rule whose match resets a permanent condition
+// back to its default status, reason and message.
+const Recovery types.Type = "recovery"
+
 // Rule describes how log monitor should analyze the log.
 type Rule struct {
 	// Type is the type of matched problem.
 	Type types.Type `json:"type"`
 	// Condition is the type of the condition the problem triggered. Notice that
-	// the Condition field should be set only when the problem is permanent, or
-	// else the field will be ignored.
+	// the Condition field should be set only when the problem is permanent or
+	// recovery, or else the field will be ignored.
 	Condition string `json:"condition"`
 	// Reason is the short reason of the problem.
 	Reason string `json:"reason"`
 	// Pattern is the regular expression to match the problem in log.
 	// Notice that the pattern must match to the end of the line.
 	Pattern string `json:"pattern"`
+	// Fields are the regular expressions that the structured fields of the last
+	// matched log line must match, keyed by field name. A log line without
+	// one of the fields does not match.
+	Fields map[string]string `json:"fields,omitempty"`
 	// PatternGeneratedMessageSuffix is an optional suffix appended to the matched pattern.
 	// This suffix provides additional context or instructions for resolving the issue.
 	// It can be used to include environment-specific details, links to documentation,
 	// or any other information that helps users understand and address the problem.
 	PatternGeneratedMessageSuffix string `json:"patternGeneratedMessageSuffix,omitempty"`
+	// Severity is the severity of the problem: info, warning, error or critical.
+	// It is carried in the events and conditions of the rule, and added as the
+	// severity label of the problem metrics. Events default to warning, and
+	// the metrics have no severity label when it is empty.
+	Severity string `json:"severity,omitempty"`
+	// ReasonTemplate is an optional Go template of the reason, which is used
+	// instead of Reason when the rule matches. The named capture groups of
+	// Pattern are available as its data, e.g. {{.process}}.
+	ReasonTemplate string `json:"reasonTemplate,omitempty"`
+	// MessageTemplate is an optional Go template of the message, which is used
+	// instead of the matched log lines. Its data is the same as ReasonTemplate.
+	MessageTemplate string `json:"messageTemplate,omitempty"`
+	// MetricLabels are the named capture groups of Pattern added as labels to
+	// the problem_label_counter metric. The labels are declared when node
+	// problem detector starts, so labels added by a reload are not counted.
+	MetricLabels []string `json:"metricLabels,omitempty"`
+	// ClearAfter is the duration string after which a permanent condition set by
+	// this rule is reset to its default, if the rule has not matched again since.
+	// Empty means the condition never expires.
+	ClearAfter string `json:"clearAfter,omitempty"`
+	// Count is the number of times the pattern must match within Window for
+	// the rule to fire. Zero means the rule fires on every match.
+	Count int `json:"count,omitempty"`
+	// Window is the duration string of the sliding window Count is counted in.
+	Window string `json:"window,omitempty"`
+	// RevertPattern is the regular expression whose match takes back the most
+	// recent counted match, e.g. a service stopping after starting. It is only
+	// supported with Count.
+	RevertPattern string `json:"revertPattern,omitempty"`
+	// Cooldown is the duration string of the window in which at most
+	// MaxEventsPerWindow events of a temporary rule are emitted. The events
+	// suppressed in a window are rolled up into a summary event once the
+	// window elapses, and still counted by the problem_counter metric. Empty
+	// means every event is emitted.
+	Cooldown string `json:"cooldown,omitempty"`
+	// MaxEventsPerWindow is the maximum number of events emitted per Cooldown
+	// window, 1 by default. It is only supported with Cooldown.
+	MaxEventsPerWindow int `json:"maxEventsPerWindow,omitempty"`
+}
+
+// Block describes how consecutive log lines, e.g. a kernel oops or a stack
+// trace, are assembled into a single log before the rules are matched.
+type Block struct {
+	// Start is the regular expression matching the first line of the block.
+	Start string `json:"start"`
+	// End is the regular expression matching the last line of the block.
+	// Exactly one of End and Continuation must be set.
+	End string `json:"end,omitempty"`
+	// Continuation is the regular expression matching every line of the block
+	// after the first one. The block ends before the first line not matching it.
+	Continuation string `json:"continuation,omitempty"`
+	// MaxLines is the maximum number of lines in the block. The block ends
+	// once it is reached.
+	MaxLines int `json:"maxLines,omitempty"`
+	// MaxBytes is the maximum size of the block message in bytes. The lines
+	// beyond it are dropped from the block.
+	MaxBytes int `json:"maxBytes,omitempty"`
+	// Timeout is the duration string after which a block without new lines
+	// ends.
+	Timeout string `json:"timeout,omitempty"`
 }
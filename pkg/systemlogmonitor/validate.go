@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/node-problem-detector/pkg/systemlogmonitor/logwatchers"
+	systemlogtypes "k8s.io/node-problem-detector/pkg/systemlogmonitor/types"
+	"k8s.io/node-problem-detector/pkg/types"
+	"k8s.io/node-problem-detector/pkg/util"
+)
+
+// ValidateConfig validates the log monitor configuration file without starting
+// the log watcher. Unlike NewLogMonitor, it reports every invalid value rather
+// than the first one, and also reports rules which are valid but can never
+// behave as intended. The errors are util.ConfigErrors at the JSON path of the
+// invalid value.
+func ValidateConfig(configPath string) []error {
+	f, err := os.ReadFile(configPath)
+	if err != nil {
+		return []error{err}
+	}
+	var config MonitorConfig
+	if err := util.UnmarshalConfig(f, &config); err != nil {
+		return []error{err}
+	}
+	config.ApplyDefaultConfiguration()
+
+	errs := logwatchers.ValidateConfig(config.WatcherConfig)
+	if config.BufferSize < 0 {
+		errs = append(errs, util.NewConfigError("$.bufferSize", fmt.Errorf("bufferSize must not be negative: %d", config.BufferSize)))
+	}
+	for i, condition := range config.DefaultConditions {
+		for j := range i {
+			if config.DefaultConditions[j].Type == condition.Type {
+				errs = append(errs, util.NewConfigError(fmt.Sprintf("$.conditions[%d].type", i),
+					fmt.Errorf("condition %q is also configured by conditions[%d]", condition.Type, j)))
+				break
+			}
+		}
+	}
+	for i, block := range config.Blocks {
+		if _, err := compileBlock(block); err != nil {
+			errs = append(errs, util.NewConfigError(fmt.Sprintf("$.blocks[%d]", i), err))
+		}
+	}
+	for i, rule := range config.Rules {
+		errs = append(errs, config.validateRuleConfig(i, rule)...)
+	}
+	return errs
+}
+
+// validateRuleConfig validates the i-th rule of the configuration.
+func (mc MonitorConfig) validateRuleConfig(i int, rule systemlogtypes.Rule) []error {
+	path := fmt.Sprintf("$.rules[%d]", i)
+	var errs []error
+	add := func(path string, err error) {
+		errs = append(errs, util.NewConfigError(path, err))
+	}
+	pattern, err := CompilePattern(rule.Pattern)
+	if err != nil {
+		add(path+".pattern", err)
+	} else if lines, err := minLines(rule.Pattern); err == nil && lines > mc.BufferSize {
+		add(path+".pattern", fmt.Errorf("pattern matches at least %d lines, more than bufferSize %d", lines, mc.BufferSize))
+	}
+	if _, err := compileFields(rule.Fields); err != nil {
+		add(path+".fields", err)
+	}
+	if pattern != nil {
+		if _, err := compileRuleTemplate(rule, pattern); err != nil {
+			add(path, err)
+		}
+	}
+	if err := mc.validateRule(rule); err != nil {
+		add(path, err)
+	}
+	if _, err := parseRuleMatchWindow(rule); err != nil {
+		add(path, err)
+	}
+	if _, err := parseRuleClearAfter(rule); err != nil {
+		add(path, err)
+	}
+	if _, err := parseRuleLimiter(rule); err != nil {
+		add(path, err)
+	}
+	if rule.Type == types.Perm && rule.ClearAfter == "" && !mc.hasDefaultCondition(rule.Condition) {
+		add(path+".condition", fmt.Errorf("permanent problem %s does not have preset default condition, the rule never updates a condition", rule.Condition))
+	}
+	for j, other := range mc.Rules[:i] {
+		if other.Reason != rule.Reason {
+			continue
+		}
+		switch {
+		case other.Type == rule.Type && other.Condition == rule.Condition && other.Pattern == rule.Pattern:
+			add(path, fmt.Errorf("rule duplicates rules[%d]", j))
+		case other.Type == types.Perm && rule.Type == types.Perm && other.Condition != rule.Condition:
+			add(path+".reason", fmt.Errorf("reason %q is also the reason of condition %s in rules[%d]", rule.Reason, other.Condition, j))
+		default:
+			continue
+		}
+		break
+	}
+	return errs
+}
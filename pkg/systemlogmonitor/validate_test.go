@@ -0,0 +1,145 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package systemlogmonitor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/node-problem-detector/pkg/util"
+)
+
+func TestValidateConfig(t *testing.T) {
+	for desc, test := range map[string]struct {
+		config   string
+		expected []string
+	}{
+		"valid config": {
+			config: replayTestConfig,
+		},
+		"syntax error": {
+			config:   "{\n  \"plugin\": \"kmsg\",\n}",
+			expected: []string{"$"},
+		},
+		"wrong type": {
+			config:   `{"plugin": "kmsg", "rules": [{"type": "temporary", "reason": "R", "pattern": "p", "count": "2"}]}`,
+			expected: []string{"$.rules[0].count"},
+		},
+		"every invalid value": {
+			config: `{
+  "plugin": "unknown",
+  "lookback": "1x",
+  "conditions": [
+    {"type": "A", "reason": "NoA"},
+    {"type": "A", "reason": "NoA"}
+  ],
+  "blocks": [{"start": "("}],
+  "rules": [
+    {"type": "temporary", "reason": "R1", "pattern": "("},
+    {"type": "temporary", "reason": "R2", "pattern": "p", "severity": "fatal"},
+    {"type": "permanent", "condition": "A", "reason": "R3", "pattern": "p", "count": 2, "window": "-1m"},
+    {"type": "permanent", "condition": "A", "reason": "R4", "pattern": "p", "clearAfter": "x"},
+    {"type": "temporary", "reason": "R5", "pattern": "p", "fields": {"PRIORITY": "("}},
+    {"type": "permanent", "condition": "A", "reason": "R6", "pattern": "p", "cooldown": "5m"}
+  ]
+}`,
+			expected: []string{
+				"$.plugin",
+				"$.lookback",
+				"$.conditions[1].type",
+				"$.blocks[0]",
+				"$.rules[0].pattern",
+				"$.rules[1]",
+				"$.rules[2]",
+				"$.rules[3]",
+				"$.rules[4].fields",
+				"$.rules[5]",
+			},
+		},
+		"semantic problems": {
+			config: `{
+  "plugin": "kmsg",
+  "bufferSize": 2,
+  "conditions": [
+    {"type": "A", "reason": "NoA"},
+    {"type": "B", "reason": "NoB"}
+  ],
+  "rules": [
+    {"type": "permanent", "condition": "C", "reason": "R1", "pattern": "p"},
+    {"type": "permanent", "condition": "A", "reason": "R2", "pattern": "p"},
+    {"type": "permanent", "condition": "B", "reason": "R2", "pattern": "q"},
+    {"type": "temporary", "reason": "R3", "pattern": "p"},
+    {"type": "temporary", "reason": "R3", "pattern": "p"},
+    {"type": "temporary", "reason": "R3", "pattern": "q"},
+    {"type": "temporary", "reason": "R4", "pattern": "a\\nb\\nc"}
+  ]
+}`,
+			expected: []string{
+				"$.rules[0].condition",
+				"$.rules[2].reason",
+				"$.rules[4]",
+				"$.rules[6].pattern",
+			},
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, os.WriteFile(configPath, []byte(test.config), 0644))
+			var paths []string
+			for _, err := range ValidateConfig(configPath) {
+				var configErr *util.ConfigError
+				require.True(t, errors.As(err, &configErr), "unexpected error %v", err)
+				paths = append(paths, configErr.Path)
+			}
+			assert.Equal(t, test.expected, paths)
+		})
+	}
+}
+
+func TestValidateShippedConfigs(t *testing.T) {
+	for _, config := range []string{
+		"disk-log-message-filelog.json",
+		"docker-monitor-filelog.json",
+		"kernel-monitor-filelog.json",
+		"kernel-monitor.json",
+		"readonly-monitor.json",
+		"windows-containerd-monitor-filelog.json",
+	} {
+		t.Run(config, func(t *testing.T) {
+			assert.Empty(t, ValidateConfig(filepath.Join("..", "..", "config", config)))
+		})
+	}
+}
+
+func TestNewLogMonitorInvalidWatcherConfig(t *testing.T) {
+	// A reloaded configuration with an invalid plugin configuration returns an
+	// error, so that the old log monitor keeps running.
+	configPath := filepath.Join(t.TempDir(), "syslog-monitor.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+  "plugin": "syslog",
+  "pluginConfig": {"network": "sctp", "address": ":514"},
+  "source": "syslog-monitor",
+  "rules": []
+}`), 0644))
+	_, err := NewLogMonitor(configPath)
+	assert.ErrorContains(t, err, `unknown network "sctp"`)
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/node-problem-detector/pkg/problemdaemon"
 	ssmtypes "k8s.io/node-problem-detector/pkg/systemstatsmonitor/types"
 	"k8s.io/node-problem-detector/pkg/types"
+	"k8s.io/node-problem-detector/pkg/util"
 	"k8s.io/node-problem-detector/pkg/util/tomb"
 )
 
@@ -35,6 +36,7 @@ const SystemStatsMonitorName = "system-stats-monitor"
 func init() {
 	problemdaemon.Register(SystemStatsMonitorName, types.ProblemDaemonHandler{
 		CreateProblemDaemonOrDie: NewSystemStatsMonitorOrDie,
+		ValidateConfig:           ValidateConfig,
 		CmdOptionDescription:     "Set to config file paths.",
 	})
 }
@@ -105,6 +107,26 @@ func NewSystemStatsMonitorOrDie(configPath string) types.Monitor {
 	return &ssm
 }
 
+// ValidateConfig validates the system stats monitor configuration file without
+// creating the collectors. The errors are util.ConfigErrors.
+func ValidateConfig(configPath string) []error {
+	f, err := os.ReadFile(configPath)
+	if err != nil {
+		return []error{err}
+	}
+	var config ssmtypes.SystemStatsConfig
+	if err := util.UnmarshalConfig(f, &config); err != nil {
+		return []error{err}
+	}
+	if err := config.ApplyConfiguration(); err != nil {
+		return []error{util.NewConfigError("$", err)}
+	}
+	if err := config.Validate(); err != nil {
+		return []error{util.NewConfigError("$", err)}
+	}
+	return nil
+}
+
 func (ssm *systemStatsMonitor) Start() (<-chan *types.Status, error) {
 	klog.Infof("Start system stats monitor %s", ssm.configPath)
 	go ssm.monitorLoop()
@@ -17,6 +17,7 @@ limitations under the License.
 package types
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -28,9 +29,8 @@ import (
 // 1) The kubernetes api packages are too heavy.
 // 2) We want to make the interface independent with kubernetes api change.
 
-// Severity is the severity of the problem event. Now we only have 2 severity levels: Info and Warn,
-// which are corresponding to the current kubernetes event types. We may want to add more severity
-// levels in the future.
+// Severity is the severity of the problem event. Info is translated to a normal Kubernetes event,
+// and the other severities to a warning event.
 type Severity string
 
 const (
@@ -38,8 +38,25 @@ const (
 	Info Severity = "info"
 	// Warn is translated to a warning event.
 	Warn Severity = "warn"
+	// Error is translated to a warning event.
+	Error Severity = "error"
+	// Critical is translated to a warning event.
+	Critical Severity = "critical"
 )
 
+// ParseSeverity parses a configured severity. "warning" is accepted for Warn,
+// and an empty string is parsed to an empty severity.
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case "", Info, Warn, Error, Critical:
+		return Severity(s), nil
+	case "warning":
+		return Warn, nil
+	default:
+		return "", fmt.Errorf("unknown severity %q, expected one of info, warning, error or critical", s)
+	}
+}
+
 // ConditionStatus is the status of the condition.
 type ConditionStatus string
 
@@ -65,6 +82,9 @@ type Condition struct {
 	Reason string `json:"reason"`
 	// Message is a human-readable message of why node goes into this condition.
 	Message string `json:"message"`
+	// Severity is the severity of the problem while the condition is true. It is
+	// empty when the rule that set the condition has no severity.
+	Severity Severity `json:"severity,omitempty"`
 }
 
 // Event is the event used internally by node problem detector.
@@ -111,6 +131,19 @@ type Monitor interface {
 	Stop()
 }
 
+// ConditionMonitor is a Monitor whose node conditions can be carried over to
+// another instance of it, e.g. when its configuration is reloaded.
+type ConditionMonitor interface {
+	Monitor
+	// GetConditions returns a copy of the current node conditions. It should
+	// only be called after the monitor is stopped.
+	GetConditions() []Condition
+	// RestoreConditions sets the node conditions the monitor starts with.
+	// Conditions of types that the monitor does not handle are ignored. It
+	// should be called before the monitor is started.
+	RestoreConditions([]Condition)
+}
+
 // Exporter exports machine health data to certain control plane.
 type Exporter interface {
 	// ExportProblems Export problems to the control plane.
@@ -130,6 +163,17 @@ type ProblemDaemonConfigPathMap map[ProblemDaemonType]*[]string
 type ProblemDaemonHandler struct {
 	// CreateProblemDaemonOrDie initializes a problem daemon, panic if error occurs.
 	CreateProblemDaemonOrDie func(string) Monitor
+	// CreateProblemDaemon initializes a problem daemon, returns error if the
+	// configuration is invalid. It is optional, and only problem daemons that
+	// set it are recreated when their configuration changes.
+	CreateProblemDaemon func(string) (Monitor, error)
+	// ValidateConfig validates a configuration file of the problem daemon
+	// without starting it, and returns every error found. It is optional.
+	ValidateConfig func(string) []error
+	// ProblemMetricLabels returns the extra labels the problems of a
+	// configuration file of the problem daemon are counted with. It is
+	// optional.
+	ProblemMetricLabels func(string) []string
 	// CmdOptionDescription explains how to configure the problem daemon from command line arguments.
 	CmdOptionDescription string
 }
@@ -141,6 +185,9 @@ type ExporterType string
 type ExporterHandler struct {
 	// CreateExporterOrDie initializes an exporter, panic if error occurs.
 	CreateExporterOrDie func(CommandLineOptions) Exporter
+	// ValidateConfig validates the configuration of the exporter without
+	// creating it, and returns every error found. It is optional.
+	ValidateConfig func(CommandLineOptions) []error
 	// CmdOptionDescription explains how to configure the exporter from command line arguments.
 	Options CommandLineOptions
 }
@@ -0,0 +1,92 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConfigError is an error of the value at a JSON path of a configuration file.
+type ConfigError struct {
+	// Path is the JSON path of the invalid value, e.g. "$.rules[2].pattern".
+	Path string
+	Err  error
+}
+
+// NewConfigError returns the error of the value at the JSON path.
+func NewConfigError(path string, err error) *ConfigError {
+	return &ConfigError{Path: path, Err: err}
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalConfig unmarshals the JSON configuration. The error is a
+// ConfigError at the path of the value of a wrong type, or at the root with
+// the line and column of a syntax error.
+func UnmarshalConfig(data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, column := position(data, syntaxErr.Offset)
+		return NewConfigError("$", fmt.Errorf("line %d, column %d: %v", line, column, err))
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return NewConfigError(jsonPath(typeErr.Field), fmt.Errorf("cannot unmarshal %s into %v", typeErr.Value, typeErr.Type))
+	case err != nil:
+		return NewConfigError("$", err)
+	}
+	return nil
+}
+
+// position returns the line and column, both starting at 1, of the byte
+// before the offset, which is where the JSON decoder stopped.
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:max(offset-1, 0)]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// jsonPath converts the dotted field of a json.UnmarshalTypeError, e.g.
+// "rules.2.count", to a JSON path, e.g. "$.rules[2].count".
+func jsonPath(field string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, name := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(name); err == nil {
+			fmt.Fprintf(&b, "[%s]", name)
+		} else {
+			b.WriteString("." + name)
+		}
+	}
+	return b.String()
+}
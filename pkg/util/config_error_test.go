@@ -0,0 +1,58 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnmarshalConfig(t *testing.T) {
+	type rule struct {
+		Count int `json:"count"`
+	}
+	type config struct {
+		Rules []rule `json:"rules"`
+	}
+	for desc, test := range map[string]struct {
+		data     string
+		expected string
+	}{
+		"valid":          {data: `{"rules": [{"count": 1}]}`},
+		"syntax error":   {data: "{\n  \"rules\": [],\n}", expected: "$: line 3, column 1: invalid character '}' looking for beginning of object key string"},
+		"type error":     {data: `{"rules": [{"count": 1}, {"count": "2"}]}`, expected: "$.rules[1].count: cannot unmarshal string into int"},
+		"root type":      {data: `[]`, expected: "$: json: cannot unmarshal array into Go value of type util.config"},
+		"unexpected end": {data: `{"rules": [`, expected: "$: line 1, column 11: unexpected end of JSON input"},
+	} {
+		var c config
+		err := UnmarshalConfig([]byte(test.data), &c)
+		if test.expected == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", desc, err)
+			}
+			continue
+		}
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			t.Errorf("%s: expected a ConfigError, got %v", desc, err)
+			continue
+		}
+		if err.Error() != test.expected {
+			t.Errorf("%s: expected error %q, got %q", desc, test.expected, err.Error())
+		}
+	}
+}
@@ -55,7 +55,7 @@ func ConvertToAPIEventType(severity types.Severity) string {
 	switch severity {
 	case types.Info:
 		return v1.EventTypeNormal
-	case types.Warn:
+	case types.Warn, types.Error, types.Critical:
 		return v1.EventTypeWarning
 	default:
 		// Should never get here, just in case
@@ -47,3 +47,17 @@ func TestConvertToAPICondition(t *testing.T) {
 		t.Errorf("expected %+v, got %+v", expected, apiCondition)
 	}
 }
+
+func TestConvertToAPIEventType(t *testing.T) {
+	for severity, expected := range map[types.Severity]string{
+		types.Info:     v1.EventTypeNormal,
+		types.Warn:     v1.EventTypeWarning,
+		types.Error:    v1.EventTypeWarning,
+		types.Critical: v1.EventTypeWarning,
+		"":             v1.EventTypeNormal,
+	} {
+		if got := ConvertToAPIEventType(severity); got != expected {
+			t.Errorf("severity %q: expected %q, got %q", severity, expected, got)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+// EventLimiter limits the events of a rule to a maximum number per cooldown
+// window. The events suppressed in a window are rolled up into a summary event
+// once the window elapses. The window starts at the first event after the
+// previous window elapsed. The windows are opened by Allow and closed by
+// Summary at the time of the same clock, not at the timestamps of the events.
+type EventLimiter struct {
+	cooldown  time.Duration
+	maxEvents int
+	// start is the start of the current window, zero before the first event.
+	start time.Time
+	// emitted is the number of events emitted in the current window.
+	emitted int
+	// suppressed is the number of events suppressed since the last summary.
+	suppressed int
+	// last is the last suppressed event.
+	last types.Event
+}
+
+// NewEventLimiter creates an event limiter which emits at most maxEvents
+// events per cooldown window.
+func NewEventLimiter(cooldown time.Duration, maxEvents int) *EventLimiter {
+	return &EventLimiter{cooldown: cooldown, maxEvents: maxEvents}
+}
+
+// Allow reports whether the event is emitted at now. A suppressed event is
+// rolled up into the summary of its window.
+func (l *EventLimiter) Allow(event types.Event, now time.Time) bool {
+	if l.start.IsZero() || !now.Before(l.start.Add(l.cooldown)) {
+		l.start = now
+		l.emitted = 0
+	}
+	if l.emitted < l.maxEvents {
+		l.emitted++
+		return true
+	}
+	l.suppressed++
+	l.last = event
+	return false
+}
+
+// Deadline returns the time at which the summary of the suppressed events is
+// due, false if no event is suppressed.
+func (l *EventLimiter) Deadline() (time.Time, bool) {
+	if l.suppressed == 0 {
+		return time.Time{}, false
+	}
+	return l.start.Add(l.cooldown), true
+}
+
+// Summary returns the summary event of the suppressed events if their window
+// elapsed at now, false otherwise. The summary is the last suppressed event at
+// now, with the number of the other suppressed events added to its message,
+// e.g. "... (and 57 more in the last 5m0s)".
+func (l *EventLimiter) Summary(now time.Time) (types.Event, bool) {
+	deadline, ok := l.Deadline()
+	if !ok || now.Before(deadline) {
+		return types.Event{}, false
+	}
+	summary := l.last
+	summary.Timestamp = now
+	if l.suppressed > 1 {
+		summary.Message = fmt.Sprintf("%s (and %d more in the last %v)", l.last.Message, l.suppressed-1, l.cooldown)
+	}
+	l.suppressed = 0
+	l.last = types.Event{}
+	return summary, true
+}
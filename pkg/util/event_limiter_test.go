@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/node-problem-detector/pkg/types"
+)
+
+func TestEventLimiter(t *testing.T) {
+	start := time.Unix(1000, 0)
+	event := func(offset time.Duration, message string) types.Event {
+		return types.Event{Reason: "OOMKilling", Timestamp: start.Add(offset), Message: message}
+	}
+	l := NewEventLimiter(5*time.Minute, 2)
+
+	if _, ok := l.Deadline(); ok {
+		t.Errorf("expected no deadline before any event is suppressed")
+	}
+	for i, test := range []struct {
+		event   types.Event
+		allowed bool
+	}{
+		{event: event(0, "first"), allowed: true},
+		{event: event(time.Minute, "second"), allowed: true},
+		{event: event(2*time.Minute, "third"), allowed: false},
+		{event: event(3*time.Minute, "fourth"), allowed: false},
+	} {
+		if got := l.Allow(test.event, test.event.Timestamp); got != test.allowed {
+			t.Errorf("event %d: expected allowed %v, got %v", i, test.allowed, got)
+		}
+	}
+
+	deadline, ok := l.Deadline()
+	if !ok || !deadline.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("expected deadline %v, got %v, %v", start.Add(5*time.Minute), deadline, ok)
+	}
+	if _, ok := l.Summary(start.Add(4 * time.Minute)); ok {
+		t.Errorf("expected no summary before the window elapses")
+	}
+	summary, ok := l.Summary(start.Add(5 * time.Minute))
+	expected := types.Event{
+		Reason:    "OOMKilling",
+		Timestamp: start.Add(5 * time.Minute),
+		Message:   "fourth (and 1 more in the last 5m0s)",
+	}
+	if !ok || summary != expected {
+		t.Errorf("expected summary %+v, got %+v, %v", expected, summary, ok)
+	}
+	if _, ok := l.Summary(start.Add(6 * time.Minute)); ok {
+		t.Errorf("expected the summary to be reported once")
+	}
+
+	// A new window starts with the first event after the previous one elapsed.
+	if !l.Allow(event(6*time.Minute, "fifth"), start.Add(6*time.Minute)) || !l.Allow(event(7*time.Minute, "sixth"), start.Add(7*time.Minute)) {
+		t.Errorf("expected the events of a new window to be allowed")
+	}
+	if l.Allow(event(8*time.Minute, "seventh"), start.Add(8*time.Minute)) {
+		t.Errorf("expected the third event of the window to be suppressed")
+	}
+	summary, ok = l.Summary(start.Add(11 * time.Minute))
+	if !ok || summary.Message != "seventh" {
+		t.Errorf("expected a single suppressed event to be reported as is, got %+v, %v", summary, ok)
+	}
+}
+
+func TestEventLimiterWindowFollowsNow(t *testing.T) {
+	now := time.Unix(5000, 0)
+	l := NewEventLimiter(5*time.Minute, 1)
+
+	// Events with old timestamps, e.g. read from the lookback, still share the
+	// window opened now.
+	if !l.Allow(types.Event{Timestamp: time.Unix(1000, 0), Message: "first"}, now) {
+		t.Errorf("expected the first event to be allowed")
+	}
+	if l.Allow(types.Event{Timestamp: time.Unix(2000, 0), Message: "second"}, now) {
+		t.Errorf("expected the second event to be suppressed")
+	}
+	if _, ok := l.Summary(now); ok {
+		t.Errorf("expected no summary before the window opened now elapses")
+	}
+	if summary, ok := l.Summary(now.Add(5 * time.Minute)); !ok || summary.Message != "second" {
+		t.Errorf("expected the summary of the second event, got %+v, %v", summary, ok)
+	}
+}
@@ -43,3 +43,11 @@ func Kill(cmd *exec.Cmd) error {
 	}
 	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
 }
+
+// Terminate asks the process and subprocesses to exit.
+func Terminate(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return fmt.Errorf("%v does not have a process handle", cmd)
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
@@ -87,3 +87,9 @@ func Kill(cmd *exec.Cmd) error {
 	}
 	return err
 }
+
+// Terminate asks the process and subprocesses to exit. Console processes
+// cannot be signaled on Windows, so they are killed.
+func Terminate(cmd *exec.Cmd) error {
+	return Kill(cmd)
+}
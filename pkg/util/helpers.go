@@ -36,6 +36,20 @@ func GenerateConditionChangeEvent(t string, status types.ConditionStatus, reason
 	}
 }
 
+// CarryOverConditions replaces each condition with the previous condition of the
+// same type, if there is one, and returns the conditions.
+func CarryOverConditions(conditions, previous []types.Condition) []types.Condition {
+	for i := range conditions {
+		for _, p := range previous {
+			if p.Type == conditions[i].Type {
+				conditions[i] = p
+				break
+			}
+		}
+	}
+	return conditions
+}
+
 func GetStartTime(now time.Time, uptimeDuration time.Duration, lookbackStr string, delayStr string) (time.Time, error) {
 	startTime := now.Add(-uptimeDuration)
 
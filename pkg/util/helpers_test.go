@@ -17,10 +17,31 @@ limitations under the License.
 package util
 
 import (
+	"reflect"
 	"testing"
 	"time"
+
+	"k8s.io/node-problem-detector/pkg/types"
 )
 
+func TestCarryOverConditions(t *testing.T) {
+	conditions := []types.Condition{
+		{Type: "A", Status: types.False, Reason: "DefaultA"},
+		{Type: "B", Status: types.False, Reason: "DefaultB"},
+	}
+	previous := []types.Condition{
+		{Type: "B", Status: types.True, Transition: time.Unix(100, 0), Reason: "ProblemB", Message: "b"},
+		{Type: "C", Status: types.True, Reason: "ProblemC"},
+	}
+	expected := []types.Condition{
+		{Type: "A", Status: types.False, Reason: "DefaultA"},
+		{Type: "B", Status: types.True, Transition: time.Unix(100, 0), Reason: "ProblemB", Message: "b"},
+	}
+	if got := CarryOverConditions(conditions, previous); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected conditions %+v, got %+v", expected, got)
+	}
+}
+
 func TestGetStartTime(t *testing.T) {
 	now := time.Now()
 	testCases := []struct {
@@ -27,6 +27,12 @@ type Int64MetricInterface interface {
 	Record(tags map[string]string, measurement int64) error
 }
 
+// Float64MetricInterface is used to create test double for Float64Metric.
+type Float64MetricInterface interface {
+	// Record records a measurement for the metric, with provided tags as metric labels.
+	Record(tags map[string]string, measurement float64) error
+}
+
 // FakeInt64Metric implements Int64MetricInterface.
 // FakeInt64Metric can be used as a test double for Int64MetricInterface, allowing
 // inspection of the metrics.
@@ -95,3 +101,65 @@ func (fake *FakeInt64Metric) Record(tags map[string]string, measurement int64) e
 func (fake *FakeInt64Metric) ListMetrics() []Int64MetricRepresentation {
 	return fake.metrics
 }
+
+// FakeFloat64Metric implements Float64MetricInterface.
+// FakeFloat64Metric can be used as a test double for Float64MetricInterface, allowing
+// inspection of the metrics. The value of a Distribution metric is the sum of its
+// measurements.
+type FakeFloat64Metric struct {
+	name        string
+	aggregation Aggregation
+	allowedTags map[string]bool
+	metrics     []Float64MetricRepresentation
+}
+
+func NewFakeFloat64Metric(name string, aggregation Aggregation, tagNames []string) *FakeFloat64Metric {
+	if name == "" {
+		return nil
+	}
+
+	allowedTags := make(map[string]bool)
+	for _, tagName := range tagNames {
+		allowedTags[tagName] = true
+	}
+
+	fake := FakeFloat64Metric{name, aggregation, allowedTags, []Float64MetricRepresentation{}}
+	return &fake
+}
+
+func (fake *FakeFloat64Metric) Record(tags map[string]string, measurement float64) error {
+	labels := make(map[string]string)
+	for tagName, tagValue := range tags {
+		if _, ok := fake.allowedTags[tagName]; !ok {
+			return fmt.Errorf("tag %q is not allowed", tagName)
+		}
+		labels[tagName] = tagValue
+	}
+
+	metricIndex := -1
+	for index, existingMetric := range fake.metrics {
+		if reflect.DeepEqual(existingMetric.Labels, labels) {
+			metricIndex = index
+			break
+		}
+	}
+	if metricIndex == -1 {
+		fake.metrics = append(fake.metrics, Float64MetricRepresentation{Name: fake.name, Labels: labels})
+		metricIndex = len(fake.metrics) - 1
+	}
+
+	switch fake.aggregation {
+	case LastValue:
+		fake.metrics[metricIndex].Value = measurement
+	case Sum, Distribution:
+		fake.metrics[metricIndex].Value += measurement
+	default:
+		return errors.New("unsupported aggregation type")
+	}
+	return nil
+}
+
+// ListMetrics returns a snapshot of the current metrics.
+func (fake *FakeFloat64Metric) ListMetrics() []Float64MetricRepresentation {
+	return fake.metrics
+}
@@ -243,3 +243,48 @@ func TestFakeInt64Metric(t *testing.T) {
 		})
 	}
 }
+
+func TestFakeFloat64Metric(t *testing.T) {
+	testCases := []struct {
+		name            string
+		aggregation     Aggregation
+		measurements    []float64
+		expectedMetrics []Float64MetricRepresentation
+	}{
+		{
+			name:         "last value metric",
+			aggregation:  LastValue,
+			measurements: []float64{0.5, 0.25},
+			expectedMetrics: []Float64MetricRepresentation{
+				{Name: "foo", Labels: map[string]string{"bar": "baz"}, Value: 0.25},
+			},
+		},
+		{
+			name:         "sum metric",
+			aggregation:  Sum,
+			measurements: []float64{0.5, 0.25},
+			expectedMetrics: []Float64MetricRepresentation{
+				{Name: "foo", Labels: map[string]string{"bar": "baz"}, Value: 0.75},
+			},
+		},
+		{
+			name:         "distribution metric",
+			aggregation:  Distribution,
+			measurements: []float64{0.5, 0.25},
+			expectedMetrics: []Float64MetricRepresentation{
+				{Name: "foo", Labels: map[string]string{"bar": "baz"}, Value: 0.75},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			metric := NewFakeFloat64Metric("foo", test.aggregation, []string{"bar"})
+			for _, measurement := range test.measurements {
+				assert.NoError(t, metric.Record(map[string]string{"bar": "baz"}, measurement))
+			}
+			assert.Error(t, metric.Record(map[string]string{"unknown": "tag"}, 1))
+			assert.Equal(t, test.expectedMetrics, metric.ListMetrics())
+		})
+	}
+}
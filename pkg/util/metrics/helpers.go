@@ -45,8 +45,13 @@ const (
 	LastValue Aggregation = "LastValue"
 	// Sum means last measurement will be added onto previous measurements (counter metric).
 	Sum Aggregation = "Sum"
+	// Distribution means measurements are counted into the buckets of DurationBounds (histogram metric).
+	Distribution Aggregation = "Distribution"
 )
 
+// DurationBounds are the bucket boundaries of Distribution metrics, in seconds.
+var DurationBounds = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60}
+
 func getTagKeysFromNames(tagNames []string) ([]tag.Key, error) {
 	tagMapMutex.Lock()
 	defer tagMapMutex.Unlock()
@@ -68,6 +73,9 @@ func getTagKeysFromNames(tagNames []string) ([]tag.Key, error) {
 }
 
 // ParsePrometheusMetrics parses Prometheus formatted metrics into metrics under Float64MetricRepresentation.
+// Histograms and summaries are flattened into the series of their text format: the
+// <name>_bucket series with the "le" label or the <name> series with the "quantile"
+// label, and the <name>_sum and <name>_count series.
 //
 // Note: Prometheus's go library stores all counter/gauge-typed metric values under float64.
 func ParsePrometheusMetrics(metricsText string) ([]Float64MetricRepresentation, error) {
@@ -81,30 +89,67 @@ func ParsePrometheusMetrics(metricsText string) ([]Float64MetricRepresentation,
 	}
 
 	for _, metricFamily := range metricFamilies {
+		name := *metricFamily.Name
 		for _, metric := range metricFamily.Metric {
 			labels := make(map[string]string)
 			for _, labelPair := range metric.Label {
 				labels[*labelPair.Name] = *labelPair.Value
 			}
 
-			var value float64
 			switch *metricFamily.Type {
 			case pcm.MetricType_COUNTER:
-				value = *metric.Counter.Value
+				metrics = append(metrics, Float64MetricRepresentation{name, labels, *metric.Counter.Value})
 			case pcm.MetricType_GAUGE:
-				value = *metric.Gauge.Value
+				metrics = append(metrics, Float64MetricRepresentation{name, labels, *metric.Gauge.Value})
+			case pcm.MetricType_UNTYPED:
+				metrics = append(metrics, Float64MetricRepresentation{name, labels, *metric.Untyped.Value})
+			case pcm.MetricType_HISTOGRAM:
+				histogram := metric.Histogram
+				for _, bucket := range histogram.Bucket {
+					count := float64(bucket.GetCumulativeCount())
+					if bucket.CumulativeCountFloat != nil {
+						count = bucket.GetCumulativeCountFloat()
+					}
+					metrics = append(metrics, Float64MetricRepresentation{name + "_bucket",
+						withLabel(labels, model.BucketLabel, bucket.GetUpperBound()), count})
+				}
+				count := float64(histogram.GetSampleCount())
+				if histogram.SampleCountFloat != nil {
+					count = histogram.GetSampleCountFloat()
+				}
+				metrics = append(metrics,
+					Float64MetricRepresentation{name + "_sum", labels, histogram.GetSampleSum()},
+					Float64MetricRepresentation{name + "_count", labels, count})
+			case pcm.MetricType_SUMMARY:
+				summary := metric.Summary
+				for _, quantile := range summary.Quantile {
+					metrics = append(metrics, Float64MetricRepresentation{name,
+						withLabel(labels, model.QuantileLabel, quantile.GetQuantile()), quantile.GetValue()})
+				}
+				metrics = append(metrics,
+					Float64MetricRepresentation{name + "_sum", labels, summary.GetSampleSum()},
+					Float64MetricRepresentation{name + "_count", labels, float64(summary.GetSampleCount())})
 			default:
 				return metrics, fmt.Errorf("unexpected MetricType %s for metric %s",
-					pcm.MetricType_name[int32(*metricFamily.Type)], *metricFamily.Name)
+					pcm.MetricType_name[int32(*metricFamily.Type)], name)
 			}
-
-			metrics = append(metrics, Float64MetricRepresentation{*metricFamily.Name, labels, value})
 		}
 	}
 
 	return metrics, nil
 }
 
+// withLabel returns a copy of the labels with the label set to the value, formatted
+// as in the Prometheus text format.
+func withLabel(labels map[string]string, name string, value float64) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = model.FloatString(value).String()
+	return result
+}
+
 // GetFloat64Metric finds the metric matching provided name and labels.
 // When strictLabelMatching is set to true, the founded metric labels are identical to the provided labels;
 // when strictLabelMatching is set to false, the founded metric labels are a superset of the provided labels.
@@ -51,8 +51,51 @@ func TestPrometheusMetricsParsingAndMatching(t *testing.T) {
 					Name:   "disk_avg_queue_len",
 					Labels: map[string]string{"device": "sda8"},
 				},
+				// Flattened histogram.
+				{
+					Name:   "kubelet_pleg_relist_duration_seconds_bucket",
+					Labels: map[string]string{"le": "0.01"},
+					Value:  12,
+				},
+				{
+					Name:   "kubelet_pleg_relist_duration_seconds_bucket",
+					Labels: map[string]string{"le": "+Inf"},
+					Value:  15,
+				},
+				{
+					Name:   "kubelet_pleg_relist_duration_seconds_sum",
+					Labels: map[string]string{},
+					Value:  0.75,
+				},
+				{
+					Name:   "kubelet_pleg_relist_duration_seconds_count",
+					Labels: map[string]string{},
+					Value:  15,
+				},
+				// Flattened summary.
+				{
+					Name:   "go_gc_duration_seconds",
+					Labels: map[string]string{"quantile": "0.5"},
+					Value:  4.2e-05,
+				},
+				{
+					Name:   "go_gc_duration_seconds_count",
+					Labels: map[string]string{},
+					Value:  42,
+				},
+				// Untyped metric.
+				{
+					Name:   "plugin_info",
+					Labels: map[string]string{"plugin": "ntp"},
+					Value:  1,
+				},
 			},
 			notExpectedMetrics: []Float64MetricRepresentation{
+				// Histograms and summaries are only reported as flattened series.
+				{
+					Name:   "kubelet_pleg_relist_duration_seconds",
+					Labels: map[string]string{},
+				},
 				// Metric with non-existent label.
 				{
 					Name:   "host_uptime",
@@ -133,11 +176,14 @@ func TestPrometheusMetricsParsingAndMatching(t *testing.T) {
 			}
 
 			for _, expectedMetric := range test.expectedMetrics {
-				_, err = GetFloat64Metric(metrics, expectedMetric.Name, expectedMetric.Labels, test.strictLabelMatching)
+				metric, err := GetFloat64Metric(metrics, expectedMetric.Name, expectedMetric.Labels, test.strictLabelMatching)
 				if err != nil {
 					t.Errorf("Failed to find metric %v in these metrics %v.\nMetrics text: %s\n",
 						expectedMetric, metrics, metricsText)
 				}
+				if expectedMetric.Value != 0 && metric.Value != expectedMetric.Value {
+					t.Errorf("Metric %v has value %v, expected %v", expectedMetric, metric.Value, expectedMetric.Value)
+				}
 			}
 
 			for _, notExpectedMetric := range test.notExpectedMetrics {
@@ -27,6 +27,17 @@ const (
 	CPULoad15m              MetricID = "cpu/load_15m"
 	ProblemCounterID        MetricID = "problem_counter"
 	ProblemGaugeID          MetricID = "problem_gauge"
+	ProblemLabelCounterID   MetricID = "problem_label_counter"
+	CustomPluginMetricID    MetricID = "custom_plugin/metric"
+	LogLinesReadID          MetricID = "monitor/log_lines_read"
+	LogLinesParsedID        MetricID = "monitor/log_lines_parsed"
+	LogLinesFailedID        MetricID = "monitor/log_lines_failed"
+	RuleMatchesID           MetricID = "monitor/rule_matches"
+	StatusSendLatencyID     MetricID = "monitor/status_send_latency"
+	WatcherRestartsID       MetricID = "monitor/watcher_restarts"
+	PluginDurationID        MetricID = "monitor/plugin_duration"
+	PluginTimeoutsID        MetricID = "monitor/plugin_timeouts"
+	PluginExitCodesID       MetricID = "monitor/plugin_exit_codes"
 	DiskIOTimeID            MetricID = "disk/io_time"
 	DiskWeightedIOID        MetricID = "disk/weighted_io"
 	DiskAvgQueueLenID       MetricID = "disk/avg_queue_len"
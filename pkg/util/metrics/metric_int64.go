@@ -60,6 +60,8 @@ func NewInt64Metric(metricID MetricID, viewName string, description string, unit
 		aggregationMethod = view.LastValue()
 	case Sum:
 		aggregationMethod = view.Sum()
+	case Distribution:
+		aggregationMethod = view.Distribution(DurationBounds...)
 	default:
 		return nil, fmt.Errorf("unknown aggregation option %q", aggregation)
 	}
@@ -35,7 +35,9 @@ type Tail struct {
 	filename   string
 	file       *os.File
 	stop       chan bool
-	watcher    *inotify.Watcher
+	// done is closed when watchLoop returns and the watcher is closed.
+	done    chan struct{}
+	watcher *inotify.Watcher
 }
 
 const (
@@ -60,6 +62,7 @@ func newTail(filename string) (*Tail, error) {
 	}
 	var err error
 	t.stop = make(chan bool)
+	t.done = make(chan struct{})
 	t.watcher, err = inotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("inotify init failed on %s: %v", t.filename, err)
@@ -82,9 +85,10 @@ func (t *Tail) Read(p []byte) (int, error) {
 
 var _ io.ReadCloser = &Tail{}
 
-// Close stops watching and closes the file
+// Close stops watching, closes the file and releases the inotify instance.
 func (t *Tail) Close() error {
 	close(t.stop)
+	<-t.done
 	return nil
 }
 
@@ -124,6 +128,8 @@ func (t *Tail) attemptOpen() error {
 }
 
 func (t *Tail) watchLoop() {
+	defer close(t.done)
+	defer t.closeWatcher()
 	for {
 		err := t.watchFile()
 		if err != nil {
@@ -133,6 +139,31 @@ func (t *Tail) watchLoop() {
 	}
 }
 
+// closeWatcher closes the inotify watcher and waits for its file descriptor to
+// be closed. The reader goroutine of the watcher only notices that it is closed
+// after reading an event, so the directory is watched again for Close to
+// generate one when it removes the watch, and the events and errors sent
+// meanwhile are drained.
+func (t *Tail) closeWatcher() {
+	go func() {
+		for range t.watcher.Event {
+		}
+	}()
+	watchDir := filepath.Dir(t.filename)
+	if err := t.watcher.AddWatch(watchDir, inotify.InDeleteSelf); err != nil {
+		klog.Errorf("Failed to close inotify watcher of %s: %v", t.filename, err)
+		go func() {
+			for range t.watcher.Error {
+			}
+		}()
+		t.watcher.Close()
+		return
+	}
+	t.watcher.Close()
+	for range t.watcher.Error {
+	}
+}
+
 func (t *Tail) watchFile() error {
 	err := t.attemptOpen()
 	if err != nil {
@@ -0,0 +1,93 @@
+// Copyright 2026 The Kubernetes Authors All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tail
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// inotifyInstances returns the number of inotify instances of the process.
+func inotifyInstances(t *testing.T) int {
+	t.Helper()
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Fatalf("Failed to list file descriptors: %v", err)
+	}
+	count := 0
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join("/proc/self/fd", entry.Name()))
+		if err == nil && target == "anon_inode:inotify" {
+			count++
+		}
+	}
+	return count
+}
+
+// readLine reads a line from the tail, waiting for the file to be opened.
+func readLine(t *testing.T, tail *Tail) string {
+	t.Helper()
+	reader := bufio.NewReader(tail)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if line, err := reader.ReadString('\n'); err == nil {
+			return line
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Timed out reading %s", tail.filename)
+	return ""
+}
+
+func TestCloseReleasesInotifyInstance(t *testing.T) {
+	dir := t.TempDir()
+	before := inotifyInstances(t)
+	// More files than the default limit of 128 inotify instances per user are
+	// followed and removed, like container logs.
+	for i := range 150 {
+		path := filepath.Join(dir, fmt.Sprintf("container-%d.log", i))
+		if err := os.WriteFile(path, []byte("line\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+		tail, err := NewTail(path)
+		if err != nil {
+			t.Fatalf("Failed to tail %s: %v", path, err)
+		}
+		if line := readLine(t, tail); line != "line\n" {
+			t.Errorf("Wanted line %q, got %q", "line\n", line)
+		}
+		if i%2 == 0 {
+			// The tail waits for the removed file to come back.
+			if err := os.Remove(path); err != nil {
+				t.Fatalf("Failed to remove %s: %v", path, err)
+			}
+		}
+		if err := tail.Close(); err != nil {
+			t.Errorf("Failed to close tail of %s: %v", path, err)
+		}
+		if i%2 != 0 {
+			if err := os.Remove(path); err != nil {
+				t.Fatalf("Failed to remove %s: %v", path, err)
+			}
+		}
+	}
+	if after := inotifyInstances(t); after != before {
+		t.Errorf("Wanted %d inotify instances after closing the tails, got %d", before, after)
+	}
+}